@@ -61,6 +61,23 @@ func (b *Boxen) allocateSerialPorts(numRequired, instanceID int) ([]int, error)
 	return nil, fmt.Errorf("%w: unable to allocate serial port(s)", util.ErrAllocationError)
 }
 
+func (b *Boxen) allocateVNCPort(instanceID int) (int, error) {
+	existingVNCPorts := b.Config.AllocatedVNCPorts()
+
+	if port := instanceID + config.VNCPORTBASE; port <= config.VNCPORTHI &&
+		!util.IntSliceContains(existingVNCPorts, port) {
+		return port, nil
+	}
+
+	for i := config.VNCPORTHI; i >= config.VNCPORTLOW; i-- {
+		if !util.IntSliceContains(existingVNCPorts, i) {
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: unable to allocate vnc port", util.ErrAllocationError)
+}
+
 func (b *Boxen) allocateMgmtNatPorts(
 	natPorts []int,
 	pendingNatPorts []*config.NatPortPair,
@@ -5,6 +5,7 @@ import (
 	"sync"
 
 	"github.com/carlmontanari/boxen/boxen/config"
+	"github.com/carlmontanari/boxen/boxen/docker"
 	"github.com/carlmontanari/boxen/boxen/logging"
 	"github.com/carlmontanari/boxen/boxen/platforms"
 	"github.com/carlmontanari/boxen/boxen/util"
@@ -12,8 +13,10 @@ import (
 
 // args is a simple struct for holding Boxen option arguments.
 type args struct {
-	logger *logging.Instance
-	config string
+	logger          *logging.Instance
+	config          string
+	containerEngine string
+	installCacheDir string
 }
 
 // Boxen is the main "manager"/instance containing all the configuration data and maps of instances.
@@ -24,6 +27,13 @@ type Boxen struct {
 	Instances     map[string]platforms.Platform
 	instancesLock *sync.Mutex
 	Logger        *logging.Instance
+	// containerEngine is the name of the container engine to use for packaging, the engine itself
+	// is only resolved (and possibly auto-detected) when packaging.
+	containerEngine string
+	engine          docker.Engine
+	// installCacheDir is the directory installed disks are cached in when packaging, if empty the
+	// install cache is not used.
+	installCacheDir string
 }
 
 // NewBoxen returns an instance of Boxen with any provided Option applied.
@@ -44,6 +54,9 @@ func NewBoxen(opts ...Option) (*Boxen, error) {
 		}
 	}
 
+	b.containerEngine = a.containerEngine
+	b.installCacheDir = a.installCacheDir
+
 	if a.logger != nil {
 		b.Logger = a.logger
 	} else {
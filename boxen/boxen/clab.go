@@ -0,0 +1,345 @@
+package boxen
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/carlmontanari/boxen/boxen/config"
+	"github.com/carlmontanari/boxen/boxen/platforms"
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+const (
+	// ClabStartupConfigPath is the path startup configs are bound to in containerlab nodes.
+	ClabStartupConfigPath = "/config/startup-config.cfg"
+
+	clabIntfPrefix = "eth"
+)
+
+// clabKinds maps boxen platform types to the containerlab kind boxen images are run as. The vr-*
+// kinds are the vrnetlab kinds, boxen images are vrnetlab compatible (see package-start).
+func clabKinds() map[string]string {
+	return map[string]string{
+		platforms.PlatformTypeAristaVeos:           "vr-veos",
+		platforms.PlatformTypeCiscoCsr1000v:        "vr-csr",
+		platforms.PlatformTypeCiscoXrv9k:           "vr-xrv9k",
+		platforms.PlatformTypeCiscoN9kv:            "vr-n9kv",
+		platforms.PlatformTypeJuniperVsrx:          "vr-vsrx",
+		platforms.PlatformTypePaloAltoPanos:        "vr-pan",
+		platforms.PlatformTypeIPInfusionOcNOS:      "ipinfusion_ocnos",
+		platforms.PlatformTypeCheckpointCloudguard: "checkpoint_cloudguard",
+		platforms.PlatformTypeFortinetFortigate:    "fortinet_fortigate",
+		platforms.PlatformTypeMikrotikChr:          "vr-ros",
+		platforms.PlatformTypeLinux:                "linux",
+	}
+}
+
+// ClabTopology is a containerlab topology file.
+type ClabTopology struct {
+	Name     string            `yaml:"name"`
+	Topology *ClabTopologyBody `yaml:"topology"`
+}
+
+// ClabTopologyBody is the "topology" section of a containerlab topology file.
+type ClabTopologyBody struct {
+	Kinds map[string]*ClabKind `yaml:"kinds,omitempty"`
+	Nodes map[string]*ClabNode `yaml:"nodes"`
+	Links []*ClabLink          `yaml:"links,omitempty"`
+}
+
+// ClabKind is a containerlab kind definition, holding the defaults for all nodes of that kind.
+type ClabKind struct {
+	Image string `yaml:"image,omitempty"`
+}
+
+// ClabNode is a containerlab node definition.
+type ClabNode struct {
+	Kind  string            `yaml:"kind"`
+	Image string            `yaml:"image,omitempty"`
+	Env   map[string]string `yaml:"env,omitempty"`
+	Binds []string          `yaml:"binds,omitempty"`
+}
+
+// ClabLink is a containerlab link definition.
+type ClabLink struct {
+	Endpoints []string `yaml:"endpoints"`
+}
+
+// ClabExportOptions are the options for ClabExport.
+type ClabExportOptions struct {
+	// Name is the name of the containerlab topology.
+	Name string
+	// Instances are the boxen instances to export, if empty all instances are exported.
+	Instances []string
+	// Images maps platform types to the image to use, by default the image is the image boxen
+	// packaging tags, i.e. "boxen_arista_veos:<source disk version>".
+	Images map[string]string
+	// RepoPrefix is prepended to all default image names, i.e. a registry "localhost:5000/".
+	RepoPrefix string
+	// Kinds maps platform types to containerlab kinds, overriding the defaults.
+	Kinds map[string]string
+}
+
+// clabEndpoint is one end of a data plane link between two boxen instances.
+type clabEndpoint struct {
+	instance string
+	nic      int
+}
+
+func (e clabEndpoint) String() string {
+	return fmt.Sprintf("%s:%s%d", e.instance, clabIntfPrefix, e.nic)
+}
+
+// clabNicCount returns the nic count of the instance, falling back to the platform default profile
+// if the instance has no hardware set.
+func clabNicCount(i *config.Instance) (int, error) {
+	if i.Hardware != nil && i.Hardware.NicCount > 0 {
+		return i.Hardware.NicCount, nil
+	}
+
+	p, err := GetDefaultProfile(i.PlatformType)
+	if err != nil {
+		return 0, err
+	}
+
+	return p.Hardware.NicCount, nil
+}
+
+// clabLinks returns the data plane links between the instances -- a link exists where an instance
+// nic connects to the listen port of a nic of another instance. A link to an instance that is not
+// exported is an error rather than being left out, as the exported topology would not match the
+// lab.
+func (b *Boxen) clabLinks(instances []string) ([][2]clabEndpoint, error) {
+	listeners := map[int]clabEndpoint{}
+
+	for name, i := range b.Config.Instances {
+		if i.DataPlaneIntf == nil {
+			continue
+		}
+
+		for nic, pair := range i.DataPlaneIntf.SocketConnectMap {
+			listeners[pair.Listen] = clabEndpoint{instance: name, nic: nic}
+		}
+	}
+
+	var links [][2]clabEndpoint
+
+	seen := map[string]bool{}
+
+	for _, name := range instances {
+		i := b.Config.Instances[name]
+
+		if i.DataPlaneIntf == nil {
+			continue
+		}
+
+		nics := make([]int, 0, len(i.DataPlaneIntf.SocketConnectMap))
+
+		for nic := range i.DataPlaneIntf.SocketConnectMap {
+			nics = append(nics, nic)
+		}
+
+		sort.Ints(nics)
+
+		for _, nic := range nics {
+			pair := i.DataPlaneIntf.SocketConnectMap[nic]
+
+			if pair.Connect <= 0 {
+				continue
+			}
+
+			a := clabEndpoint{instance: name, nic: nic}
+
+			z, ok := listeners[pair.Connect]
+			if !ok {
+				return nil, fmt.Errorf(
+					"%w: instance '%s' nic %d connects to port %d which is not a nic of any "+
+						"instance",
+					util.ErrValidationError,
+					name,
+					nic,
+					pair.Connect,
+				)
+			}
+
+			if !util.StringSliceContains(z.instance, instances) {
+				return nil, fmt.Errorf(
+					"%w: instance '%s' nic %d connects to instance '%s' which is not exported, "+
+						"export it as well",
+					util.ErrValidationError,
+					name,
+					nic,
+					z.instance,
+				)
+			}
+
+			// both sides of a link "connect" to each other, only keep one of them
+			if seen[z.String()+a.String()] {
+				continue
+			}
+
+			seen[a.String()+z.String()] = true
+
+			links = append(links, [2]clabEndpoint{a, z})
+		}
+	}
+
+	return links, nil
+}
+
+// clabValidateNics checks that all link endpoints fit in the nic count of their instance.
+func (b *Boxen) clabValidateNics(links [][2]clabEndpoint) error {
+	for _, link := range links {
+		for _, e := range link {
+			nicCount, err := clabNicCount(b.Config.Instances[e.instance])
+			if err != nil {
+				return err
+			}
+
+			if e.nic < 1 || e.nic > nicCount {
+				return fmt.Errorf(
+					"%w: instance '%s' interface %d is out of range, platform type '%s' has "+
+						"interfaces 1-%d",
+					util.ErrValidationError,
+					e.instance,
+					e.nic,
+					b.Config.Instances[e.instance].PlatformType,
+					nicCount,
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (b *Boxen) clabNode(
+	i *config.Instance,
+	kind, image string,
+	intfCount int,
+) (*ClabNode, error) {
+	n := &ClabNode{
+		Kind:  kind,
+		Image: image,
+		Env:   map[string]string{},
+	}
+
+	if intfCount > 0 {
+		n.Env["CLAB_INTFS"] = strconv.Itoa(intfCount)
+	}
+
+	if i.BootDelay > 0 {
+		n.Env["BOOT_DELAY"] = strconv.Itoa(i.BootDelay)
+	}
+
+	if i.StartupConfig != "" {
+		f, err := filepath.Abs(util.ExpandPath(i.StartupConfig))
+		if err != nil {
+			return nil, err
+		}
+
+		n.Binds = append(n.Binds, fmt.Sprintf("%s:%s:ro", f, ClabStartupConfigPath))
+		n.Env["STARTUP_CONFIG"] = ClabStartupConfigPath
+	}
+
+	return n, nil
+}
+
+// ClabExport generates a containerlab topology from boxen instances -- nodes are the packaged
+// images of the instances, with the env vars package-start reads (CLAB_INTFS, BOOT_DELAY and
+// STARTUP_CONFIG), startup config binds, and links from the instances data plane connections.
+// The kinds section holds the image per kind so that nodes only carry what differs. Connections to
+// instances that are not exported, and interfaces beyond the nic count of an instance, are errors.
+func (b *Boxen) ClabExport(opts *ClabExportOptions) (*ClabTopology, error) {
+	instances := opts.Instances
+
+	if len(instances) == 0 {
+		for name := range b.Config.Instances {
+			instances = append(instances, name)
+		}
+	}
+
+	sort.Strings(instances)
+
+	for _, name := range instances {
+		if _, ok := b.Config.Instances[name]; !ok {
+			return nil, fmt.Errorf(
+				"%w: no instance name '%s' in the config",
+				util.ErrInstanceError,
+				name,
+			)
+		}
+	}
+
+	links, err := b.clabLinks(instances)
+	if err != nil {
+		return nil, err
+	}
+
+	err = b.clabValidateNics(links)
+	if err != nil {
+		return nil, err
+	}
+
+	intfCounts := map[string]int{}
+
+	t := &ClabTopology{
+		Name: opts.Name,
+		Topology: &ClabTopologyBody{
+			Kinds: map[string]*ClabKind{},
+			Nodes: map[string]*ClabNode{},
+		},
+	}
+
+	for _, link := range links {
+		intfCounts[link[0].instance]++
+		intfCounts[link[1].instance]++
+
+		t.Topology.Links = append(
+			t.Topology.Links,
+			&ClabLink{Endpoints: []string{link[0].String(), link[1].String()}},
+		)
+	}
+
+	kinds := clabKinds()
+
+	for pT, kind := range opts.Kinds {
+		kinds[pT] = kind
+	}
+
+	for _, name := range instances {
+		i := b.Config.Instances[name]
+
+		kind, ok := kinds[i.PlatformType]
+		if !ok {
+			return nil, fmt.Errorf(
+				"%w: no containerlab kind for platform type '%s'",
+				util.ErrValidationError,
+				i.PlatformType,
+			)
+		}
+
+		image, ok := opts.Images[i.PlatformType]
+		if !ok {
+			image = fmt.Sprintf("%sboxen_%s:%s", opts.RepoPrefix, i.PlatformType, i.Disk)
+		}
+
+		// the first instance of a kind sets the kind image, nodes only set it if they differ
+		if _, ok = t.Topology.Kinds[kind]; !ok {
+			t.Topology.Kinds[kind] = &ClabKind{Image: image}
+		}
+
+		if t.Topology.Kinds[kind].Image == image {
+			image = ""
+		}
+
+		t.Topology.Nodes[name], err = b.clabNode(i, kind, image, intfCounts[name])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
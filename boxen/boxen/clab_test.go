@@ -0,0 +1,228 @@
+package boxen_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/carlmontanari/boxen/boxen/boxen"
+	"github.com/carlmontanari/boxen/boxen/config"
+	"github.com/carlmontanari/boxen/boxen/logging"
+	"github.com/carlmontanari/boxen/boxen/util"
+	"github.com/google/go-cmp/cmp"
+)
+
+// clabInstance returns an instance of platform type pT with the given nic -> socket connect pairs.
+func clabInstance(
+	name, pT string,
+	nicCount int,
+	nics map[int]*config.SocketConnectPair,
+) *config.Instance {
+	i := &config.Instance{
+		Name:          name,
+		PlatformType:  pT,
+		Disk:          "4.22.1F",
+		DataPlaneIntf: &config.DataPlaneIntf{SocketConnectMap: nics},
+	}
+
+	if nicCount > 0 {
+		i.Hardware = &config.Hardware{NicCount: nicCount}
+	}
+
+	return i
+}
+
+func testClabBoxen(t *testing.T, instances []*config.Instance) *boxen.Boxen {
+	t.Helper()
+
+	l, err := logging.NewInstance(func(...interface{}) {})
+	if err != nil {
+		t.Fatalf("failed creating logger: %s", err)
+	}
+
+	c := config.NewConfig()
+
+	for _, i := range instances {
+		c.Instances[i.Name] = i
+	}
+
+	return &boxen.Boxen{Config: c, Logger: l}
+}
+
+func TestClabExport(t *testing.T) {
+	tests := []struct {
+		desc      string
+		instances []*config.Instance
+		export    []string
+		want      *boxen.ClabTopology
+	}{
+		{
+			desc: "two node link",
+			instances: []*config.Instance{
+				clabInstance("r1", "arista_veos", 4, map[int]*config.SocketConnectPair{
+					1: {Connect: 21002, Listen: 21001},
+				}),
+				clabInstance("r2", "arista_veos", 4, map[int]*config.SocketConnectPair{
+					2: {Connect: 21001, Listen: 21002},
+				}),
+			},
+			want: &boxen.ClabTopology{
+				Name: "boxen",
+				Topology: &boxen.ClabTopologyBody{
+					Kinds: map[string]*boxen.ClabKind{
+						"vr-veos": {Image: "boxen_arista_veos:4.22.1F"},
+					},
+					Nodes: map[string]*boxen.ClabNode{
+						"r1": {Kind: "vr-veos", Env: map[string]string{"CLAB_INTFS": "1"}},
+						"r2": {Kind: "vr-veos", Env: map[string]string{"CLAB_INTFS": "1"}},
+					},
+					Links: []*boxen.ClabLink{
+						{Endpoints: []string{"r1:eth1", "r2:eth2"}},
+					},
+				},
+			},
+		},
+		{
+			desc: "three node chain",
+			instances: []*config.Instance{
+				clabInstance("r1", "arista_veos", 0, map[int]*config.SocketConnectPair{
+					1: {Connect: 21003, Listen: 21001},
+				}),
+				clabInstance("r2", "arista_veos", 0, map[int]*config.SocketConnectPair{
+					1: {Connect: 21004, Listen: 21002},
+					2: {Connect: 21001, Listen: 21003},
+				}),
+				clabInstance("r3", "cisco_csr1000v", 0, map[int]*config.SocketConnectPair{
+					1: {Connect: 21002, Listen: 21004},
+					2: {Listen: 21005},
+				}),
+			},
+			want: &boxen.ClabTopology{
+				Name: "boxen",
+				Topology: &boxen.ClabTopologyBody{
+					Kinds: map[string]*boxen.ClabKind{
+						"vr-veos": {Image: "boxen_arista_veos:4.22.1F"},
+						"vr-csr":  {Image: "boxen_cisco_csr1000v:4.22.1F"},
+					},
+					Nodes: map[string]*boxen.ClabNode{
+						"r1": {Kind: "vr-veos", Env: map[string]string{"CLAB_INTFS": "1"}},
+						"r2": {Kind: "vr-veos", Env: map[string]string{"CLAB_INTFS": "2"}},
+						"r3": {Kind: "vr-csr", Env: map[string]string{"CLAB_INTFS": "1"}},
+					},
+					Links: []*boxen.ClabLink{
+						{Endpoints: []string{"r1:eth1", "r2:eth2"}},
+						{Endpoints: []string{"r2:eth1", "r3:eth1"}},
+					},
+				},
+			},
+		},
+		{
+			desc: "unconnected instances",
+			instances: []*config.Instance{
+				clabInstance("r1", "arista_veos", 0, nil),
+				clabInstance("r2", "arista_veos", 0, map[int]*config.SocketConnectPair{
+					1: {Listen: 21001},
+				}),
+			},
+			export: []string{"r2"},
+			want: &boxen.ClabTopology{
+				Name: "boxen",
+				Topology: &boxen.ClabTopologyBody{
+					Kinds: map[string]*boxen.ClabKind{
+						"vr-veos": {Image: "boxen_arista_veos:4.22.1F"},
+					},
+					Nodes: map[string]*boxen.ClabNode{
+						"r2": {Kind: "vr-veos", Env: map[string]string{}},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			b := testClabBoxen(t, tt.instances)
+
+			got, err := b.ClabExport(&boxen.ClabExportOptions{Name: "boxen", Instances: tt.export})
+			if err != nil {
+				t.Fatalf("%s: failed exporting topology: %s", tt.desc, err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("%s: topology does not match (-want +got):\n%s", tt.desc, diff)
+			}
+		})
+	}
+}
+
+func TestClabExportInvalid(t *testing.T) {
+	tests := []struct {
+		desc      string
+		instances []*config.Instance
+		export    []string
+	}{
+		{
+			desc: "link to an instance that is not exported",
+			instances: []*config.Instance{
+				clabInstance("r1", "arista_veos", 0, map[int]*config.SocketConnectPair{
+					1: {Connect: 21002, Listen: 21001},
+				}),
+				clabInstance("r2", "arista_veos", 0, map[int]*config.SocketConnectPair{
+					1: {Connect: 21001, Listen: 21002},
+				}),
+			},
+			export: []string{"r1"},
+		},
+		{
+			desc: "link to a port of no instance",
+			instances: []*config.Instance{
+				clabInstance("r1", "arista_veos", 0, map[int]*config.SocketConnectPair{
+					1: {Connect: 21009, Listen: 21001},
+				}),
+			},
+		},
+		{
+			desc: "interface beyond the instance nic count",
+			instances: []*config.Instance{
+				clabInstance("r1", "arista_veos", 2, map[int]*config.SocketConnectPair{
+					3: {Connect: 21002, Listen: 21001},
+				}),
+				clabInstance("r2", "arista_veos", 0, map[int]*config.SocketConnectPair{
+					1: {Connect: 21001, Listen: 21002},
+				}),
+			},
+		},
+		{
+			desc: "interface beyond the platform default nic count",
+			instances: []*config.Instance{
+				clabInstance("r1", "arista_veos", 0, map[int]*config.SocketConnectPair{
+					1: {Connect: 21002, Listen: 21001},
+				}),
+				clabInstance("r2", "ipinfusion_ocnos", 0, map[int]*config.SocketConnectPair{
+					7: {Connect: 21001, Listen: 21002},
+				}),
+			},
+		},
+		{
+			desc: "interface zero",
+			instances: []*config.Instance{
+				clabInstance("r1", "arista_veos", 0, map[int]*config.SocketConnectPair{
+					0: {Connect: 21002, Listen: 21001},
+				}),
+				clabInstance("r2", "arista_veos", 0, map[int]*config.SocketConnectPair{
+					1: {Connect: 21001, Listen: 21002},
+				}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			b := testClabBoxen(t, tt.instances)
+
+			_, err := b.ClabExport(&boxen.ClabExportOptions{Name: "boxen", Instances: tt.export})
+			if !errors.Is(err, util.ErrValidationError) {
+				t.Fatalf("%s: expected validation error, got: %v", tt.desc, err)
+			}
+		})
+	}
+}
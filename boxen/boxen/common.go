@@ -74,6 +74,16 @@ func getDiskData(i *installInfo) error {
 	return err
 }
 
+// validateInstallSSHKeys checks the platform type of the disk can authorize all the ssh keys of the
+// install credentials, so unsupported keys fail the install up front rather than being dropped.
+func validateInstallSSHKeys(i *installInfo) error {
+	if i.credentials == nil {
+		return nil
+	}
+
+	return platforms.ValidateSSHKeys(i.srcDisk.PlatformType, len(i.credentials.SSHKeys))
+}
+
 // GetDefaultProfile fetches the default instance profile for a given platform type 'pt'.
 func GetDefaultProfile(pt string) (*config.Profile, error) {
 	var f []byte
@@ -115,6 +125,13 @@ func (b *Boxen) installAllocateDisks(i *installInfo) error {
 		)
 	}
 
+	err = validateInstallSSHKeys(i)
+	if err != nil {
+		b.Logger.Criticalf("error validating ssh keys: %s", err)
+
+		return err
+	}
+
 	i.name = fmt.Sprintf("%s_%s", i.srcDisk.PlatformType, i.srcDisk.Version)
 	i.newDisk = fmt.Sprintf("%s/%s.qcow2", i.tmpDir, i.name)
 
@@ -3,44 +3,83 @@ package boxen
 import (
 	"bytes"
 	"fmt"
-	"html/template"
 	"os"
+	"path/filepath"
 	"strings"
+	"text/template"
 
 	"github.com/carlmontanari/boxen/boxen"
+	"github.com/carlmontanari/boxen/boxen/config"
+	"github.com/carlmontanari/boxen/boxen/platforms"
+	"github.com/carlmontanari/boxen/boxen/util"
 )
 
 type configTemplateArgs struct {
 	Username          string
 	Password          string
 	SecondaryPassword string
+	SSHKeys           []*util.SSHPublicKey
 }
 
-// RenderInitialConfig renders the initial installation config template.
+// configTemplateFuncs are the extra functions available to the initial config templates.
+var configTemplateFuncs = template.FuncMap{ //nolint:gochecknoglobals
+	// inc returns i+1, handy for platforms that want numbered (1-indexed) things like ssh keys.
+	"inc": func(i int) int { return i + 1 },
+	// hash returns the password hashed with the given scheme, i.e. {{ hash "sha512-crypt" .Password }}
+	// so templates can keep plaintext passwords out of the rendered configs.
+	"hash": util.HashPassword,
+}
+
+// RenderInitialConfig renders the initial installation config template. Note that this is a text
+// (not html) template -- config lines must not be html escaped (i.e. "+" in ssh keys/passwords).
 func (b *Boxen) RenderInitialConfig(
 	name string,
 ) ([]string, error) {
-	platformType := b.Config.Instances[name].PlatformType
+	return renderInitialConfig(b.Config.Instances[name], configTemplateFuncs)
+}
+
+// renderInitialConfig renders the initial installation config template for the instance i, funcs
+// are the functions made available to the template.
+func renderInitialConfig(i *config.Instance, funcs template.FuncMap) ([]string, error) {
+	platformType := i.PlatformType
+
+	creds, err := i.Credentials.Resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	sshKeys, err := util.ParseSSHPublicKeys(creds.SSHKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	// templates of platforms without (or with limited) key support can't render all the keys
+	err = platforms.ValidateSSHKeys(platformType, len(sshKeys))
+	if err != nil {
+		return nil, err
+	}
 
 	templateData := &configTemplateArgs{
-		Username: b.Config.Instances[name].Credentials.Username,
-		Password: b.Config.Instances[name].Credentials.Password,
+		Username: creds.Username,
+		Password: creds.Password,
+		SSHKeys:  sshKeys,
 	}
 
-	var t *template.Template
+	templateName := fmt.Sprintf("%s.template", platformType)
 
-	var err error
+	var t *template.Template
 
 	envProfilePath := os.Getenv(
 		fmt.Sprintf("BOXEN_%s_INITIAL_CONFIG_TEMPLATE", strings.ToUpper(platformType)),
 	)
 	if envProfilePath != "" {
-		t, err = template.ParseFiles(envProfilePath)
+		t, err = template.New(filepath.Base(envProfilePath)).
+			Funcs(funcs).
+			ParseFiles(envProfilePath)
 	} else {
-		t, err = template.ParseFS(
-			boxen.Assets,
-			fmt.Sprintf("assets/configs/%s.template", platformType),
-		)
+		t, err = template.New(templateName).
+			Funcs(funcs).
+			ParseFS(boxen.Assets, fmt.Sprintf("assets/configs/%s", templateName))
 	}
 
 	if err != nil {
@@ -0,0 +1,179 @@
+package boxen_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/carlmontanari/boxen/boxen/boxen"
+	"github.com/carlmontanari/boxen/boxen/config"
+	"github.com/carlmontanari/boxen/boxen/logging"
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+const (
+	testSSHKey1Blob = "AAAAC3NzaC1lZDI1NTE5AAAAILXMN7mj0jOxyukcUD9aSdRSAiM6QmtRlXlRujoflUx6"
+	testSSHKey2Blob = "AAAAC3NzaC1lZDI1NTE5AAAAIO4SWvLtxZcw4EBJDC4LR+CEWi78Fs4zbmEsECmsV1MW"
+	testSSHKey3Blob = "AAAAC3NzaC1lZDI1NTE5AAAAIEN3yX4IR+Bxi7gEInGdlrlsMnt0EDMqvc+0/BG5CUoM"
+	testSSHKey3     = "ssh-ed25519 " + testSSHKey3Blob + " boxen@k3"
+	testSSHKey4Blob = "AAAAC3NzaC1lZDI1NTE5AAAAINl6vFuG8GDbt2l6yn7shAxXAbRTbD9HAzxdWG+NnUyz"
+	testSSHKey4     = "ssh-ed25519 " + testSSHKey4Blob + " boxen@k4"
+)
+
+func TestRenderInitialConfigSSHKeys(t *testing.T) {
+	tests := []struct {
+		desc         string
+		platformType string
+		keys         []string
+		want         []string
+		wantErr      bool
+	}{
+		{
+			desc:         "arista veos",
+			platformType: "arista_veos",
+			keys:         []string{testSSHKey1, testSSHKey2},
+			want: []string{
+				"username boxen sshkey ssh-ed25519 " + testSSHKey1Blob,
+				"username boxen sshkey ssh-ed25519 " + testSSHKey2Blob,
+			},
+		},
+		{
+			desc:         "cisco csr1000v",
+			platformType: "cisco_csr1000v",
+			keys:         []string{testSSHKey1},
+			want: []string{
+				"ip ssh pubkey-chain",
+				"username boxen",
+				"key-string",
+				testSSHKey1Blob[:64],
+				testSSHKey1Blob[64:],
+			},
+		},
+		{
+			desc:         "cisco n9kv",
+			platformType: "cisco_n9kv",
+			keys:         []string{testSSHKey1, testSSHKey2},
+			want: []string{
+				"username boxen sshkey ssh-ed25519 " + testSSHKey1Blob,
+				"username boxen sshkey ssh-ed25519 " + testSSHKey2Blob,
+			},
+		},
+		{
+			desc:         "juniper vsrx",
+			platformType: "juniper_vsrx",
+			keys:         []string{testSSHKey1},
+			want: []string{
+				`set system login user boxen authentication ssh-ed25519 "ssh-ed25519 ` +
+					testSSHKey1Blob + `"`,
+			},
+		},
+		{
+			desc:         "mikrotik chr",
+			platformType: "mikrotik_chr",
+			keys:         []string{testSSHKey1, testSSHKey2},
+			want: []string{
+				`/user ssh-keys add user=boxen key="ssh-ed25519 ` + testSSHKey1Blob + `"`,
+				`/user ssh-keys add user=boxen key="ssh-ed25519 ` + testSSHKey2Blob + `"`,
+			},
+		},
+		{
+			desc:         "paloalto panos",
+			platformType: "paloalto_panos",
+			keys:         []string{testSSHKey1},
+			want: []string{
+				"set mgt-config users boxen public-key " +
+					"c3NoLWVkMjU1MTkgQUFBQUMzTnphQzFsWkRJMU5URTVBQUFBSUxYTU43bWowak94eXVrY1VEOWFT" +
+					"ZFJTQWlNNlFtdFJsWGxSdWpvZmxVeDY=",
+			},
+		},
+		{
+			desc:         "fortinet fortigate",
+			platformType: "fortinet_fortigate",
+			keys:         []string{testSSHKey1, testSSHKey2, testSSHKey3},
+			want: []string{
+				"config system admin",
+				"edit boxen",
+				`set ssh-public-key1 "ssh-ed25519 ` + testSSHKey1Blob + `"`,
+				`set ssh-public-key2 "ssh-ed25519 ` + testSSHKey2Blob + `"`,
+				`set ssh-public-key3 "ssh-ed25519 ` + testSSHKey3Blob + `"`,
+			},
+		},
+		{
+			desc:         "paloalto panos too many keys",
+			platformType: "paloalto_panos",
+			keys:         []string{testSSHKey1, testSSHKey2},
+			wantErr:      true,
+		},
+		{
+			desc:         "fortinet fortigate too many keys",
+			platformType: "fortinet_fortigate",
+			keys:         []string{testSSHKey1, testSSHKey2, testSSHKey3, testSSHKey4},
+			wantErr:      true,
+		},
+		{
+			desc:         "cisco xrv9k has no key support",
+			platformType: "cisco_xrv9k",
+			keys:         []string{testSSHKey1},
+			wantErr:      true,
+		},
+		{
+			desc:         "ipinfusion ocnos has no key support",
+			platformType: "ipinfusion_ocnos",
+			keys:         []string{testSSHKey1},
+			wantErr:      true,
+		},
+		{
+			desc:         "checkpoint cloudguard has no key support",
+			platformType: "checkpoint_cloudguard",
+			keys:         []string{testSSHKey1},
+			wantErr:      true,
+		},
+		{
+			desc:         "invalid key",
+			platformType: "arista_veos",
+			keys:         []string{`from="10.0.0.0/8" ` + testSSHKey1},
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			l, err := logging.NewInstance(func(...interface{}) {})
+			if err != nil {
+				t.Fatalf("failed creating logger: %s", err)
+			}
+
+			c := config.NewConfig()
+			c.Instances["vm1"] = &config.Instance{
+				Name:         "vm1",
+				PlatformType: tt.platformType,
+				Credentials: &config.Credentials{
+					Username: "boxen",
+					Password: "b0x3n",
+					SSHKeys:  tt.keys,
+				},
+			}
+
+			b := &boxen.Boxen{Config: c, Logger: l}
+
+			got, err := b.RenderInitialConfig("vm1")
+
+			if tt.wantErr {
+				if !errors.Is(err, util.ErrValidationError) {
+					t.Fatalf("%s: expected validation error, got: %v", tt.desc, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("%s: failed rendering config: %s", tt.desc, err)
+			}
+
+			for _, line := range tt.want {
+				if !util.StringSliceContains(line, got) {
+					t.Fatalf("%s: rendered config is missing line %q:\n%v", tt.desc, line, got)
+				}
+			}
+		})
+	}
+}
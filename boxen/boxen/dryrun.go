@@ -0,0 +1,96 @@
+package boxen
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/carlmontanari/boxen/boxen/config"
+	"github.com/carlmontanari/boxen/boxen/instance"
+	"github.com/carlmontanari/boxen/boxen/platforms"
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+// StartDryRun returns the launch command Start would launch the local instance name with, nothing
+// is started, copied or written.
+func (b *Boxen) StartDryRun(name string) (*instance.LaunchCmdExplain, error) {
+	_, ok := b.Config.Instances[name]
+	if !ok {
+		return nil, fmt.Errorf(
+			"%w: no instance name '%s' in the config",
+			util.ErrInstanceError,
+			name,
+		)
+	}
+
+	// same as Start, the instance runs from the disk in its instance directory
+	diskVer := b.Config.Instances[name].Disk
+	b.Config.Instances[name].Disk = fmt.Sprintf(
+		"%s/%s/disk.qcow2",
+		b.Config.Options.Build.InstancePath,
+		name,
+	)
+
+	defer func() { b.Config.Instances[name].Disk = diskVer }()
+
+	// same as Start, an instance with vnc enabled but no port gets one -- dry run doesn't keep it
+	vncPort := b.Config.Instances[name].Hardware.VNCPort
+
+	err := b.startAllocateVNCPort(name)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { b.Config.Instances[name].Hardware.VNCPort = vncPort }()
+
+	q, err := platforms.NewPlatformFromConfig(name, b.Config, &instance.Loggers{Base: b.Logger})
+	if err != nil {
+		return nil, err
+	}
+
+	return platforms.DryRun(q, false)
+}
+
+// InstallDryRun returns the launch command Install would launch the installation instance of disk
+// with, nothing is installed, copied or written. The temporary directory in the command is only
+// indicative, Install creates a new one each time.
+func (b *Boxen) InstallDryRun(
+	disk, vendor, platform, version string,
+) (*instance.LaunchCmdExplain, error) {
+	i := &installInfo{inDisk: disk}
+
+	err := b.handleProvidedPlatformInfo(i, vendor, platform, version)
+	if err != nil {
+		return nil, err
+	}
+
+	err = getDiskData(i)
+	if err != nil {
+		return nil, err
+	}
+
+	i.tmpDir = filepath.Join(os.TempDir(), "boxen")
+	i.name = fmt.Sprintf("%s_%s", i.srcDisk.PlatformType, i.srcDisk.Version)
+	i.newDisk = fmt.Sprintf("%s/%s.qcow2", i.tmpDir, i.name)
+
+	_, ok := b.Config.Platforms[i.srcDisk.PlatformType]
+	if !ok {
+		b.Config.Platforms[i.srcDisk.PlatformType] = config.NewPlatform()
+
+		defer delete(b.Config.Platforms, i.srcDisk.PlatformType)
+	}
+
+	err = b.installAllocateInstance(i)
+	if err != nil {
+		return nil, err
+	}
+
+	defer delete(b.Config.Instances, i.name)
+
+	q, err := platforms.NewPlatformFromConfig(i.name, b.Config, &instance.Loggers{Base: b.Logger})
+	if err != nil {
+		return nil, err
+	}
+
+	return platforms.DryRun(q, true)
+}
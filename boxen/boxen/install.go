@@ -13,15 +13,23 @@ import (
 )
 
 type installInfo struct {
-	inDisk   string
-	srcDisk  *Disk
-	newDisk  string
-	username string
-	password string
-	config   []string
-	runFiles []string
-	name     string
-	tmpDir   string
+	inDisk      string
+	srcDisk     *Disk
+	newDisk     string
+	credentials *config.Credentials
+	config      []string
+	runFiles    []string
+	name        string
+	tmpDir      string
+	// manifest and installCacheKey are only set when packaging.
+	manifest        *ImageManifest
+	installCacheKey string
+	// installed is true if the disk is an already installed source disk (packaging from source), so
+	// there is no installation to be done.
+	installed bool
+	// installedFiles are the files other than the disk an installation produces when packaging (i.e.
+	// uefi firmware vars), they are copied out of the install container and cached with the disk.
+	installedFiles []string
 }
 
 func (b *Boxen) installAllocateInstance(
@@ -85,13 +93,7 @@ func (b *Boxen) installAllocateInstance(
 	b.Config.Instances[i.name].Hardware.SerialPorts = serialPorts
 	b.Config.Instances[i.name].Hardware.MonitorPort = monitorPort
 
-	if len(i.username) > 0 {
-		b.Config.Instances[i.name].Credentials.Username = i.username
-	}
-
-	if len(i.password) > 0 {
-		b.Config.Instances[i.name].Credentials.Password = i.password
-	}
+	b.Config.Instances[i.name].Credentials.Merge(i.credentials)
 
 	return nil
 }
@@ -199,10 +201,17 @@ func (b *Boxen) installTransferFinalFiles(
 }
 
 func (b *Boxen) installUpdateConfig(i *installInfo) error {
-	b.Config.Platforms[i.srcDisk.PlatformType].SourceDisks = append(
-		b.Config.Platforms[i.srcDisk.PlatformType].SourceDisks,
-		i.srcDisk.Version,
-	)
+	p := b.Config.Platforms[i.srcDisk.PlatformType]
+
+	p.SourceDisks = append(p.SourceDisks, i.srcDisk.Version)
+
+	// keep (references to) the credentials the disk was installed with, so packaging the source disk
+	// later doesn't have to guess them
+	if p.SourceCredentials == nil {
+		p.SourceCredentials = map[string]*config.Credentials{}
+	}
+
+	p.SourceCredentials[i.srcDisk.Version] = b.Config.Instances[i.name].Credentials.References()
 
 	// delete the instance out of the config since we don't need it anymore
 	delete(b.Config.Instances, i.name)
@@ -213,13 +222,23 @@ func (b *Boxen) installUpdateConfig(i *installInfo) error {
 // Install "installs" a disk as a source disk which local instances can be provisioned to boot from.
 // This is only useful/relevant in the local VM (not Containerlab) mode of operation. Install
 // handles disabling initial config dialogs/prompts, setting base credentials, and then storing the
-// image for use for later provisioned instances.
+// image for use for later provisioned instances. Any credentials provided (as literal values or
+// credential sources) override the boxen default credentials.
 func (b *Boxen) Install(
-	disk, username, password, vendor, platform, version string,
+	disk string,
+	credentials *config.Credentials,
+	vendor, platform, version string,
 ) error {
 	b.Logger.Infof("install requested for disk '%s'", disk)
 
-	i := &installInfo{inDisk: disk, username: username, password: password}
+	if credentials != nil {
+		err := credentials.Validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	i := &installInfo{inDisk: disk, credentials: credentials}
 
 	err := b.handleProvidedPlatformInfo(i, vendor, platform, version)
 	if err != nil {
@@ -285,6 +304,12 @@ func (b *Boxen) Install(
 
 	b.Logger.Info("initial installation complete")
 
+	// uefi instances write their firmware vars next to the disk, the vars belong to the installed
+	// disk so they are stored with it and copied to every instance started from it
+	if util.FileExists(filepath.Join(i.tmpDir, config.FirmwareVarsFile)) {
+		i.runFiles = append(i.runFiles, config.FirmwareVarsFile)
+	}
+
 	err = b.installTransferFinalFiles(i)
 	if err != nil {
 		return err
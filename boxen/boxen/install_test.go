@@ -0,0 +1,66 @@
+package boxen_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/carlmontanari/boxen/boxen/boxen"
+	"github.com/carlmontanari/boxen/boxen/config"
+	"github.com/carlmontanari/boxen/boxen/logging"
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+const (
+	testSSHKey1 = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAILXMN7mj0jOxyukcUD9aSdRSAiM6QmtRlXlRujoflUx6 " +
+		"boxen@k1"
+	testSSHKey2 = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIO4SWvLtxZcw4EBJDC4LR+CEWi78Fs4zbmEsECmsV1MW " +
+		"boxen@k2"
+)
+
+func TestInstallRejectsUnsupportedSSHKeys(t *testing.T) {
+	tests := []struct {
+		desc     string
+		vendor   string
+		platform string
+		keys     []string
+	}{
+		{
+			desc:     "keys on a platform without key support",
+			vendor:   "cisco",
+			platform: "xrv9k",
+			keys:     []string{testSSHKey1},
+		},
+		{
+			desc:     "too many keys",
+			vendor:   "paloalto",
+			platform: "panos",
+			keys:     []string{testSSHKey1, testSSHKey2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			l, err := logging.NewInstance(func(...interface{}) {})
+			if err != nil {
+				t.Fatalf("failed creating logger: %s", err)
+			}
+
+			disk := filepath.Join(t.TempDir(), "disk.qcow2")
+
+			err = os.WriteFile(disk, []byte("not really a disk"), util.FilePerms)
+			if err != nil {
+				t.Fatalf("failed creating disk: %s", err)
+			}
+
+			b := &boxen.Boxen{Config: config.NewConfig(), Logger: l}
+
+			// the keys are rejected before the disk is even copied, so a bogus disk is fine
+			err = b.Install(disk, &config.Credentials{SSHKeys: tt.keys}, tt.vendor, tt.platform, "1")
+			if !errors.Is(err, util.ErrValidationError) {
+				t.Fatalf("%s: expected validation error, got: %v", tt.desc, err)
+			}
+		})
+	}
+}
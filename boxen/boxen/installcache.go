@@ -0,0 +1,332 @@
+package boxen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/template"
+	"time"
+
+	"github.com/carlmontanari/boxen/boxen/config"
+	"github.com/carlmontanari/boxen/boxen/util"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// DefaultInstallCacheDir is the default directory installed disks are cached in.
+	DefaultInstallCacheDir = "~/boxen/cache/install"
+
+	installCacheDisk  = "disk.qcow2"
+	installCacheEntry = "entry.json"
+
+	// installCacheSalt is the fixed salt used when rendering the install config for the cache key,
+	// the "real" render uses random salts, which would never produce the same key twice.
+	installCacheSalt = "boxeninstallkey"
+)
+
+// InstallCacheEntry describes an installed disk in the install cache.
+type InstallCacheEntry struct {
+	Key              string `json:"key"`
+	PlatformType     string `json:"platform_type"`
+	Version          string `json:"version"`
+	BoxenVersion     string `json:"boxen_version"`
+	SourceDisk       string `json:"source_disk"`
+	SourceDiskSha256 string `json:"source_disk_sha256"`
+	Created          string `json:"created"`
+	LastUsed         string `json:"last_used"`
+	Size             int64  `json:"size"`
+}
+
+// installCacheKeyData is everything that determines the result of an install, the cache key is the
+// sha256 of this. The key is only ever stored as a hash, so (resolved) credentials in the rendered
+// config or instance never end up on disk.
+type installCacheKeyData struct {
+	SourceDiskSha256 string   `json:"source_disk_sha256"`
+	PlatformType     string   `json:"platform_type"`
+	BoxenVersion     string   `json:"boxen_version"`
+	InstallConfig    []string `json:"install_config"`
+	Instance         string   `json:"instance"`
+}
+
+// installCacheKey returns the install cache key for the (packaging) instance i.
+func installCacheKey(i *config.Instance, sourceDiskSha256 string) (string, error) {
+	funcs := template.FuncMap{}
+
+	for k, v := range configTemplateFuncs {
+		funcs[k] = v
+	}
+
+	funcs["hash"] = func(scheme, password string) (string, error) {
+		return util.HashPasswordWithSalt(scheme, password, installCacheSalt)
+	}
+
+	installConfig, err := renderInitialConfig(i, funcs)
+	if err != nil {
+		return "", err
+	}
+
+	inst, err := yaml.Marshal(i)
+	if err != nil {
+		return "", err
+	}
+
+	b, err := json.Marshal(&installCacheKeyData{
+		SourceDiskSha256: sourceDiskSha256,
+		PlatformType:     i.PlatformType,
+		BoxenVersion:     Version,
+		InstallConfig:    installConfig,
+		Instance:         string(inst),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.Sum256(b)
+
+	return hex.EncodeToString(h[:]), nil
+}
+
+func (b *Boxen) installCachePath(elem ...string) string {
+	return filepath.Join(append([]string{util.ExpandPath(b.installCacheDir)}, elem...)...)
+}
+
+func readInstallCacheEntry(f string) (*InstallCacheEntry, error) {
+	raw, err := os.ReadFile(f)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &InstallCacheEntry{}
+
+	err = json.Unmarshal(raw, e)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"%w: failed parsing install cache entry '%s': %s",
+			util.ErrInspectionError,
+			f,
+			err,
+		)
+	}
+
+	return e, nil
+}
+
+func writeInstallCacheEntry(f string, e *InstallCacheEntry) error {
+	raw, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(f, append(raw, '\n'), util.FilePerms)
+}
+
+// linkOrCopyFile hard links s to d, falling back to copying if s and d are on different
+// filesystems (i.e. the cache is in the users home and the temp dir is a tmpfs).
+func linkOrCopyFile(s, d string) error {
+	err := os.Link(s, d)
+	if err == nil {
+		return nil
+	}
+
+	return util.CopyFile(s, d)
+}
+
+// installCacheLookup checks the install cache for the key, on a hit the cached disk is placed at d,
+// the cached installed files next to it, and true is returned.
+func (b *Boxen) installCacheLookup(key, d string, installedFiles []string) (bool, error) {
+	if b.installCacheDir == "" {
+		return false, nil
+	}
+
+	e, err := readInstallCacheEntry(b.installCachePath(key, installCacheEntry))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	_ = os.Remove(d)
+
+	// entries missing any of the installed files (i.e. stored before the platform was uefi) are
+	// treated as a miss
+	for _, f := range installedFiles {
+		if !util.FileExists(b.installCachePath(key, f)) {
+			return false, nil
+		}
+	}
+
+	err = linkOrCopyFile(b.installCachePath(key, installCacheDisk), d)
+	if err != nil {
+		return false, err
+	}
+
+	for _, f := range installedFiles {
+		_ = os.Remove(filepath.Join(filepath.Dir(d), f))
+
+		err = linkOrCopyFile(b.installCachePath(key, f), filepath.Join(filepath.Dir(d), f))
+		if err != nil {
+			return false, err
+		}
+	}
+
+	e.LastUsed = time.Now().UTC().Format(time.RFC3339)
+
+	return true, writeInstallCacheEntry(b.installCachePath(key, installCacheEntry), e)
+}
+
+// installCacheStore stores the installed disk s, and the installed files next to it, in the install
+// cache. The entry is written to a temporary directory first and renamed into place, so a partially
+// written entry is never a hit.
+func (b *Boxen) installCacheStore(
+	key, s string,
+	installedFiles []string,
+	m *ImageManifest,
+) error {
+	if b.installCacheDir == "" {
+		return nil
+	}
+
+	err := os.MkdirAll(b.installCachePath(), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	tmpDir, err := os.MkdirTemp(b.installCachePath(), fmt.Sprintf(".%s", key))
+	if err != nil {
+		return err
+	}
+
+	defer os.RemoveAll(tmpDir)
+
+	err = linkOrCopyFile(s, filepath.Join(tmpDir, installCacheDisk))
+	if err != nil {
+		return err
+	}
+
+	for _, f := range installedFiles {
+		err = linkOrCopyFile(filepath.Join(filepath.Dir(s), f), filepath.Join(tmpDir, f))
+		if err != nil {
+			return err
+		}
+	}
+
+	info, err := os.Stat(filepath.Join(tmpDir, installCacheDisk))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	err = writeInstallCacheEntry(filepath.Join(tmpDir, installCacheEntry), &InstallCacheEntry{
+		Key:              key,
+		PlatformType:     m.PlatformType,
+		Version:          m.Version,
+		BoxenVersion:     m.BoxenVersion,
+		SourceDisk:       m.SourceDisk,
+		SourceDiskSha256: m.SourceDiskSha256,
+		Created:          now,
+		LastUsed:         now,
+		Size:             info.Size(),
+	})
+	if err != nil {
+		return err
+	}
+
+	// a concurrent build may have stored the same key already, that is fine, keep that one
+	_ = os.RemoveAll(b.installCachePath(key))
+
+	return os.Rename(tmpDir, b.installCachePath(key))
+}
+
+// ListInstallCache returns all entries in the install cache, most recently used first.
+func (b *Boxen) ListInstallCache() ([]*InstallCacheEntry, error) {
+	if b.installCacheDir == "" {
+		return nil, fmt.Errorf("%w: no install cache directory set", util.ErrValidationError)
+	}
+
+	dirs, err := os.ReadDir(b.installCachePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var entries []*InstallCacheEntry
+
+	for _, d := range dirs {
+		if !d.IsDir() || d.Name()[0] == '.' {
+			continue
+		}
+
+		e, err := readInstallCacheEntry(b.installCachePath(d.Name(), installCacheEntry))
+		if err != nil {
+			b.Logger.Infof("skipping invalid install cache entry '%s': %s", d.Name(), err)
+
+			continue
+		}
+
+		entries = append(entries, e)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastUsed > entries[j].LastUsed
+	})
+
+	return entries, nil
+}
+
+// PruneInstallCache removes entries from the install cache that have not been used for longer than
+// olderThan (if not zero) and that are for the platformType (if not empty), returning the removed
+// entries. If all is true every entry is removed.
+func (b *Boxen) PruneInstallCache(
+	olderThan time.Duration,
+	platformType string,
+	all bool,
+) ([]*InstallCacheEntry, error) {
+	if !all && olderThan == 0 && platformType == "" {
+		return nil, fmt.Errorf(
+			"%w: refusing to prune install cache without any filter, use all to remove everything",
+			util.ErrValidationError,
+		)
+	}
+
+	entries, err := b.ListInstallCache()
+	if err != nil {
+		return nil, err
+	}
+
+	var pruned []*InstallCacheEntry
+
+	for _, e := range entries {
+		if !all {
+			if platformType != "" && e.PlatformType != platformType {
+				continue
+			}
+
+			lastUsed, err := time.Parse(time.RFC3339, e.LastUsed)
+			if olderThan != 0 && err == nil && time.Since(lastUsed) < olderThan {
+				continue
+			}
+		}
+
+		b.Logger.Infof("pruning install cache entry '%s' (%s %s)", e.Key, e.PlatformType, e.Version)
+
+		err = os.RemoveAll(b.installCachePath(e.Key))
+		if err != nil {
+			return pruned, err
+		}
+
+		pruned = append(pruned, e)
+	}
+
+	return pruned, nil
+}
@@ -0,0 +1,154 @@
+package boxen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/carlmontanari/boxen/boxen"
+	"github.com/carlmontanari/boxen/boxen/config"
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+const testInstallCacheSha256 = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+// testInstallCacheTemplate writes a copy of the builtin arista_veos initial config template, with
+// extra appended, and points the template override env var at it.
+func testInstallCacheTemplate(t *testing.T, extra string) {
+	t.Helper()
+
+	builtin, err := boxen.Assets.ReadFile("assets/configs/arista_veos.template")
+	if err != nil {
+		t.Fatalf("failed reading builtin template: %s", err)
+	}
+
+	f := filepath.Join(t.TempDir(), "arista_veos.template")
+
+	err = os.WriteFile(f, append(builtin, []byte(extra)...), util.FilePerms)
+	if err != nil {
+		t.Fatalf("failed writing template: %s", err)
+	}
+
+	t.Setenv("BOXEN_ARISTA_VEOS_INITIAL_CONFIG_TEMPLATE", f)
+}
+
+func TestInstallCacheKey(t *testing.T) {
+	newInstance := func() *config.Instance {
+		return &config.Instance{
+			Name:         "arista_veos",
+			PlatformType: "arista_veos",
+			Disk:         "disk.qcow2",
+			ID:           1,
+			Credentials: &config.Credentials{
+				Username:     "boxen",
+				PasswordFrom: &config.CredentialSource{Env: "BOXEN_TEST_CACHE_PASSWORD"},
+			},
+			Hardware: &config.Hardware{Memory: 2048, NicCount: 8, NicPerBus: 26},
+		}
+	}
+
+	tests := []struct {
+		desc        string
+		modify      func(t *testing.T, i *config.Instance, sha *string)
+		wantChanged bool
+	}{
+		{
+			desc:   "same inputs",
+			modify: func(*testing.T, *config.Instance, *string) {},
+		},
+		{
+			desc: "same password from the source",
+			modify: func(t *testing.T, _ *config.Instance, _ *string) {
+				t.Setenv("BOXEN_TEST_CACHE_PASSWORD", "b0x3n")
+			},
+		},
+		{
+			desc: "template override identical to the builtin template",
+			modify: func(t *testing.T, _ *config.Instance, _ *string) {
+				testInstallCacheTemplate(t, "")
+			},
+		},
+		{
+			desc: "source disk sha",
+			modify: func(_ *testing.T, _ *config.Instance, sha *string) {
+				*sha = "60303ae22b998861bce3b28f33eec1be758a213c86c93c076dbe9f558c11c752"
+			},
+			wantChanged: true,
+		},
+		{
+			desc: "boxen version",
+			modify: func(t *testing.T, _ *config.Instance, _ *string) {
+				orig := Version
+				t.Cleanup(func() { Version = orig })
+
+				Version = "9.9.9"
+			},
+			wantChanged: true,
+		},
+		{
+			desc: "template",
+			modify: func(t *testing.T, _ *config.Instance, _ *string) {
+				testInstallCacheTemplate(t, "\nip domain-name lab")
+			},
+			wantChanged: true,
+		},
+		{
+			desc: "password from the source",
+			modify: func(t *testing.T, _ *config.Instance, _ *string) {
+				t.Setenv("BOXEN_TEST_CACHE_PASSWORD", "n3wb0x3n")
+			},
+			wantChanged: true,
+		},
+		{
+			desc: "password source",
+			modify: func(t *testing.T, i *config.Instance, _ *string) {
+				t.Setenv("BOXEN_TEST_CACHE_OTHER_PASSWORD", "b0x3n")
+
+				i.Credentials.PasswordFrom = &config.CredentialSource{
+					Env: "BOXEN_TEST_CACHE_OTHER_PASSWORD",
+				}
+			},
+			wantChanged: true,
+		},
+		{
+			desc: "username",
+			modify: func(_ *testing.T, i *config.Instance, _ *string) {
+				i.Credentials.Username = "admin"
+			},
+			wantChanged: true,
+		},
+		{
+			desc: "ssh keys",
+			modify: func(_ *testing.T, i *config.Instance, _ *string) {
+				i.Credentials.SSHKeys = []string{
+					"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAILXMN7mj0jOxyukcUD9aSdRSAiM6QmtRlXlRujoflUx6",
+				}
+			},
+			wantChanged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Setenv("BOXEN_TEST_CACHE_PASSWORD", "b0x3n")
+
+			want, err := installCacheKey(newInstance(), testInstallCacheSha256)
+			if err != nil {
+				t.Fatalf("%s: failed building cache key: %s", tt.desc, err)
+			}
+
+			i, sha := newInstance(), testInstallCacheSha256
+
+			tt.modify(t, i, &sha)
+
+			got, err := installCacheKey(i, sha)
+			if err != nil {
+				t.Fatalf("%s: failed building cache key: %s", tt.desc, err)
+			}
+
+			if changed := got != want; changed != tt.wantChanged {
+				t.Fatalf("%s: cache key changed %t, want %t", tt.desc, changed, tt.wantChanged)
+			}
+		})
+	}
+}
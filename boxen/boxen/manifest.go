@@ -0,0 +1,338 @@
+package boxen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/carlmontanari/boxen/boxen/config"
+	"github.com/carlmontanari/boxen/boxen/docker"
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+const (
+	// ImageManifestFile is the name of the manifest file embedded in the root of packaged images.
+	ImageManifestFile = "boxen-manifest.json"
+	// ImageManifestSchemaVersion is the current schema version of the ImageManifest.
+	ImageManifestSchemaVersion = 1
+
+	// ImageLabelPrefix is the prefix of all boxen specific image labels.
+	ImageLabelPrefix = "com.github.carlmontanari.boxen."
+
+	ociLabelPrefix = "org.opencontainers.image."
+	boxenSource    = "https://github.com/carlmontanari/boxen"
+)
+
+// ImageManifest describes the contents of a packaged image, it is embedded in the image as
+// ImageManifestFile and (mostly) mirrored in the image labels so that tooling can pick images
+// without having to parse tags or pull/run the image.
+type ImageManifest struct {
+	SchemaVersion int    `json:"schema_version"`
+	Vendor        string `json:"vendor"`
+	Platform      string `json:"platform"`
+	PlatformType  string `json:"platform_type"`
+	Version       string `json:"version"`
+	BoxenVersion  string `json:"boxen_version"`
+	Created       string `json:"created"`
+	// SourceDisk is the file name of the disk the image was packaged from.
+	SourceDisk string `json:"source_disk"`
+	// SourceDiskSha256 is the sha256 checksum of the disk the image was packaged from.
+	SourceDiskSha256 string                    `json:"source_disk_sha256"`
+	Credentials      *ImageManifestCredentials `json:"credentials"`
+	TCPNatPorts      []int                     `json:"tcp_nat_ports"`
+	UDPNatPorts      []int                     `json:"udp_nat_ports"`
+	NicCount         int                       `json:"nic_count"`
+	Memory           int                       `json:"memory"`
+}
+
+// ImageManifestCredentials is a reference to the credentials of a packaged image. Passwords are
+// never recorded, only the credential source (if any) the image resolves the password from, or
+// whether the image uses the boxen default password.
+type ImageManifestCredentials struct {
+	Username        string `json:"username,omitempty"`
+	UsernameFrom    string `json:"username_from,omitempty"`
+	PasswordFrom    string `json:"password_from,omitempty"`
+	DefaultPassword bool   `json:"default_password"`
+}
+
+func newImageManifestCredentials(c *config.Credentials) *ImageManifestCredentials {
+	mc := &ImageManifestCredentials{}
+
+	if c.UsernameFrom != nil {
+		mc.UsernameFrom = c.UsernameFrom.String()
+	} else {
+		mc.Username = c.Username
+	}
+
+	if c.PasswordFrom != nil {
+		mc.PasswordFrom = c.PasswordFrom.String()
+	} else {
+		mc.DefaultPassword = c.Password == config.NewDefaultCredentials().Password
+	}
+
+	return mc
+}
+
+func (b *Boxen) newImageManifest(
+	i *installInfo,
+	instance *config.Instance,
+	tcpNatPorts, udpNatPorts []int,
+) (*ImageManifest, error) {
+	b.Logger.Debugf("calculating checksum of source disk '%s'", i.srcDisk.Disk)
+
+	sum, err := util.FileSha256(i.srcDisk.Disk)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ImageManifest{
+		SchemaVersion:    ImageManifestSchemaVersion,
+		Vendor:           i.srcDisk.Vendor,
+		Platform:         i.srcDisk.Platform,
+		PlatformType:     i.srcDisk.PlatformType,
+		Version:          i.srcDisk.Version,
+		BoxenVersion:     Version,
+		Created:          time.Now().UTC().Format(time.RFC3339),
+		SourceDisk:       filepath.Base(i.srcDisk.Disk),
+		SourceDiskSha256: sum,
+		Credentials:      newImageManifestCredentials(instance.Credentials),
+		TCPNatPorts:      tcpNatPorts,
+		UDPNatPorts:      udpNatPorts,
+		NicCount:         instance.Hardware.NicCount,
+		Memory:           instance.Hardware.Memory,
+	}, nil
+}
+
+// Dump writes the manifest as json to the file f.
+func (m *ImageManifest) Dump(f string) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(f, append(b, '\n'), util.FilePerms)
+}
+
+func joinPorts(ports []int) string {
+	s := make([]string, len(ports))
+
+	for i, p := range ports {
+		s[i] = strconv.Itoa(p)
+	}
+
+	return strings.Join(s, ",")
+}
+
+func splitPorts(s string) ([]int, error) {
+	var ports []int
+
+	if s == "" {
+		return ports, nil
+	}
+
+	for _, p := range strings.Split(s, ",") {
+		port, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid port '%s' in image label", util.ErrInspectionError, p)
+		}
+
+		ports = append(ports, port)
+	}
+
+	return ports, nil
+}
+
+// Labels returns the image labels for the manifest -- the standard oci annotations plus boxen
+// specific labels (prefixed with ImageLabelPrefix) holding the manifest fields.
+func (m *ImageManifest) Labels() map[string]string {
+	labels := map[string]string{
+		ociLabelPrefix + "title":       fmt.Sprintf("boxen %s", m.PlatformType),
+		ociLabelPrefix + "description": fmt.Sprintf("boxen packaged %s %s", m.PlatformType, m.Version),
+		ociLabelPrefix + "vendor":      m.Vendor,
+		ociLabelPrefix + "version":     m.Version,
+		ociLabelPrefix + "created":     m.Created,
+		ociLabelPrefix + "source":      boxenSource,
+
+		ImageLabelPrefix + "schema-version":     strconv.Itoa(m.SchemaVersion),
+		ImageLabelPrefix + "vendor":             m.Vendor,
+		ImageLabelPrefix + "platform":           m.Platform,
+		ImageLabelPrefix + "platform-type":      m.PlatformType,
+		ImageLabelPrefix + "version":            m.Version,
+		ImageLabelPrefix + "boxen-version":      m.BoxenVersion,
+		ImageLabelPrefix + "source-disk":        m.SourceDisk,
+		ImageLabelPrefix + "source-disk-sha256": m.SourceDiskSha256,
+		ImageLabelPrefix + "tcp-nat-ports":      joinPorts(m.TCPNatPorts),
+		ImageLabelPrefix + "udp-nat-ports":      joinPorts(m.UDPNatPorts),
+		ImageLabelPrefix + "nic-count":          strconv.Itoa(m.NicCount),
+		ImageLabelPrefix + "memory":             strconv.Itoa(m.Memory),
+		ImageLabelPrefix + "manifest":           "/" + ImageManifestFile,
+	}
+
+	if m.Credentials != nil {
+		labels[ImageLabelPrefix+"username"] = m.Credentials.Username
+		labels[ImageLabelPrefix+"username-from"] = m.Credentials.UsernameFrom
+		labels[ImageLabelPrefix+"password-from"] = m.Credentials.PasswordFrom
+		labels[ImageLabelPrefix+"default-password"] = strconv.FormatBool(
+			m.Credentials.DefaultPassword,
+		)
+	}
+
+	return labels
+}
+
+// NewImageManifestFromLabels rebuilds an ImageManifest from the labels of a packaged image.
+func NewImageManifestFromLabels(labels map[string]string) (*ImageManifest, error) {
+	l := func(k string) string { return labels[ImageLabelPrefix+k] }
+
+	if l("schema-version") == "" {
+		return nil, fmt.Errorf(
+			"%w: image has no boxen labels, was it packaged with boxen?",
+			util.ErrInspectionError,
+		)
+	}
+
+	var err error
+
+	m := &ImageManifest{
+		Vendor:           l("vendor"),
+		Platform:         l("platform"),
+		PlatformType:     l("platform-type"),
+		Version:          l("version"),
+		BoxenVersion:     l("boxen-version"),
+		Created:          labels[ociLabelPrefix+"created"],
+		SourceDisk:       l("source-disk"),
+		SourceDiskSha256: l("source-disk-sha256"),
+		Credentials: &ImageManifestCredentials{
+			Username:        l("username"),
+			UsernameFrom:    l("username-from"),
+			PasswordFrom:    l("password-from"),
+			DefaultPassword: l("default-password") == "true",
+		},
+	}
+
+	for k, v := range map[string]*int{
+		"schema-version": &m.SchemaVersion,
+		"nic-count":      &m.NicCount,
+		"memory":         &m.Memory,
+	} {
+		*v, err = strconv.Atoi(l(k))
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: invalid value '%s' for image label '%s'",
+				util.ErrInspectionError,
+				l(k),
+				ImageLabelPrefix+k,
+			)
+		}
+	}
+
+	m.TCPNatPorts, err = splitPorts(l("tcp-nat-ports"))
+	if err != nil {
+		return nil, err
+	}
+
+	m.UDPNatPorts, err = splitPorts(l("udp-nat-ports"))
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// readImageManifestFile copies the embedded manifest out of the image repo:tag.
+func (b *Boxen) readImageManifestFile(
+	repo, tag string,
+	engine docker.Engine,
+) (*ImageManifest, error) {
+	cid, err := docker.Create(
+		docker.WithEngine(engine),
+		docker.WithRepo(repo),
+		docker.WithTag(tag),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		rmErr := docker.RmContainer(cid, docker.WithEngine(engine))
+		if rmErr != nil {
+			b.Logger.Criticalf("error removing manifest container '%s': %s", cid, rmErr)
+		}
+	}()
+
+	tmpDir, err := os.MkdirTemp(os.TempDir(), "boxen")
+	if err != nil {
+		return nil, err
+	}
+
+	defer os.RemoveAll(tmpDir)
+
+	err = docker.CopyFromContainer(
+		"/"+ImageManifestFile,
+		ImageManifestFile,
+		docker.WithEngine(engine),
+		docker.WithContainer(cid),
+		docker.WithWorkDir(tmpDir),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.ReadFile(filepath.Join(tmpDir, ImageManifestFile))
+	if err != nil {
+		return nil, err
+	}
+
+	m := &ImageManifest{}
+
+	err = json.Unmarshal(f, m)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"%w: failed parsing image manifest: %s",
+			util.ErrInspectionError,
+			err,
+		)
+	}
+
+	return m, nil
+}
+
+// InspectImage returns the ImageManifest and labels of the packaged image repo:tag. If fromLabels
+// is true the manifest is rebuilt from the image labels only, otherwise the manifest embedded in
+// the image is read, which requires creating (but not starting) a container from the image.
+func (b *Boxen) InspectImage(
+	repo, tag string,
+	fromLabels bool,
+) (*ImageManifest, map[string]string, error) {
+	b.Logger.Infof("inspect requested for image '%s:%s'", repo, tag)
+
+	engine, err := docker.NewEngine(b.containerEngine)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	labels, err := docker.ImageLabels(repo, tag, docker.WithEngine(engine))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m, err := NewImageManifestFromLabels(labels)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if fromLabels {
+		return m, labels, nil
+	}
+
+	m, err = b.readImageManifestFile(repo, tag, engine)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return m, labels, nil
+}
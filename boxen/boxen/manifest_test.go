@@ -0,0 +1,163 @@
+package boxen_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/carlmontanari/boxen/boxen/boxen"
+	"github.com/carlmontanari/boxen/boxen/util"
+	"github.com/google/go-cmp/cmp"
+)
+
+func testImageManifest() *boxen.ImageManifest {
+	return &boxen.ImageManifest{
+		SchemaVersion:    boxen.ImageManifestSchemaVersion,
+		Vendor:           "arista",
+		Platform:         "veos",
+		PlatformType:     "arista_veos",
+		Version:          "4.28.0F",
+		BoxenVersion:     "0.1.0",
+		Created:          "2024-05-02T10:11:12Z",
+		SourceDisk:       "vEOS-lab-4.28.0F.vmdk",
+		SourceDiskSha256: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		Credentials: &boxen.ImageManifestCredentials{
+			Username:     "boxen",
+			PasswordFrom: "env:BOXEN_PASSWORD",
+		},
+		TCPNatPorts: []int{22, 23, 443, 830},
+		UDPNatPorts: []int{161},
+		NicCount:    8,
+		Memory:      2048,
+	}
+}
+
+func TestImageManifestLabelsRoundTrip(t *testing.T) {
+	tests := []struct {
+		desc   string
+		modify func(m *boxen.ImageManifest)
+	}{
+		{
+			desc:   "full manifest",
+			modify: func(*boxen.ImageManifest) {},
+		},
+		{
+			desc: "default password and username from a source",
+			modify: func(m *boxen.ImageManifest) {
+				m.Credentials = &boxen.ImageManifestCredentials{
+					UsernameFrom:    "file:/run/secrets/username",
+					DefaultPassword: true,
+				}
+			},
+		},
+		{
+			desc: "no nat ports",
+			modify: func(m *boxen.ImageManifest) {
+				m.TCPNatPorts = nil
+				m.UDPNatPorts = nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			want := testImageManifest()
+			tt.modify(want)
+
+			got, err := boxen.NewImageManifestFromLabels(want.Labels())
+			if err != nil {
+				t.Fatalf("%s: failed building manifest from labels: %s", tt.desc, err)
+			}
+
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Fatalf("%s: manifest does not match (-want +got):\n%s", tt.desc, diff)
+			}
+		})
+	}
+}
+
+func TestNewImageManifestFromLabelsInvalid(t *testing.T) {
+	tests := []struct {
+		desc   string
+		modify func(labels map[string]string)
+	}{
+		{
+			desc: "no labels",
+			modify: func(labels map[string]string) {
+				for k := range labels {
+					delete(labels, k)
+				}
+			},
+		},
+		{
+			desc: "only oci labels",
+			modify: func(labels map[string]string) {
+				for k := range labels {
+					if strings.HasPrefix(k, boxen.ImageLabelPrefix) {
+						delete(labels, k)
+					}
+				}
+			},
+		},
+		{
+			desc: "missing schema version",
+			modify: func(labels map[string]string) {
+				delete(labels, boxen.ImageLabelPrefix+"schema-version")
+			},
+		},
+		{
+			desc: "missing nic count",
+			modify: func(labels map[string]string) {
+				delete(labels, boxen.ImageLabelPrefix+"nic-count")
+			},
+		},
+		{
+			desc: "missing memory",
+			modify: func(labels map[string]string) {
+				delete(labels, boxen.ImageLabelPrefix+"memory")
+			},
+		},
+		{
+			desc: "non integer schema version",
+			modify: func(labels map[string]string) {
+				labels[boxen.ImageLabelPrefix+"schema-version"] = "v1"
+			},
+		},
+		{
+			desc: "non integer nic count",
+			modify: func(labels map[string]string) {
+				labels[boxen.ImageLabelPrefix+"nic-count"] = "eight"
+			},
+		},
+		{
+			desc: "non integer memory",
+			modify: func(labels map[string]string) {
+				labels[boxen.ImageLabelPrefix+"memory"] = "2G"
+			},
+		},
+		{
+			desc: "non integer tcp nat port",
+			modify: func(labels map[string]string) {
+				labels[boxen.ImageLabelPrefix+"tcp-nat-ports"] = "22,ssh"
+			},
+		},
+		{
+			desc: "empty udp nat port",
+			modify: func(labels map[string]string) {
+				labels[boxen.ImageLabelPrefix+"udp-nat-ports"] = "161,"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			labels := testImageManifest().Labels()
+			tt.modify(labels)
+
+			_, err := boxen.NewImageManifestFromLabels(labels)
+			if !errors.Is(err, util.ErrInspectionError) {
+				t.Fatalf("%s: expected inspection error, got: %v", tt.desc, err)
+			}
+		})
+	}
+}
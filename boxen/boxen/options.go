@@ -21,3 +21,23 @@ func WithLogger(l *logging.Instance) Option {
 		return nil
 	}
 }
+
+// WithContainerEngine allows for passing the name of the container engine (docker, podman or auto)
+// to use for packaging/inspecting images to the NewBoxen function.
+func WithContainerEngine(e string) Option {
+	return func(o *args) error {
+		o.containerEngine = e
+
+		return nil
+	}
+}
+
+// WithInstallCacheDir allows for passing the directory to cache installed disks in when packaging
+// to the NewBoxen function, see DefaultInstallCacheDir.
+func WithInstallCacheDir(d string) Option {
+	return func(o *args) error {
+		o.installCacheDir = d
+
+		return nil
+	}
+}
@@ -1,17 +1,14 @@
 package boxen
 
 import (
-	"bytes"
-	"context"
-	"errors"
 	"fmt"
-	"html/template"
+	"io"
 	"io/ioutil"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
-	"sync"
+	"text/template"
+	"time"
 
 	"github.com/carlmontanari/boxen/boxen"
 	"github.com/carlmontanari/boxen/boxen/config"
@@ -21,20 +18,45 @@ import (
 	"github.com/carlmontanari/boxen/boxen/util"
 )
 
+// installEnvFile is the name of the env-file used to pass env credential sources to the install
+// container.
+const installEnvFile = "install.env"
+
+// installCidFileTimeout is how long (in seconds) to wait for the install container id file.
+const installCidFileTimeout = 60
+
 type dockerfileTemplateData struct {
-	LocalHost         string
 	RequiredFiles     []string
 	ExposedTCPPorts   []int
 	ExposedUDPPorts   []int
 	TimeoutMultiplier int
-	LogLevel          string
 	Sparsify          int
 	BoxenVersion      string
 	BinaryOverride    bool
+	ManifestFile      string
+	StartDefaultsFile string
+	Labels            map[string]string
+	UEFI              bool
+}
+
+// dockerfileTemplateFuncs are the functions available in the packaging dockerfile templates.
+var dockerfileTemplateFuncs = template.FuncMap{ //nolint:gochecknoglobals
+	"quote": dockerfileQuote,
+}
+
+// dockerfileQuote double quotes s for use in a dockerfile instruction (i.e. a LABEL value), quotes
+// and backslashes are escaped, as are "$" so that the builder does not try variable substitution.
+func dockerfileQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", " ")
+
+	return fmt.Sprintf(`"%s"`, r.Replace(s))
 }
 
 type dockerignoreTemplateData struct {
-	RequiredFiles []string
+	RequiredFiles     []string
+	BinaryOverride    bool
+	ManifestFile      string
+	StartDefaultsFile string
 }
 
 func writeDockerfiles(
@@ -43,6 +65,9 @@ func writeDockerfiles(
 	exposedTCPPorts, exposedUDPPorts []int,
 	timeoutModifier int,
 	binaryOverride bool,
+	manifest *ImageManifest,
+	uefi bool,
+	installedFiles []string,
 ) error {
 	inclFiles = append(inclFiles, "disk.qcow2")
 
@@ -54,7 +79,9 @@ func writeDockerfiles(
 		return err
 	}
 
-	dockerfileTpl, err := template.ParseFS(boxen.Assets, "assets/packaging/Dockerfile.template")
+	dockerfileTpl, err := template.New("Dockerfile.template").
+		Funcs(dockerfileTemplateFuncs).
+		ParseFS(boxen.Assets, "assets/packaging/Dockerfile.template")
 	if err != nil {
 		return err
 	}
@@ -65,19 +92,31 @@ func writeDockerfiles(
 	}
 
 	dockerfileData := &dockerfileTemplateData{
-		LocalHost:         util.GetPreferredIP(),
 		RequiredFiles:     inclFiles,
 		ExposedTCPPorts:   exposedTCPPorts,
 		ExposedUDPPorts:   exposedUDPPorts,
 		TimeoutMultiplier: timeoutModifier,
-		LogLevel:          util.GetEnvStrOrDefault("BOXEN_LOG_LEVEL", "info"),
 		Sparsify:          util.GetEnvIntOrDefault("BOXEN_SPARSIFY_DISK", 0),
 		BoxenVersion:      Version,
 		BinaryOverride:    binaryOverride,
+		ManifestFile:      ImageManifestFile,
+		StartDefaultsFile: PackageStartDefaultsFile,
+		Labels:            manifest.Labels(),
+		UEFI:              uefi,
 	}
 
+	// files produced by the installation only exist for the final image build
+	finalDockerfileData := *dockerfileData
+	finalDockerfileData.RequiredFiles = append(
+		append([]string{}, inclFiles...),
+		installedFiles...,
+	)
+
 	dockerignoreData := &dockerignoreTemplateData{
-		RequiredFiles: inclFiles,
+		RequiredFiles:     finalDockerfileData.RequiredFiles,
+		BinaryOverride:    binaryOverride,
+		ManifestFile:      ImageManifestFile,
+		StartDefaultsFile: PackageStartDefaultsFile,
 	}
 
 	buildDockerfileDest, err := os.Create(fmt.Sprintf("%s/build.Dockerfile", dir))
@@ -96,7 +135,7 @@ func writeDockerfiles(
 	}
 
 	_ = buildDockerfileTpl.Execute(buildDockerfileDest, dockerfileData)
-	_ = dockerfileTpl.Execute(dockerfileDest, dockerfileData)
+	_ = dockerfileTpl.Execute(dockerfileDest, &finalDockerfileData)
 	_ = dockerignoreTpl.Execute(dockerignoreDest, dockerignoreData)
 
 	return nil
@@ -104,13 +143,8 @@ func writeDockerfiles(
 
 func (b *Boxen) packageBundle(
 	i *installInfo,
-	inst platforms.Platform,
+	packageFiles []string,
 ) error {
-	packageFiles, _, err := inst.Package(filepath.Dir(i.inDisk), i.tmpDir)
-	if err != nil {
-		return err
-	}
-
 	c := config.NewPackageConfig()
 
 	platformDefaultProfile, err := GetDefaultProfile(i.srcDisk.PlatformType)
@@ -143,13 +177,9 @@ func (b *Boxen) packageBundle(
 		Advanced:      platformDefaultProfile.Advanced,
 	}
 
-	if i.username != "" {
-		c.Instances[i.srcDisk.PlatformType].Credentials.Username = i.username
-	}
-
-	if i.password != "" {
-		c.Instances[i.srcDisk.PlatformType].Credentials.Password = i.password
-	}
+	// credential sources are stored as is (not resolved!) in the packaged config, and literal
+	// passwords are rejected up front, so the packaged image never contains the actual password.
+	c.Instances[i.srcDisk.PlatformType].Credentials.Merge(i.credentials)
 
 	c.Instances[i.srcDisk.PlatformType].Hardware.MonitorPort = b.allocateMonitorPort(1)
 
@@ -166,6 +196,12 @@ func (b *Boxen) packageBundle(
 		return err
 	}
 
+	// files are copied into the images as is, so set the modes here rather than in extra layers
+	err = os.Chmod(fmt.Sprintf("%s/tc-tap-ifup", i.tmpDir), 0o777) //nolint:gosec,gomnd
+	if err != nil {
+		return err
+	}
+
 	usrBoxenBinary := util.GetEnvStrOrDefault("BOXEN_PACKAGE_BINARY", "")
 	binaryOverride := false
 
@@ -180,9 +216,57 @@ func (b *Boxen) packageBundle(
 			return err
 		}
 
+		err = os.Chmod(fmt.Sprintf("%s/boxen", i.tmpDir), 0o755) //nolint:gosec,gomnd
+		if err != nil {
+			return err
+		}
+
 		binaryOverride = true
 	}
 
+	manifest, err := b.newImageManifest(
+		i,
+		c.Instances[i.srcDisk.PlatformType],
+		platformDefaultProfile.TPCNatPorts,
+		platformDefaultProfile.UDPNatPorts,
+	)
+	if err != nil {
+		return err
+	}
+
+	err = manifest.Dump(fmt.Sprintf("%s/%s", i.tmpDir, ImageManifestFile))
+	if err != nil {
+		return err
+	}
+
+	i.manifest = manifest
+
+	err = writePackageStartDefaults(fmt.Sprintf("%s/%s", i.tmpDir, PackageStartDefaultsFile))
+	if err != nil {
+		return err
+	}
+
+	if b.installCacheDir != "" && !i.installed {
+		i.installCacheKey, err = installCacheKey(
+			c.Instances[i.srcDisk.PlatformType],
+			manifest.SourceDiskSha256,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	uefi := platformDefaultProfile.Advanced != nil &&
+		platformDefaultProfile.Advanced.Firmware.IsUEFI()
+
+	// when packaging from source the firmware vars are one of the run files of the source disk,
+	// otherwise the installation creates them
+	i.installedFiles = nil
+
+	if uefi && !util.StringSliceContains(config.FirmwareVarsFile, packageFiles) {
+		i.installedFiles = append(i.installedFiles, config.FirmwareVarsFile)
+	}
+
 	err = writeDockerfiles(
 		i.tmpDir,
 		packageFiles,
@@ -190,6 +274,9 @@ func (b *Boxen) packageBundle(
 		platformDefaultProfile.UDPNatPorts,
 		util.GetTimeoutMultiplier(),
 		binaryOverride,
+		manifest,
+		uefi,
+		i.installedFiles,
 	)
 	if err != nil {
 		return err
@@ -203,22 +290,6 @@ func (b *Boxen) packageBundle(
 	return os.Rename(i.newDisk, fmt.Sprintf("%s/disk.qcow2", i.tmpDir))
 }
 
-func (b *Boxen) fileServer(wg *sync.WaitGroup, packageDir string) *http.Server {
-	srv := &http.Server{Addr: ":6666"}
-
-	http.Handle("/", http.FileServer(http.Dir(packageDir)))
-
-	go func() {
-		defer wg.Done()
-
-		if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
-			b.Logger.Criticalf("file server error: %v", err)
-		}
-	}()
-
-	return srv
-}
-
 func (b *Boxen) buildBaseImage(i *installInfo, repo, tag string) error {
 	f, err := os.OpenFile(
 		fmt.Sprintf("%s/initial_build.log", i.tmpDir),
@@ -234,6 +305,7 @@ func (b *Boxen) buildBaseImage(i *installInfo, repo, tag string) error {
 	defer f.Close()
 
 	err = docker.Build(
+		docker.WithEngine(b.engine),
 		docker.WithWorkDir(i.tmpDir),
 		docker.WithDockerfile("build.Dockerfile"),
 		docker.WithRepo(repo),
@@ -256,6 +328,94 @@ func (b *Boxen) buildBaseImage(i *installInfo, repo, tag string) error {
 	return err
 }
 
+// validatePackageCredentials ensures the credentials can be stored in the packaged config -- a
+// literal password (other than the boxen default) would be stored in plaintext in the image, and
+// only env and file sources are supported as the packaged image must be able to resolve them at
+// runtime (i.e. via "docker run -e" or mounted docker/k8s secrets).
+func validatePackageCredentials(c *config.Credentials) error {
+	if c == nil {
+		return nil
+	}
+
+	err := c.Validate()
+	if err != nil {
+		return err
+	}
+
+	if c.PasswordFrom == nil && c.Password != "" &&
+		c.Password != config.NewDefaultCredentials().Password {
+		return fmt.Errorf(
+			"%w: literal passwords are stored in plaintext in the packaged image, provide the "+
+				"password via an env or file credential source ('--password-from') instead",
+			util.ErrValidationError,
+		)
+	}
+
+	for _, src := range c.Sources() {
+		switch {
+		case src.Env != "":
+		case src.File != "":
+			if !filepath.IsAbs(src.File) {
+				return fmt.Errorf(
+					"%w: file credential source '%s' must be an absolute path when packaging",
+					util.ErrValidationError,
+					src.File,
+				)
+			}
+		default:
+			return fmt.Errorf(
+				"%w: %s credential sources are not supported for packaging, use env or file",
+				util.ErrValidationError,
+				src.Kind(),
+			)
+		}
+	}
+
+	return nil
+}
+
+// installCredentialOpts returns the docker options required to make any credential sources
+// resolvable in the install container. Env sources are resolved and written to an env-file in the
+// temporary directory (the caller must remove this after use), file sources are mounted read-only
+// at the same path in the container.
+func (b *Boxen) installCredentialOpts(i *installInfo) ([]docker.Option, error) {
+	if i.credentials == nil {
+		return nil, nil
+	}
+
+	var opts []docker.Option
+
+	var envLines []string
+
+	for _, src := range i.credentials.Sources() {
+		if src.File != "" {
+			opts = append(opts, docker.WithVolume(fmt.Sprintf("%s:%s:ro", src.File, src.File)))
+
+			continue
+		}
+
+		v, err := src.Resolve()
+		if err != nil {
+			return nil, err
+		}
+
+		envLines = append(envLines, fmt.Sprintf("%s=%s", src.Env, v))
+	}
+
+	if len(envLines) == 0 {
+		return opts, nil
+	}
+
+	envFile := fmt.Sprintf("%s/%s", i.tmpDir, installEnvFile)
+
+	err := os.WriteFile(envFile, []byte(strings.Join(envLines, "\n")+"\n"), 0o600) //nolint:gomnd
+	if err != nil {
+		return nil, err
+	}
+
+	return append(opts, docker.WithEnvFile(envFile)), nil
+}
+
 func (b *Boxen) runInstallImage(i *installInfo, repo, tag string) error {
 	f, err := os.OpenFile(
 		fmt.Sprintf("%s/install_build.log", i.tmpDir),
@@ -270,25 +430,34 @@ func (b *Boxen) runInstallImage(i *installInfo, repo, tag string) error {
 
 	defer f.Close()
 
-	// SocketReceiver accepts the "normal" (boxen process, not instance stuff) logs and pumps that
-	// into the "normal" local boxen logger.
-	sr, err := logging.NewSocketReceiver(util.GetPreferredIP(), 6667, b.Logger) //nolint: gomnd
+	// the "normal" (boxen process, not instance stuff) logs are emitted on the container stdout,
+	// StreamReceiver pumps them into the "normal" local boxen logger.
+	sr := logging.NewStreamReceiver(b.Logger)
+
+	defer sr.Flush()
+
+	credentialOpts, err := b.installCredentialOpts(i)
 	if err != nil {
-		b.Logger.Criticalf("error starting socket log receiver: %s", err)
+		b.Logger.Criticalf("error preparing credentials for install container: %s", err)
 
 		return err
 	}
 
-	defer sr.Close()
+	// the env file may contain resolved credentials, never leave it lying around
+	defer os.Remove(fmt.Sprintf("%s/%s", i.tmpDir, installEnvFile))
 
 	r, err := docker.Run(
-		docker.WithCidFile("install_cidfile"),
-		docker.WithPrivileged(true),
-		docker.WithRepo(repo),
-		docker.WithTag(tag),
-		docker.WithWorkDir(i.tmpDir),
-		docker.WithStdErr(f),
-		docker.WithStdOut(f),
+		append(
+			credentialOpts,
+			docker.WithEngine(b.engine),
+			docker.WithCidFile("install_cidfile"),
+			docker.WithPrivileged(true),
+			docker.WithRepo(repo),
+			docker.WithTag(tag),
+			docker.WithWorkDir(i.tmpDir),
+			docker.WithStdErr(f),
+			docker.WithStdOut(io.MultiWriter(f, sr)),
+		)...,
 	)
 	if err != nil {
 		return err
@@ -299,17 +468,25 @@ func (b *Boxen) runInstallImage(i *installInfo, repo, tag string) error {
 		return err
 	}
 
+	cid, err := docker.WaitCidFile(
+		fmt.Sprintf("%s/install_cidfile", i.tmpDir),
+		installCidFileTimeout*time.Second,
+	)
+	if err != nil {
+		b.Logger.Criticalf("error reading installation container id: %s", err)
+
+		return err
+	}
+
 	b.Logger.Infof(
 		"install logs available at '%s/install_build.log', or by inspect container '%s' logs",
 		i.tmpDir,
-		docker.ReadCidFile(fmt.Sprintf("%s/install_cidfile", i.tmpDir)),
+		cid,
 	)
 
-	waitStdoutW := &bytes.Buffer{}
-
-	err = docker.Wait(
-		docker.WithContainer(docker.ReadCidFile(fmt.Sprintf("%s/install_cidfile", i.tmpDir))),
-		docker.WithStdOut(waitStdoutW),
+	exitCode, err := docker.WaitExitCode(
+		docker.WithEngine(b.engine),
+		docker.WithContainer(cid),
 	)
 	if err != nil {
 		b.Logger.Criticalf("error waiting for installation container to exit: %s", err)
@@ -317,22 +494,25 @@ func (b *Boxen) runInstallImage(i *installInfo, repo, tag string) error {
 		return err
 	}
 
-	if waitStdoutW.Len() > 0 {
-		// we got some stdout, we want to check if its a zero (48) or a 1 (49) to know what the
-		// containers exit code was (we exit with 1 in main.go if we encounter an error, so that
-		// would be what the container returns). we should also always get a newline (10).
-		if !bytes.Equal(waitStdoutW.Bytes(), []byte{48, 10}) {
-			return fmt.Errorf(
-				"%w: docker wait indicates install container exited with non-zero exit code",
-				util.ErrCommandError,
-			)
-		}
+	// the container has exited, so the (attached) run process is done too -- waiting for it ensures
+	// all the container output has been copied to the log file and StreamReceiver
+	_ = r.Proc.Wait()
+
+	// we exit with 1 in main.go if we encounter an error, so that would be what the container
+	// returns if the installation failed.
+	if exitCode != 0 {
+		return fmt.Errorf(
+			"%w: install container exited with non-zero exit code %d",
+			util.ErrCommandError,
+			exitCode,
+		)
 	}
 
 	err = docker.CopyFromContainer(
 		"disk.qcow2",
 		"disk.qcow2",
-		docker.WithContainer(docker.ReadCidFile(fmt.Sprintf("%s/install_cidfile", i.tmpDir))),
+		docker.WithEngine(b.engine),
+		docker.WithContainer(cid),
 		docker.WithWorkDir(i.tmpDir),
 	)
 	if err != nil {
@@ -341,14 +521,33 @@ func (b *Boxen) runInstallImage(i *installInfo, repo, tag string) error {
 		return err
 	}
 
-	err = docker.RmContainer(docker.ReadCidFile(fmt.Sprintf("%s/install_cidfile", i.tmpDir)))
+	for _, installedFile := range i.installedFiles {
+		err = docker.CopyFromContainer(
+			installedFile,
+			installedFile,
+			docker.WithEngine(b.engine),
+			docker.WithContainer(cid),
+			docker.WithWorkDir(i.tmpDir),
+		)
+		if err != nil {
+			b.Logger.Criticalf(
+				"error copying '%s' from installation container: %s",
+				installedFile,
+				err,
+			)
+
+			return err
+		}
+	}
+
+	err = docker.RmContainer(cid, docker.WithEngine(b.engine))
 	if err != nil {
 		b.Logger.Criticalf("error removing installation container: %s", err)
 	}
 
 	// want to nuke the initial build image now that we have copied all the stuff out of the
 	// "install" container.
-	err = docker.RmImage(repo, tag)
+	err = docker.RmImage(repo, tag, docker.WithEngine(b.engine))
 	if err != nil {
 		b.Logger.Criticalf("error removing initial build image: %s", err)
 	}
@@ -369,6 +568,7 @@ func (b *Boxen) buildFinalImage(i *installInfo, repo, tag string) error {
 	defer f.Close()
 
 	err = docker.Build(
+		docker.WithEngine(b.engine),
 		docker.WithWorkDir(i.tmpDir),
 		docker.WithDockerfile("Dockerfile"),
 		docker.WithRepo(repo),
@@ -416,7 +616,14 @@ func (b *Boxen) packageBuildPreContainer(i *installInfo) error {
 
 	b.Logger.Debug("packaging instance created")
 
-	err = b.packageBundle(i, inst)
+	packageFiles, _, err := inst.Package(filepath.Dir(i.inDisk), i.tmpDir)
+	if err != nil {
+		b.Logger.Criticalf("error during package phase of packaging: %s", err)
+
+		return err
+	}
+
+	err = b.packageBundle(i, packageFiles)
 	if err != nil {
 		b.Logger.Criticalf("error bundling required packaging files: %s", err)
 
@@ -428,9 +635,51 @@ func (b *Boxen) packageBuildPreContainer(i *installInfo) error {
 	return nil
 }
 
-func (b *Boxen) packageBuildContainer(i *installInfo, repo, tag string) error {
-	var err error
+// packageInstallContainer builds the base image and runs the install container, leaving the
+// installed disk in the temporary directory. The installed disk is stored in the install cache (if
+// enabled) so subsequent builds of the same disk/config can skip all of this.
+func (b *Boxen) packageInstallContainer(i *installInfo, repo, tag string) error {
+	b.Logger.Infof("docker build output available at '%s/initial_build.log'", i.tmpDir)
+
+	err := b.buildBaseImage(i, repo, tag)
+	if err != nil {
+		b.Logger.Criticalf("error building base image: %s", err)
+
+		return err
+	}
+
+	b.Logger.Debug("base image building complete!")
+
+	err = b.runInstallImage(i, repo, tag)
+	if err != nil {
+		b.Logger.Criticalf("error running instance installation: %s", err)
+
+		return err
+	}
+
+	b.Logger.Debug("instance installation complete!")
+
+	if i.installCacheKey == "" {
+		return nil
+	}
 
+	err = b.installCacheStore(
+		i.installCacheKey,
+		fmt.Sprintf("%s/disk.qcow2", i.tmpDir),
+		i.installedFiles,
+		i.manifest,
+	)
+	if err != nil {
+		// not being able to cache the disk shouldn't fail the build, it'll just be slow next time
+		b.Logger.Criticalf("error storing installed disk in install cache: %s", err)
+	} else {
+		b.Logger.Infof("installed disk stored in install cache with key '%s'", i.installCacheKey)
+	}
+
+	return nil
+}
+
+func (b *Boxen) packageBuildContainer(i *installInfo, repo, tag string) error {
 	if util.GetEnvIntOrDefault("BOXEN_DEV_MODE", 0) > 0 {
 		b.Logger.Info(
 			"boxen dev mode enabled, not deleting temporary directory after installation",
@@ -441,43 +690,64 @@ func (b *Boxen) packageBuildContainer(i *installInfo, repo, tag string) error {
 
 	b.Logger.Debug("pre packaging complete, begin docker-ization!")
 
-	b.Logger.Infof("docker build output available at '%s/initial_build.log'", i.tmpDir)
+	if !i.installed {
+		hit, err := b.installCacheLookup(
+			i.installCacheKey,
+			fmt.Sprintf("%s/disk.qcow2", i.tmpDir),
+			i.installedFiles,
+		)
+		if err != nil {
+			b.Logger.Criticalf("error checking install cache: %s", err)
 
-	wg := &sync.WaitGroup{}
-	wg.Add(1)
+			return err
+		}
 
-	srv := b.fileServer(wg, i.tmpDir)
+		if hit {
+			b.Logger.Infof(
+				"install cache hit for key '%s', skipping instance installation",
+				i.installCacheKey,
+			)
+		} else {
+			err = b.packageInstallContainer(i, repo, tag)
+			if err != nil {
+				return err
+			}
+		}
+	}
 
-	err = b.buildBaseImage(i, repo, tag)
+	err := b.buildFinalImage(i, repo, tag)
 	if err != nil {
-		b.Logger.Criticalf("error building base image: %s", err)
+		b.Logger.Criticalf("error building final image: %s", err)
 
 		return err
 	}
 
-	b.Logger.Debug("base image building complete!")
+	b.Logger.Debug("packaging complete!")
 
-	err = b.runInstallImage(i, repo, tag)
-	if err != nil {
-		b.Logger.Criticalf("error running instance installation: %s", err)
+	return nil
+}
 
+// packageBuildPrepare validates the packaging inputs and resolves the container engine.
+func (b *Boxen) packageBuildPrepare(credentials *config.Credentials, push *PushConfig) error {
+	err := validatePackageCredentials(credentials)
+	if err != nil {
 		return err
 	}
 
-	b.Logger.Debug("instance installation complete!")
+	if push != nil {
+		// tags are only expanded after the build, but no point building if they are invalid
+		_, err = expandPushTags(push.Tags, "0")
+		if err != nil {
+			return err
+		}
+	}
 
-	err = b.buildFinalImage(i, repo, tag)
+	b.engine, err = docker.NewEngine(b.containerEngine)
 	if err != nil {
-		b.Logger.Criticalf("error building final image: %s", err)
-
 		return err
 	}
 
-	_ = srv.Shutdown(context.TODO())
-
-	wg.Wait()
-
-	b.Logger.Debug("packaging complete!")
+	b.Logger.Debugf("using container engine '%s'", b.engine.Name())
 
 	return nil
 }
@@ -488,24 +758,44 @@ func (b *Boxen) packageBuildContainer(i *installInfo, repo, tag string) error {
 // initial prompts and installing a base config and such) is complete, the disk image is copied out
 // of the install container, the install container is then destroyed, with the initial build image.
 // Finally, this function will build a final container image, copying in the provisioned disk into
-// the final image.
+// the final image. Credential sources are kept as references in the packaged config, so the same
+// env var or secret file must be provided to the packaged container at runtime. If push is not nil
+// the final image is pushed to its registry, and the PushResult is returned.
 func (b *Boxen) PackageBuild(
-	disk, username, password, repo, tag, vendor, platform, version string,
-) error {
+	disk string,
+	credentials *config.Credentials,
+	repo, tag, vendor, platform, version string,
+	push *PushConfig,
+) (*PushResult, error) {
 	b.Logger.Infof("package requested for disk '%s'", disk)
 
-	i := &installInfo{inDisk: disk, username: username, password: password}
+	err := b.packageBuildPrepare(credentials, push)
+	if err != nil {
+		return nil, err
+	}
+
+	i := &installInfo{inDisk: disk, credentials: credentials}
 
-	err := b.handleProvidedPlatformInfo(i, vendor, platform, version)
+	err = b.handleProvidedPlatformInfo(i, vendor, platform, version)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	err = b.packageBuildPreContainer(i)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	return b.packageBuildFinish(i, repo, tag, push)
+}
+
+// packageBuildFinish builds (and pushes, if push is not nil) the container image(s) once all the
+// packaging files are bundled in the temporary directory.
+func (b *Boxen) packageBuildFinish(
+	i *installInfo,
+	repo, tag string,
+	push *PushConfig,
+) (*PushResult, error) {
 	if repo == "" {
 		repo = fmt.Sprintf("boxen_%s", i.srcDisk.PlatformType)
 	} else {
@@ -516,5 +806,14 @@ func (b *Boxen) PackageBuild(
 		tag = i.srcDisk.Version
 	}
 
-	return b.packageBuildContainer(i, repo, tag)
+	err := b.packageBuildContainer(i, repo, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	if push == nil {
+		return nil, nil
+	}
+
+	return b.packagePush(repo, tag, push)
 }
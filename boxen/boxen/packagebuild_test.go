@@ -0,0 +1,56 @@
+package boxen_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/carlmontanari/boxen/boxen/boxen"
+	"github.com/carlmontanari/boxen/boxen/config"
+	"github.com/carlmontanari/boxen/boxen/logging"
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+func TestPackageBuildRejectsLiteralPassword(t *testing.T) {
+	tests := []struct {
+		desc        string
+		credentials *config.Credentials
+	}{
+		{
+			desc:        "literal password",
+			credentials: &config.Credentials{Password: "s3cr3t"},
+		},
+		{
+			desc: "literal password with username source",
+			credentials: &config.Credentials{
+				UsernameFrom: &config.CredentialSource{Env: "VEOS_USERNAME"},
+				Password:     "s3cr3t",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			l, err := logging.NewInstance(func(...interface{}) {})
+			if err != nil {
+				t.Fatalf("failed creating logger: %s", err)
+			}
+
+			b := &boxen.Boxen{Config: config.NewConfig(), Logger: l}
+
+			// the password is rejected before the disk is even looked at
+			_, err = b.PackageBuild(
+				"missing.vmdk",
+				tt.credentials,
+				"",
+				"",
+				"arista",
+				"veos",
+				"4.22.1F",
+				nil,
+			)
+			if !errors.Is(err, util.ErrValidationError) {
+				t.Fatalf("%s: expected validation error, got: %v", tt.desc, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package boxen
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/carlmontanari/boxen/boxen/config"
+	"github.com/carlmontanari/boxen/boxen/platforms"
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+// parsePackageSource parses a "<platform type>/<version>" source disk reference.
+func parsePackageSource(source string) (pT, version string, err error) {
+	parts := strings.SplitN(source, "/", 2) //nolint:gomnd
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf(
+			"%w: source '%s' is not of the form '<platform type>/<version>', ex: "+
+				"'arista_veos/4.22.1F'",
+			util.ErrValidationError,
+			source,
+		)
+	}
+
+	return parts[0], parts[1], nil
+}
+
+// sourceDiskCredentials returns the credentials the source disk of platform type pT and version was
+// installed with, as recorded at install time, with any provided credentials applied on top. As
+// literal passwords are never recorded, the credentials must be provided if the disk was installed
+// with one, or if the disk was installed before credentials were recorded at all.
+func (b *Boxen) sourceDiskCredentials(
+	pT, version string,
+	credentials *config.Credentials,
+) (*config.Credentials, error) {
+	installed := b.Config.Platforms[pT].SourceCredentials[version]
+
+	if installed == nil && credentials == nil {
+		return nil, fmt.Errorf(
+			"%w: no credentials recorded for source disk '%s/%s', provide the credentials it "+
+				"was installed with",
+			util.ErrValidationError,
+			pT,
+			version,
+		)
+	}
+
+	c := &config.Credentials{}
+	c.Merge(installed)
+	c.Merge(credentials)
+
+	if (c.Username == "" && c.UsernameFrom == nil) || (c.Password == "" && c.PasswordFrom == nil) {
+		return nil, fmt.Errorf(
+			"%w: source disk '%s/%s' was installed with a literal password, which is not "+
+				"recorded, provide the password it was installed with via an env or file "+
+				"credential source",
+			util.ErrValidationError,
+			pT,
+			version,
+		)
+	}
+
+	return c, nil
+}
+
+// packageFromSourcePreContainer bundles the installed source disk and its run files in the
+// temporary directory, this is the "from source" equivalent of packageBuildPreContainer.
+func (b *Boxen) packageFromSourcePreContainer(i *installInfo) error {
+	var err error
+
+	i.tmpDir, err = ioutil.TempDir(os.TempDir(), "boxen")
+	if err != nil {
+		b.Logger.Criticalf("error creating temporary working directory: %s\n", err)
+
+		return err
+	}
+
+	b.Logger.Debugf("temporary directory '%s' created successfully", i.tmpDir)
+
+	i.name = fmt.Sprintf("%s_%s", i.srcDisk.PlatformType, i.srcDisk.Version)
+	i.newDisk = fmt.Sprintf("%s/%s.qcow2", i.tmpDir, i.name)
+
+	err = linkOrCopyFile(i.srcDisk.Disk, i.newDisk)
+	if err != nil {
+		b.Logger.Criticalf("error copying source disk for packaging: %s", err)
+
+		return err
+	}
+
+	runFiles, err := b.sourceRunFiles(i.srcDisk.PlatformType, i.srcDisk.Version)
+	if err != nil {
+		b.Logger.Criticalf("error listing source disk run files: %s", err)
+
+		return err
+	}
+
+	packageFiles := make([]string, len(runFiles))
+
+	for idx, f := range runFiles {
+		packageFiles[idx] = filepath.Base(f)
+
+		err = util.CopyFile(f, fmt.Sprintf("%s/%s", i.tmpDir, packageFiles[idx]))
+		if err != nil {
+			b.Logger.Criticalf("error copying run file '%s' for packaging: %s", f, err)
+
+			return err
+		}
+	}
+
+	b.Logger.Debug("source disk and run files copied for packaging")
+
+	err = b.packageBundle(i, packageFiles)
+	if err != nil {
+		b.Logger.Criticalf("error bundling required packaging files: %s", err)
+
+		return err
+	}
+
+	b.Logger.Debug("bundling required packaging files complete")
+
+	return nil
+}
+
+// PackageBuildFromSource packages an already installed local source disk (see Install) as a
+// container image. The source is provided as "<platform type>/<version>", i.e.
+// "arista_veos/4.22.1F". As the disk is already installed, the install container is skipped
+// entirely -- the disk and its run files are copied straight into the final image. The credentials
+// the source disk was installed with are reused, any provided credentials override them.
+func (b *Boxen) PackageBuildFromSource(
+	source string,
+	credentials *config.Credentials,
+	repo, tag string,
+	push *PushConfig,
+) (*PushResult, error) {
+	b.Logger.Infof("package requested for source disk '%s'", source)
+
+	if b.Config == nil {
+		return nil, fmt.Errorf(
+			"%w: packaging from a source disk requires a boxen config",
+			util.ErrValidationError,
+		)
+	}
+
+	pT, version, err := parsePackageSource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	vendor, platform := platforms.GetVendorPlatform(pT)
+	if vendor == "" {
+		return nil, fmt.Errorf("%w: platform type '%s' not supported", util.ErrValidationError, pT)
+	}
+
+	if !b.sourceDiskExists(pT, version) {
+		return nil, fmt.Errorf(
+			"%w: no source disk for platform type '%s' of version '%s', install it first",
+			util.ErrInspectionError,
+			pT,
+			version,
+		)
+	}
+
+	credentials, err = b.sourceDiskCredentials(pT, version, credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	err = platforms.ValidateSSHKeys(pT, len(credentials.SSHKeys))
+	if err != nil {
+		return nil, err
+	}
+
+	err = b.packageBuildPrepare(credentials, push)
+	if err != nil {
+		return nil, err
+	}
+
+	i := &installInfo{
+		inDisk: source,
+		srcDisk: &Disk{
+			Disk: fmt.Sprintf(
+				"%s/%s/%s/disk.qcow2",
+				b.Config.Options.Build.SourcePath,
+				pT,
+				version,
+			),
+			Vendor:       vendor,
+			Platform:     platform,
+			PlatformType: pT,
+			Version:      version,
+		},
+		credentials: credentials,
+		installed:   true,
+	}
+
+	err = b.packageFromSourcePreContainer(i)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.packageBuildFinish(i, repo, tag, push)
+}
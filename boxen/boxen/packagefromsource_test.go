@@ -0,0 +1,124 @@
+package boxen_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/carlmontanari/boxen/boxen/boxen"
+	"github.com/carlmontanari/boxen/boxen/config"
+	"github.com/carlmontanari/boxen/boxen/logging"
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+func TestPackageBuildFromSourceCredentials(t *testing.T) {
+	tests := []struct {
+		desc        string
+		installed   *config.Credentials
+		credentials *config.Credentials
+	}{
+		{
+			desc:        "no credentials recorded or provided",
+			installed:   nil,
+			credentials: nil,
+		},
+		{
+			desc: "literal password not recorded",
+			installed: (&config.Credentials{
+				Username: "admin",
+				Password: "s3cr3t",
+			}).References(),
+			credentials: nil,
+		},
+		{
+			desc: "recorded source not supported for packaging",
+			installed: (&config.Credentials{
+				Username:     "admin",
+				PasswordFrom: &config.CredentialSource{Command: "pass show lab/veos"},
+			}).References(),
+			credentials: nil,
+		},
+		{
+			desc: "provided literal password",
+			installed: (&config.Credentials{
+				Username: "admin",
+				Password: "s3cr3t",
+			}).References(),
+			credentials: &config.Credentials{Password: "s3cr3t"},
+		},
+		{
+			desc:      "provided source not supported for packaging",
+			installed: config.NewDefaultCredentials().References(),
+			credentials: &config.Credentials{
+				PasswordFrom: &config.CredentialSource{File: "relative/secret"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			l, err := logging.NewInstance(func(...interface{}) {})
+			if err != nil {
+				t.Fatalf("failed creating logger: %s", err)
+			}
+
+			c := config.NewConfig()
+			c.Platforms["arista_veos"] = config.NewPlatform()
+			c.Platforms["arista_veos"].SourceDisks = []string{"4.22.1F"}
+
+			if tt.installed != nil {
+				c.Platforms["arista_veos"].SourceCredentials = map[string]*config.Credentials{
+					"4.22.1F": tt.installed,
+				}
+			}
+
+			b := &boxen.Boxen{Config: c, Logger: l}
+
+			_, err = b.PackageBuildFromSource("arista_veos/4.22.1F", tt.credentials, "", "", nil)
+			if !errors.Is(err, util.ErrValidationError) {
+				t.Fatalf("%s: expected validation error, got: %v", tt.desc, err)
+			}
+		})
+	}
+}
+
+func TestCredentialsReferences(t *testing.T) {
+	tests := []struct {
+		desc         string
+		credentials  *config.Credentials
+		wantPassword string
+	}{
+		{
+			desc:         "default password is kept",
+			credentials:  config.NewDefaultCredentials(),
+			wantPassword: config.NewDefaultCredentials().Password,
+		},
+		{
+			desc:         "literal password is dropped",
+			credentials:  &config.Credentials{Username: "admin", Password: "s3cr3t"},
+			wantPassword: "",
+		},
+		{
+			desc: "password source is kept",
+			credentials: &config.Credentials{
+				Username:     "admin",
+				PasswordFrom: &config.CredentialSource{Env: "VEOS_PASSWORD"},
+			},
+			wantPassword: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := tt.credentials.References()
+
+			if got.Password != tt.wantPassword {
+				t.Fatalf("%s: got password %q, want %q", tt.desc, got.Password, tt.wantPassword)
+			}
+
+			if got.Username != tt.credentials.Username ||
+				got.PasswordFrom != tt.credentials.PasswordFrom {
+				t.Fatalf("%s: username or password source not kept: %+v", tt.desc, got)
+			}
+		})
+	}
+}
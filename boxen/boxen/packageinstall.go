@@ -4,6 +4,7 @@ import (
 	"os"
 
 	"github.com/carlmontanari/boxen/boxen/command"
+	"github.com/carlmontanari/boxen/boxen/config"
 	"github.com/carlmontanari/boxen/boxen/instance"
 	"github.com/carlmontanari/boxen/boxen/platforms"
 	"github.com/carlmontanari/boxen/boxen/util"
@@ -22,6 +23,25 @@ func (b *Boxen) getPackagedInstanceName() string {
 	return name
 }
 
+// packageConsole serves the serial (and vnc) consoles of the packaged instance on all addresses
+// unless configured otherwise -- the container is the boundary for packaged instances, and the
+// consoles have to be reachable through the container ports.
+func (b *Boxen) packageConsole() {
+	if b.Config.Options.Console == nil {
+		b.Config.Options.Console = &config.Console{}
+	}
+
+	if b.Config.Options.Console.ListenAddress == "" {
+		b.Config.Options.Console.ListenAddress = config.PackageConsoleListenAddress
+	}
+
+	for _, i := range b.Config.Instances {
+		if i.Advanced.VNCEnabled() && i.Advanced.VNC.ListenAddress == "" {
+			i.Advanced.VNC.ListenAddress = config.PackageConsoleListenAddress
+		}
+	}
+}
+
 func (b *Boxen) shrinkDisk(name string) error {
 	err := os.Rename(b.Config.Instances[name].Disk, "fat.qcow2")
 	if err != nil {
@@ -57,6 +77,8 @@ func (b *Boxen) PackageInstall() error {
 
 	name := b.getPackagedInstanceName()
 
+	b.packageConsole()
+
 	// for things *not* packaging we will want to merge the instance config w/ the profile and/or
 	// defaults, that is not necessary for packaging since we just load up the config all on the
 	// instance though!
@@ -0,0 +1,176 @@
+package boxen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/carlmontanari/boxen/boxen/docker"
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+const (
+	// PushTagVersion is replaced with the packaged image tag (the disk version by default) in push
+	// tags.
+	PushTagVersion = "{version}"
+	// PushTagBoxenVersion is replaced with the boxen version in push tags.
+	PushTagBoxenVersion = "{boxen_version}"
+
+	pushRetryInterval = 5
+)
+
+// pushTagPattern matches valid image tags, as defined by the docker reference grammar.
+var pushTagPattern = regexp.MustCompile(`^[\w][\w.-]{0,127}$`) //nolint:gochecknoglobals
+
+// DefaultPushTags returns the tags images are pushed as if no tags are provided -- the version, the
+// version suffixed with the boxen version that packaged it, and latest.
+func DefaultPushTags() []string {
+	return []string{
+		PushTagVersion,
+		fmt.Sprintf("%s-boxen%s", PushTagVersion, PushTagBoxenVersion),
+		"latest",
+	}
+}
+
+// PushConfig holds the settings for pushing a packaged image to a registry. The registry is taken
+// from the image repository, i.e. "localhost:5000/boxen_arista_veos".
+type PushConfig struct {
+	// Tags are the tags to push the image as, PushTagVersion and PushTagBoxenVersion are replaced
+	// with the image and boxen version respectively.
+	Tags []string
+	// Retries is the number of times to retry a failed push of each tag.
+	Retries int
+}
+
+// PushResult is the result of pushing a packaged image.
+type PushResult struct {
+	Repository string   `json:"repository"`
+	Tags       []string `json:"tags"`
+	Digest     string   `json:"digest"`
+	// Reference is the digest reference of the pushed image, i.e. "repo@sha256:abc...".
+	Reference string `json:"reference"`
+}
+
+// expandPushTags replaces the placeholders in the push tags, dropping any duplicates.
+func expandPushTags(tags []string, tag string) ([]string, error) {
+	r := strings.NewReplacer(PushTagVersion, tag, PushTagBoxenVersion, Version)
+
+	var expanded []string
+
+	seen := map[string]bool{}
+
+	for _, t := range tags {
+		t = r.Replace(t)
+
+		if !pushTagPattern.MatchString(t) {
+			return nil, fmt.Errorf("%w: invalid push tag '%s'", util.ErrValidationError, t)
+		}
+
+		if seen[t] {
+			continue
+		}
+
+		seen[t] = true
+
+		expanded = append(expanded, t)
+	}
+
+	return expanded, nil
+}
+
+func (b *Boxen) pushTagWithRetry(repo, tag string, retries int) (string, error) {
+	var digest string
+
+	var err error
+
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			wait := time.Duration(attempt*pushRetryInterval) * time.Second
+
+			b.Logger.Infof(
+				"push of '%s:%s' failed, retrying in %s (%d/%d): %s",
+				repo,
+				tag,
+				wait,
+				attempt,
+				retries,
+				err,
+			)
+
+			time.Sleep(wait)
+		}
+
+		digest, err = docker.Push(
+			docker.WithEngine(b.engine),
+			docker.WithRepo(repo),
+			docker.WithTag(tag),
+		)
+		if err == nil {
+			return digest, nil
+		}
+	}
+
+	return "", err
+}
+
+// packagePush tags the packaged image repo:tag with each of the push tags and pushes them.
+func (b *Boxen) packagePush(repo, tag string, push *PushConfig) (*PushResult, error) {
+	tags := push.Tags
+	if len(tags) == 0 {
+		tags = DefaultPushTags()
+	}
+
+	tags, err := expandPushTags(tags, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &PushResult{Repository: repo}
+
+	for _, t := range tags {
+		if t != tag {
+			err = docker.Tag(
+				repo,
+				t,
+				docker.WithEngine(b.engine),
+				docker.WithRepo(repo),
+				docker.WithTag(tag),
+			)
+			if err != nil {
+				b.Logger.Criticalf("error tagging image '%s:%s' as '%s': %s", repo, tag, t, err)
+
+				return nil, err
+			}
+		}
+
+		b.Logger.Infof("pushing image '%s:%s'", repo, t)
+
+		digest, err := b.pushTagWithRetry(repo, t, push.Retries)
+		if err != nil {
+			b.Logger.Criticalf("error pushing image '%s:%s': %s", repo, t, err)
+
+			return nil, err
+		}
+
+		// all tags are the same image, so the registry should always hand back the same digest
+		if res.Digest != "" && res.Digest != digest {
+			return nil, fmt.Errorf(
+				"%w: pushed tag '%s' has digest '%s', expected '%s'",
+				util.ErrInspectionError,
+				t,
+				digest,
+				res.Digest,
+			)
+		}
+
+		res.Digest = digest
+		res.Tags = append(res.Tags, t)
+	}
+
+	res.Reference = fmt.Sprintf("%s@%s", repo, res.Digest)
+
+	b.Logger.Infof("push complete, image digest '%s'", res.Digest)
+
+	return res, nil
+}
@@ -0,0 +1,115 @@
+package boxen
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/carlmontanari/boxen/boxen/util"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExpandPushTags(t *testing.T) {
+	tests := []struct {
+		desc    string
+		tags    []string
+		tag     string
+		want    []string
+		wantErr bool
+	}{
+		{
+			desc: "default tags",
+			tags: DefaultPushTags(),
+			tag:  "4.22.1F",
+			want: []string{"4.22.1F", "4.22.1F-boxen" + Version, "latest"},
+		},
+		{
+			desc: "placeholders anywhere in the tag",
+			tags: []string{"veos-{version}", "{version}_{boxen_version}", "stable"},
+			tag:  "4.22.1F",
+			want: []string{"veos-4.22.1F", "4.22.1F_" + Version, "stable"},
+		},
+		{
+			desc: "duplicates dropped in order",
+			tags: []string{"latest", "{version}", "4.22.1F", "latest"},
+			tag:  "4.22.1F",
+			want: []string{"latest", "4.22.1F"},
+		},
+		{
+			desc: "no tags",
+			tags: nil,
+			tag:  "4.22.1F",
+			want: nil,
+		},
+		{
+			desc:    "empty tag",
+			tags:    []string{""},
+			tag:     "4.22.1F",
+			wantErr: true,
+		},
+		{
+			desc:    "empty version",
+			tags:    []string{"{version}"},
+			tag:     "",
+			wantErr: true,
+		},
+		{
+			desc:    "repository in tag",
+			tags:    []string{"boxen_arista_veos:latest"},
+			tag:     "4.22.1F",
+			wantErr: true,
+		},
+		{
+			desc:    "registry path in tag",
+			tags:    []string{"lab/latest"},
+			tag:     "4.22.1F",
+			wantErr: true,
+		},
+		{
+			desc:    "digest in tag",
+			tags:    []string{"latest@sha256"},
+			tag:     "4.22.1F",
+			wantErr: true,
+		},
+		{
+			desc:    "leading separator",
+			tags:    []string{"-latest"},
+			tag:     "4.22.1F",
+			wantErr: true,
+		},
+		{
+			desc:    "invalid character in version",
+			tags:    []string{"{version}"},
+			tag:     "4.22.1F+build",
+			wantErr: true,
+		},
+		{
+			desc:    "too long",
+			tags:    []string{strings.Repeat("v", 129)},
+			tag:     "4.22.1F",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := expandPushTags(tt.tags, tt.tag)
+
+			if tt.wantErr {
+				if !errors.Is(err, util.ErrValidationError) {
+					t.Fatalf("%s: expected validation error, got: %v", tt.desc, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("%s: failed expanding tags: %s", tt.desc, err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("%s: tags do not match (-want +got):\n%s", tt.desc, diff)
+			}
+		})
+	}
+}
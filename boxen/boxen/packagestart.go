@@ -6,14 +6,13 @@ import (
 	"time"
 
 	"github.com/carlmontanari/boxen/boxen/command"
+	"github.com/carlmontanari/boxen/boxen/config"
 	"github.com/carlmontanari/boxen/boxen/instance"
 	"github.com/carlmontanari/boxen/boxen/platforms"
 	"github.com/carlmontanari/boxen/boxen/util"
 )
 
-func (b *Boxen) clabNicProvisionDelay() error {
-	clabIntfs := util.GetEnvIntOrDefault("CLAB_INTFS", 0)
-
+func (b *Boxen) clabNicProvisionDelay(clabIntfs int) error {
 	if clabIntfs == 0 {
 		return nil
 	}
@@ -75,12 +74,7 @@ func (b *Boxen) packageSocat(name string) error {
 	return nil
 }
 
-func (b *Boxen) clabStartDelay() {
-	startDelay := util.GetEnvIntOrDefault(
-		"BOOT_DELAY",
-		0,
-	)
-
+func (b *Boxen) clabStartDelay(startDelay int) {
 	if startDelay != 0 {
 		b.Logger.Infof("start delay set, sleeping for %d seconds...", startDelay)
 		time.Sleep(time.Duration(startDelay) * time.Second)
@@ -89,34 +83,71 @@ func (b *Boxen) clabStartDelay() {
 	}
 }
 
-func (b *Boxen) packageStartConfig(name, username, password, hostname, config string) error {
-	startupConfig := util.GetEnvStrOrDefault(
-		"STARTUP_CONFIG",
-		"",
-	)
+// packageStartSSHKeys sets the ssh keys from creds on the instance -- if no username was provided
+// the keys are added for the user the instance was packaged with.
+func (b *Boxen) packageStartSSHKeys(name string, creds *config.Credentials) error {
+	keys, err := util.ParseSSHPublicKeys(creds.SSHKeys)
+	if err != nil {
+		return err
+	}
 
-	saveRequired := false
+	usr := creds.Username
+
+	if usr == "" {
+		instanceCreds := b.Config.Instances[name].Credentials
+		if instanceCreds == nil {
+			instanceCreds = b.Config.Options.Credentials
+		}
 
-	f := startupConfig
-	if config != "" {
-		f = config
+		resolvedCreds, resolveErr := instanceCreds.Resolve()
+		if resolveErr != nil {
+			return resolveErr
+		}
+
+		usr = resolvedCreds.Username
 	}
 
-	if f != "" { //nolint:nestif
-		err := b.Instances[name].InstallConfig(f, true)
+	return b.Instances[name].SetSSHKeys(usr, keys)
+}
+
+func (b *Boxen) packageStartConfig(
+	name string,
+	credentials *config.Credentials,
+	hostname, startupConfig string,
+) error {
+	saveRequired := false
+
+	if startupConfig != "" { //nolint:nestif
+		err := b.Instances[name].InstallConfig(startupConfig, true)
 		if err != nil {
 			return err
 		}
 
 		saveRequired = true
 	} else {
-		if username != "" && password != "" {
-			err := b.Instances[name].SetUserPass(username, password)
+		if credentials != nil {
+			creds, err := credentials.Resolve()
 			if err != nil {
 				return err
 			}
 
-			saveRequired = true
+			if creds.Username != "" && creds.Password != "" {
+				err = b.Instances[name].SetUserPass(creds.Username, creds.Password)
+				if err != nil {
+					return err
+				}
+
+				saveRequired = true
+			}
+
+			if len(creds.SSHKeys) > 0 {
+				err = b.packageStartSSHKeys(name, creds)
+				if err != nil {
+					return err
+				}
+
+				saveRequired = true
+			}
 		}
 
 		if hostname != "" {
@@ -139,12 +170,185 @@ func (b *Boxen) packageStartConfig(name, username, password, hostname, config st
 	return nil
 }
 
-// PackageStart starts the qemu instance vm inside a packaged boxen container.
-func (b *Boxen) PackageStart(username, password, hostname, config string) error {
+// instanceVCPUs returns the vcpu count of the instance with cores cores.
+func instanceVCPUs(i *config.Instance, cores int) int {
+	threads, sockets := 1, 1
+
+	if i.Advanced != nil && i.Advanced.CPU != nil {
+		if i.Advanced.CPU.Threads > 0 {
+			threads = i.Advanced.CPU.Threads
+		}
+
+		if i.Advanced.CPU.Sockets > 0 {
+			sockets = i.Advanced.CPU.Sockets
+		}
+	}
+
+	return cores * threads * sockets
+}
+
+// validatePackageStartHardware checks the runtime hardware overrides against the limits of the
+// platform (from the platform default profile) and the containerlab interface count.
+func validatePackageStartHardware(
+	i *config.Instance,
+	hw *PackageStartHardware,
+	clabIntfs int,
+) error {
+	if hw.NicCount != 0 && hw.NicCount < clabIntfs {
+		return fmt.Errorf(
+			"%w: nic count %d is less than the %d containerlab interfaces",
+			util.ErrValidationError,
+			hw.NicCount,
+			clabIntfs,
+		)
+	}
+
+	p, err := GetDefaultProfile(i.PlatformType)
+	if err != nil {
+		return err
+	}
+
+	if p.Limits == nil {
+		return nil
+	}
+
+	if hw.Memory != 0 && hw.Memory < p.Limits.MinMemory {
+		return fmt.Errorf(
+			"%w: memory %d is less than the platform type '%s' minimum of %d",
+			util.ErrValidationError,
+			hw.Memory,
+			i.PlatformType,
+			p.Limits.MinMemory,
+		)
+	}
+
+	if hw.CPUCores != 0 && instanceVCPUs(i, hw.CPUCores) < p.Limits.MinVCPUs {
+		return fmt.Errorf(
+			"%w: %d cpu cores (%d vcpus) is less than the platform type '%s' minimum of %d vcpus",
+			util.ErrValidationError,
+			hw.CPUCores,
+			instanceVCPUs(i, hw.CPUCores),
+			i.PlatformType,
+			p.Limits.MinVCPUs,
+		)
+	}
+
+	return nil
+}
+
+// packageStartHardware validates and applies the runtime hardware overrides to the packaged
+// instance. This happens before the platform is created from the config, so the launch command
+// (including the pci bridges for the nics) is built for the overridden hardware.
+func (b *Boxen) packageStartHardware(name string, opts *PackageStartOptions) error {
+	i := b.Config.Instances[name]
+	hw := &opts.Hardware
+
+	err := validatePackageStartHardware(i, hw, opts.ClabIntfs)
+	if err != nil {
+		return err
+	}
+
+	if hw.Memory != 0 {
+		b.Logger.Infof("overriding instance memory to %d", hw.Memory)
+
+		i.Hardware.Memory = hw.Memory
+	}
+
+	if hw.NicCount != 0 {
+		b.Logger.Infof("overriding instance nic count to %d", hw.NicCount)
+
+		i.Hardware.NicCount = hw.NicCount
+	}
+
+	if hw.CPUCores != 0 {
+		b.Logger.Infof("overriding instance cpu cores to %d", hw.CPUCores)
+
+		if i.Advanced == nil {
+			i.Advanced = &config.Advanced{}
+		}
+
+		if i.Advanced.CPU == nil {
+			i.Advanced.CPU = &config.AdvancedCPU{}
+		}
+
+		i.Advanced.CPU.Cores = hw.CPUCores
+	}
+
+	return nil
+}
+
+// packageStartVNC enables the vnc console of the packaged instance if requested by the runtime
+// options, there is only ever one instance so it always gets the first vnc port.
+func (b *Boxen) packageStartVNC(name string, opts *PackageStartOptions) {
+	i := b.Config.Instances[name]
+
+	switch {
+	case opts.VNC == nil:
+	case *opts.VNC && !i.Advanced.VNCEnabled():
+		if i.Advanced == nil {
+			i.Advanced = &config.Advanced{}
+		}
+
+		i.Advanced.VNC = &config.VNC{}
+	case !*opts.VNC && i.Advanced != nil:
+		i.Advanced.VNC = nil
+	}
+
+	if !i.Advanced.VNCEnabled() {
+		return
+	}
+
+	if opts.VNCPassword != "" {
+		i.Advanced.VNC.Password = opts.VNCPassword
+		i.Advanced.VNC.PasswordFrom = nil
+	}
+
+	i.Hardware.VNCPort = config.VNCPORTLOW
+}
+
+// PackageStart starts the qemu instance vm inside a packaged boxen container, opts are the
+// runtime options (see ResolvePackageStartOptions).
+func (b *Boxen) PackageStart(
+	credentials *config.Credentials,
+	hostname string,
+	opts *PackageStartOptions,
+) error {
 	b.Logger.Info("package start requested")
 
+	if opts == nil {
+		opts = DefaultPackageStartOptions()
+	}
+
 	name := b.getPackagedInstanceName()
 
+	// fail before booting the instance if it can't take the requested ssh keys
+	if credentials != nil {
+		err := platforms.ValidateSSHKeys(
+			b.Config.Instances[name].PlatformType,
+			len(credentials.SSHKeys),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	b.packageStartVNC(name, opts)
+	b.packageConsole()
+
+	if opts.TCGFallback != nil {
+		b.Config.Options.Qemu.TCGFallback = *opts.TCGFallback
+	}
+
+	err := b.packageStartHardware(name, opts)
+	if err != nil {
+		return err
+	}
+
+	err = b.packageStartIntfs(name, opts)
+	if err != nil {
+		return err
+	}
+
 	instanceLoggers, err := instance.NewInstanceLoggersFOut(b.Logger, "/")
 	if err != nil {
 		return err
@@ -159,9 +363,16 @@ func (b *Boxen) PackageStart(username, password, hostname, config string) error
 		return err
 	}
 
-	err = b.clabNicProvisionDelay()
-	if err != nil {
-		return err
+	// liveness checks pass while waiting for interfaces and booting, readiness once running
+	q.StartHealthServer()
+
+	if opts.IntfDiscovery == IntfDiscoveryClab {
+		err = b.clabNicProvisionDelay(opts.ClabIntfs)
+		if err != nil {
+			return err
+		}
+	} else {
+		b.waitIntfs(b.Config.Instances[name].DataPlaneIntf.HostIntfs)
 	}
 
 	err = b.packageSocat(name)
@@ -169,7 +380,7 @@ func (b *Boxen) PackageStart(username, password, hostname, config string) error
 		return err
 	}
 
-	b.clabStartDelay()
+	b.clabStartDelay(opts.BootDelay)
 
 	b.Instances[name] = q
 
@@ -182,7 +393,7 @@ func (b *Boxen) PackageStart(username, password, hostname, config string) error
 		return err
 	}
 
-	err = b.packageStartConfig(name, username, password, hostname, config)
+	err = b.packageStartConfig(name, credentials, hostname, opts.StartupConfig)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,103 @@
+package boxen
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/carlmontanari/boxen/boxen/config"
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+func TestValidatePackageStartHardware(t *testing.T) {
+	tests := []struct {
+		desc         string
+		platformType string
+		cpu          *config.AdvancedCPU
+		hw           PackageStartHardware
+		clabIntfs    int
+		wantErr      bool
+	}{
+		{
+			desc:         "no overrides",
+			platformType: "arista_veos",
+			clabIntfs:    4,
+		},
+		{
+			desc:         "25 nics for 26 containerlab interfaces",
+			platformType: "arista_veos",
+			hw:           PackageStartHardware{NicCount: 25},
+			clabIntfs:    26,
+			wantErr:      true,
+		},
+		{
+			desc:         "26 nics for 26 containerlab interfaces",
+			platformType: "arista_veos",
+			hw:           PackageStartHardware{NicCount: 26},
+			clabIntfs:    26,
+		},
+		{
+			desc:         "27 nics for 26 containerlab interfaces",
+			platformType: "arista_veos",
+			hw:           PackageStartHardware{NicCount: 27},
+			clabIntfs:    26,
+		},
+		{
+			desc:         "memory at the platform minimum",
+			platformType: "arista_veos",
+			hw:           PackageStartHardware{Memory: 2048},
+		},
+		{
+			desc:         "memory below the platform minimum",
+			platformType: "arista_veos",
+			hw:           PackageStartHardware{Memory: 2047},
+			wantErr:      true,
+		},
+		{
+			desc:         "cpu cores at the platform minimum",
+			platformType: "juniper_vsrx",
+			hw:           PackageStartHardware{CPUCores: 2},
+		},
+		{
+			desc:         "cpu cores below the platform minimum",
+			platformType: "juniper_vsrx",
+			hw:           PackageStartHardware{CPUCores: 1},
+			wantErr:      true,
+		},
+		{
+			desc:         "threads count towards the vcpus",
+			platformType: "juniper_vsrx",
+			cpu:          &config.AdvancedCPU{Threads: 2},
+			hw:           PackageStartHardware{CPUCores: 1},
+		},
+		{
+			desc:         "sockets count towards the vcpus",
+			platformType: "cisco_n9kv",
+			cpu:          &config.AdvancedCPU{Sockets: 2},
+			hw:           PackageStartHardware{CPUCores: 1},
+		},
+		{
+			desc:         "platform without a vcpu minimum",
+			platformType: "arista_veos",
+			hw:           PackageStartHardware{CPUCores: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			i := &config.Instance{
+				PlatformType: tt.platformType,
+				Advanced:     &config.Advanced{CPU: tt.cpu},
+			}
+
+			err := validatePackageStartHardware(i, &tt.hw, tt.clabIntfs)
+
+			if tt.wantErr && !errors.Is(err, util.ErrValidationError) {
+				t.Fatalf("%s: expected validation error, got: %v", tt.desc, err)
+			}
+
+			if !tt.wantErr && err != nil {
+				t.Fatalf("%s: expected no error, got: %s", tt.desc, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package boxen
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/carlmontanari/boxen/boxen/config"
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+const (
+	// IntfDiscoveryClab taps data plane nic n to the container interface "eth<n>", as containerlab
+	// attaches them.
+	IntfDiscoveryClab = "clab"
+	// IntfDiscoveryMap taps data plane nics to the interfaces in the runtime options intf_map.
+	IntfDiscoveryMap = "map"
+	// IntfDiscoveryAnnotations taps data plane nics to the interfaces from the pod annotations, i.e.
+	// multus attached interfaces in a kubernetes pod.
+	IntfDiscoveryAnnotations = "annotations"
+
+	// DefaultPodAnnotationsFile is where the pod annotations are read from in the annotations
+	// interface discovery mode, mount the pod annotations here with a downward api volume.
+	DefaultPodAnnotationsFile = "/etc/podinfo/annotations"
+	// PodIntfMapAnnotation is the pod annotation holding an explicit interface mapping of the form
+	// "net1=1,net2=2", if not set the multus network status annotation is used.
+	PodIntfMapAnnotation = ImageLabelPrefix + "intf-map"
+
+	multusNetworkStatusAnnotation = "k8s.v1.cni.cncf.io/network-status"
+
+	// tapHostIntfDir holds a file per tap interface holding the host interface it is bridged to, this
+	// is read by the tc-tap-ifup script.
+	tapHostIntfDir = "/run/boxen"
+
+	intfWaitInterval = 5
+)
+
+// multusNetworkStatus is an entry of the multus network status annotation.
+type multusNetworkStatus struct {
+	Name      string `json:"name"`
+	Interface string `json:"interface"`
+	Default   bool   `json:"default"`
+}
+
+// ParseIntfMap parses an interface mapping of the form "net1=1,net2=2" (host interface name to data
+// plane nic id).
+func ParseIntfMap(s string) (map[string]int, error) {
+	m := map[string]int{}
+
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		kv := strings.SplitN(pair, "=", 2) //nolint:gomnd
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf(
+				"%w: interface mapping '%s' is not of the form 'interface=nic id'",
+				util.ErrValidationError,
+				pair,
+			)
+		}
+
+		nicID, err := strconv.Atoi(kv[1])
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: interface mapping '%s' nic id is not an integer",
+				util.ErrValidationError,
+				pair,
+			)
+		}
+
+		if _, ok := m[kv[0]]; ok {
+			return nil, fmt.Errorf(
+				"%w: interface '%s' is mapped more than once",
+				util.ErrValidationError,
+				kv[0],
+			)
+		}
+
+		m[kv[0]] = nicID
+	}
+
+	return m, nil
+}
+
+// readPodAnnotations reads a downward api annotations file, each line is of the form
+// 'key="quoted value"'.
+func readPodAnnotations(f string) (map[string]string, error) {
+	fh, err := os.Open(f)
+	if err != nil {
+		return nil, err
+	}
+
+	defer fh.Close()
+
+	annotations := map[string]string{}
+
+	scanner := bufio.NewScanner(fh)
+	scanner.Buffer(nil, 1024*1024) //nolint:gomnd
+
+	for scanner.Scan() {
+		kv := strings.SplitN(scanner.Text(), "=", 2) //nolint:gomnd
+		if len(kv) != 2 {
+			continue
+		}
+
+		v, err := strconv.Unquote(kv[1])
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: failed parsing pod annotation '%s': %s",
+				util.ErrInspectionError,
+				kv[0],
+				err,
+			)
+		}
+
+		annotations[kv[0]] = v
+	}
+
+	return annotations, scanner.Err()
+}
+
+// intfMapFromAnnotations returns the interface mapping from the pod annotations, either the
+// explicit PodIntfMapAnnotation, or the multus network status annotation -- in which case the
+// non-default interfaces are mapped to nics in the order multus attached them.
+func intfMapFromAnnotations(annotations map[string]string) (map[string]int, error) {
+	if v, ok := annotations[PodIntfMapAnnotation]; ok {
+		return ParseIntfMap(v)
+	}
+
+	v, ok := annotations[multusNetworkStatusAnnotation]
+	if !ok {
+		return nil, fmt.Errorf(
+			"%w: neither '%s' nor '%s' pod annotation found",
+			util.ErrInspectionError,
+			PodIntfMapAnnotation,
+			multusNetworkStatusAnnotation,
+		)
+	}
+
+	var statuses []multusNetworkStatus
+
+	err := json.Unmarshal([]byte(v), &statuses)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"%w: failed parsing multus network status: %s",
+			util.ErrInspectionError,
+			err,
+		)
+	}
+
+	m := map[string]int{}
+
+	for _, status := range statuses {
+		if status.Default || status.Interface == "" {
+			continue
+		}
+
+		m[status.Interface] = len(m) + 1
+	}
+
+	return m, nil
+}
+
+// discoverIntfs returns the data plane nic id to host interface mapping for the discovery mode of
+// the runtime options, this is nil for the clab mode.
+func discoverIntfs(opts *PackageStartOptions) (map[int]string, error) {
+	var intfMap map[string]int
+
+	switch opts.IntfDiscovery {
+	case IntfDiscoveryMap:
+		intfMap = opts.IntfMap
+	case IntfDiscoveryAnnotations:
+		annotations, err := readPodAnnotations(opts.PodAnnotationsFile)
+		if err != nil {
+			return nil, err
+		}
+
+		intfMap, err = intfMapFromAnnotations(annotations)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, nil
+	}
+
+	hostIntfs := map[int]string{}
+
+	for intf, nicID := range intfMap {
+		if other, ok := hostIntfs[nicID]; ok {
+			return nil, fmt.Errorf(
+				"%w: interfaces '%s' and '%s' are both mapped to nic %d",
+				util.ErrValidationError,
+				other,
+				intf,
+				nicID,
+			)
+		}
+
+		hostIntfs[nicID] = intf
+	}
+
+	return hostIntfs, nil
+}
+
+// packageStartIntfs discovers the host interfaces for the data plane nics, validates them against
+// the nic count of the instance and writes the tap mapping files for tc-tap-ifup.
+func (b *Boxen) packageStartIntfs(name string, opts *PackageStartOptions) error {
+	hostIntfs, err := discoverIntfs(opts)
+	if err != nil || hostIntfs == nil {
+		return err
+	}
+
+	i := b.Config.Instances[name]
+
+	nicIDs := make([]int, 0, len(hostIntfs))
+
+	for nicID := range hostIntfs {
+		nicIDs = append(nicIDs, nicID)
+	}
+
+	sort.Ints(nicIDs)
+
+	for _, nicID := range nicIDs {
+		if nicID < 1 || nicID > i.Hardware.NicCount {
+			return fmt.Errorf(
+				"%w: interface '%s' is mapped to nic %d, instance has nics 1-%d",
+				util.ErrValidationError,
+				hostIntfs[nicID],
+				nicID,
+				i.Hardware.NicCount,
+			)
+		}
+
+		b.Logger.Infof("mapping interface '%s' to nic %d", hostIntfs[nicID], nicID)
+	}
+
+	err = os.MkdirAll(tapHostIntfDir, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	for nicID, intf := range hostIntfs {
+		err = os.WriteFile(
+			filepath.Join(tapHostIntfDir, fmt.Sprintf("tap%d", nicID)),
+			[]byte(intf),
+			util.FilePerms,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if i.DataPlaneIntf == nil {
+		i.DataPlaneIntf = &config.DataPlaneIntf{}
+	}
+
+	i.DataPlaneIntf.HostIntfs = hostIntfs
+
+	return nil
+}
+
+// waitIntfs waits until all the host interfaces exist.
+func (b *Boxen) waitIntfs(hostIntfs map[int]string) {
+	if len(hostIntfs) == 0 {
+		return
+	}
+
+	b.Logger.Info("waiting until mapped interfaces show up")
+
+	for {
+		missing := 0
+
+		for _, intf := range hostIntfs {
+			if !util.DirectoryExists(fmt.Sprintf("/sys/class/net/%s", intf)) {
+				missing++
+			}
+		}
+
+		if missing == 0 {
+			b.Logger.Debug("mapped interfaces available, moving on")
+
+			return
+		}
+
+		time.Sleep(intfWaitInterval * time.Second)
+	}
+}
@@ -0,0 +1,303 @@
+package boxen
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/carlmontanari/boxen/boxen/util"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseIntfMap(t *testing.T) {
+	tests := []struct {
+		desc    string
+		s       string
+		want    map[string]int
+		wantErr bool
+	}{
+		{
+			desc: "empty",
+			s:    "",
+			want: map[string]int{},
+		},
+		{
+			desc: "interfaces",
+			s:    "net1=1,net2=2,eth7=5",
+			want: map[string]int{"net1": 1, "net2": 2, "eth7": 5},
+		},
+		{
+			desc: "whitespace and empty pairs",
+			s:    " net1=1, ,net2=2,",
+			want: map[string]int{"net1": 1, "net2": 2},
+		},
+		{
+			desc:    "no nic id",
+			s:       "net1",
+			wantErr: true,
+		},
+		{
+			desc:    "no interface",
+			s:       "=1",
+			wantErr: true,
+		},
+		{
+			desc:    "nic id not an integer",
+			s:       "net1=eth1",
+			wantErr: true,
+		},
+		{
+			desc:    "interface mapped twice",
+			s:       "net1=1,net1=2",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := ParseIntfMap(tt.s)
+
+			if tt.wantErr {
+				if !errors.Is(err, util.ErrValidationError) {
+					t.Fatalf("%s: expected validation error, got: %v", tt.desc, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("%s: failed parsing interface map: %s", tt.desc, err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("%s: interface map does not match (-want +got):\n%s", tt.desc, diff)
+			}
+		})
+	}
+}
+
+// writePodAnnotations writes a downward api annotations file with the given content.
+func writePodAnnotations(t *testing.T, content string) string {
+	t.Helper()
+
+	f := filepath.Join(t.TempDir(), "annotations")
+
+	err := os.WriteFile(f, []byte(content), util.FilePerms)
+	if err != nil {
+		t.Fatalf("failed writing annotations: %s", err)
+	}
+
+	return f
+}
+
+func TestReadPodAnnotations(t *testing.T) {
+	annotations, err := readPodAnnotations(filepath.Join("testdata", "pod-annotations-multus"))
+	if err != nil {
+		t.Fatalf("failed reading annotations: %s", err)
+	}
+
+	// the multus network status is pretty printed json, unquoting must restore the newlines/quotes
+	if !strings.HasPrefix(annotations[multusNetworkStatusAnnotation], "[\n    {\n        \"name\"") {
+		t.Fatalf("unexpected network status %q", annotations[multusNetworkStatusAnnotation])
+	}
+
+	got, err := intfMapFromAnnotations(annotations)
+	if err != nil {
+		t.Fatalf("failed reading interface map from multus network status: %s", err)
+	}
+
+	if diff := cmp.Diff(map[string]int{"net1": 1, "net2": 2}, got); diff != "" {
+		t.Fatalf("interface map does not match (-want +got):\n%s", diff)
+	}
+
+	delete(annotations, multusNetworkStatusAnnotation)
+
+	want := map[string]string{
+		"k8s.v1.cni.cncf.io/networks": "lab/veos-net1,lab/veos-net2",
+		"kubernetes.io/config.seen":   "2024-05-02T10:11:12.123456789Z",
+		"kubernetes.io/config.source": "api",
+	}
+
+	if diff := cmp.Diff(want, annotations); diff != "" {
+		t.Fatalf("annotations do not match (-want +got):\n%s", diff)
+	}
+}
+
+func TestReadPodAnnotationsInvalid(t *testing.T) {
+	tests := []struct {
+		desc    string
+		content string
+	}{
+		{
+			desc:    "unquoted value",
+			content: "kubernetes.io/config.source=api\n",
+		},
+		{
+			desc:    "unterminated value",
+			content: "kubernetes.io/config.source=\"api\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, err := readPodAnnotations(writePodAnnotations(t, tt.content))
+			if !errors.Is(err, util.ErrInspectionError) {
+				t.Fatalf("%s: expected inspection error, got: %v", tt.desc, err)
+			}
+		})
+	}
+
+	_, err := readPodAnnotations(filepath.Join(t.TempDir(), "annotations"))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected missing annotations file error, got: %v", err)
+	}
+}
+
+func TestIntfMapFromAnnotations(t *testing.T) {
+	tests := []struct {
+		desc        string
+		annotations map[string]string
+		want        map[string]int
+		wantErr     error
+	}{
+		{
+			desc: "explicit mapping takes precedence",
+			annotations: map[string]string{
+				PodIntfMapAnnotation:          "net2=1,net1=2",
+				multusNetworkStatusAnnotation: `[{"interface":"net1"},{"interface":"net2"}]`,
+			},
+			want: map[string]int{"net1": 2, "net2": 1},
+		},
+		{
+			desc: "multus attachment order",
+			annotations: map[string]string{
+				multusNetworkStatusAnnotation: `[{"interface":"eth0","default":true},` +
+					`{"name":"lab/b","interface":"net2"},{"name":"lab/a","interface":"net1"}]`,
+			},
+			want: map[string]int{"net2": 1, "net1": 2},
+		},
+		{
+			desc: "multus network without interface",
+			annotations: map[string]string{
+				multusNetworkStatusAnnotation: `[{"interface":"eth0","default":true},` +
+					`{"name":"lab/a"},{"name":"lab/b","interface":"net1"}]`,
+			},
+			want: map[string]int{"net1": 1},
+		},
+		{
+			desc:        "no annotations",
+			annotations: map[string]string{"kubernetes.io/config.source": "api"},
+			wantErr:     util.ErrInspectionError,
+		},
+		{
+			desc: "malformed multus network status",
+			annotations: map[string]string{
+				multusNetworkStatusAnnotation: `{"interface":"net1"}`,
+			},
+			wantErr: util.ErrInspectionError,
+		},
+		{
+			desc: "malformed explicit mapping",
+			annotations: map[string]string{
+				PodIntfMapAnnotation: "net1",
+			},
+			wantErr: util.ErrValidationError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := intfMapFromAnnotations(tt.annotations)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("%s: expected error '%s', got: %v", tt.desc, tt.wantErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("%s: failed reading interface map: %s", tt.desc, err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("%s: interface map does not match (-want +got):\n%s", tt.desc, diff)
+			}
+		})
+	}
+}
+
+func TestDiscoverIntfs(t *testing.T) {
+	tests := []struct {
+		desc    string
+		opts    *PackageStartOptions
+		want    map[int]string
+		wantErr bool
+	}{
+		{
+			desc: "clab",
+			opts: &PackageStartOptions{IntfDiscovery: IntfDiscoveryClab},
+			want: nil,
+		},
+		{
+			desc: "map",
+			opts: &PackageStartOptions{
+				IntfDiscovery: IntfDiscoveryMap,
+				IntfMap:       map[string]int{"net1": 1, "net2": 3},
+			},
+			want: map[int]string{1: "net1", 3: "net2"},
+		},
+		{
+			desc: "map with duplicate nics",
+			opts: &PackageStartOptions{
+				IntfDiscovery: IntfDiscoveryMap,
+				IntfMap:       map[string]int{"net1": 1, "net2": 1},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "annotations",
+			opts: &PackageStartOptions{
+				IntfDiscovery:      IntfDiscoveryAnnotations,
+				PodAnnotationsFile: filepath.Join("testdata", "pod-annotations-multus"),
+			},
+			want: map[int]string{1: "net1", 2: "net2"},
+		},
+		{
+			desc: "annotations with duplicate nics",
+			opts: &PackageStartOptions{
+				IntfDiscovery: IntfDiscoveryAnnotations,
+				PodAnnotationsFile: writePodAnnotations(
+					t,
+					PodIntfMapAnnotation+`="net1=2,net2=2"`+"\n",
+				),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := discoverIntfs(tt.opts)
+
+			if tt.wantErr {
+				if !errors.Is(err, util.ErrValidationError) {
+					t.Fatalf("%s: expected validation error, got: %v", tt.desc, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("%s: failed discovering interfaces: %s", tt.desc, err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("%s: interfaces do not match (-want +got):\n%s", tt.desc, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,388 @@
+package boxen
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/carlmontanari/boxen/boxen/config"
+	"github.com/carlmontanari/boxen/boxen/util"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// DefaultPackageStartConfigFile is the runtime options file package-start loads if it exists,
+	// mount a file here to configure a packaged image without rebuilding it.
+	DefaultPackageStartConfigFile = "/boxen-start.yaml"
+	// PackageStartConfigFileEnv is the env var that overrides the runtime options file path.
+	PackageStartConfigFileEnv = "BOXEN_START_CONFIG"
+	// PackageStartDefaultsFile is the file embedded in the root of packaged images holding the
+	// runtime options the image was packaged with (i.e. the timeout multiplier), these override
+	// the boxen defaults but nothing else.
+	PackageStartDefaultsFile = "boxen-start-defaults.yaml"
+
+	defaultPackageStartLogLevel = "info"
+)
+
+// PackageStartHardware holds the hardware overrides applied to a packaged instance at start, any
+// zero value keeps what the image was packaged with.
+type PackageStartHardware struct {
+	// Memory is the instance memory in MB.
+	Memory int `yaml:"memory,omitempty"`
+	// CPUCores is the number of cpu cores of the instance.
+	CPUCores int `yaml:"cpu_cores,omitempty"`
+	// NicCount is the number of data plane nics of the instance.
+	NicCount int `yaml:"nic_count,omitempty"`
+}
+
+// PackageStartOptions are the runtime options of a packaged instance (see PackageStart). Options
+// are resolved from (in increasing order of precedence) the defaults, the runtime options file, env
+// vars, and finally flags -- see ResolvePackageStartOptions. The bool options are pointers so that
+// false can override true, nil means not set.
+type PackageStartOptions struct {
+	// ClabIntfs is the number of data plane interfaces containerlab attaches, start waits for these
+	// to show up before launching the instance. Env: CLAB_INTFS.
+	ClabIntfs int `yaml:"clab_intfs"`
+	// BootDelay is the number of seconds to wait before booting the instance. Env: BOOT_DELAY.
+	BootDelay int `yaml:"boot_delay"`
+	// StartupConfig is the path to a config to load on the instance once booted. Env:
+	// STARTUP_CONFIG.
+	StartupConfig string `yaml:"startup_config"`
+	// TimeoutMultiplier is multiplied with all boxen timeouts. Env: BOXEN_TIMEOUT_MULTIPLIER.
+	TimeoutMultiplier int `yaml:"timeout_multiplier"`
+	// LogLevel is the boxen log level, one of debug, info or critical. Env: BOXEN_LOG_LEVEL.
+	LogLevel string `yaml:"log_level"`
+	// ScrapliPlatformDefinition overrides the scrapli platform definition used to connect to the
+	// instance. Env: BOXEN_SCRAPLI_PLATFORM_DEFINITION.
+	ScrapliPlatformDefinition string `yaml:"scrapli_platform_definition,omitempty"`
+	// Hardware holds the hardware overrides for the instance. Env: BOXEN_MEMORY,
+	// BOXEN_CPU_CORES and BOXEN_NIC_COUNT.
+	Hardware PackageStartHardware `yaml:"hardware"`
+	// IntfDiscovery is how the host interfaces the data plane nics are tapped to are discovered,
+	// one of IntfDiscoveryClab, IntfDiscoveryMap or IntfDiscoveryAnnotations. Env:
+	// BOXEN_INTF_DISCOVERY.
+	IntfDiscovery string `yaml:"intf_discovery"`
+	// IntfMap maps host interfaces to data plane nic ids for IntfDiscoveryMap. Env: BOXEN_INTF_MAP
+	// (i.e. "net1=1,net2=2").
+	IntfMap map[string]int `yaml:"intf_map,omitempty"`
+	// PodAnnotationsFile is the downward api annotations file read for IntfDiscoveryAnnotations.
+	// Env: BOXEN_POD_ANNOTATIONS_FILE.
+	PodAnnotationsFile string `yaml:"pod_annotations_file,omitempty"`
+	// VNC enables the managed vnc console of the instance, served on the container port 6001.
+	// Env: BOXEN_VNC.
+	VNC *bool `yaml:"vnc,omitempty"`
+	// VNCPassword is the vnc password, vnc is served without authentication if not set. Env:
+	// BOXEN_VNC_PASSWORD.
+	VNCPassword string `yaml:"vnc_password,omitempty"`
+	// TCGFallback runs the instance with tcg (software emulation) if kvm is not available, rather
+	// than failing. Env: BOXEN_TCG_FALLBACK.
+	TCGFallback *bool `yaml:"tcg_fallback,omitempty"`
+}
+
+// DefaultPackageStartOptions returns the runtime options used if nothing is configured.
+func DefaultPackageStartOptions() *PackageStartOptions {
+	return &PackageStartOptions{
+		TimeoutMultiplier:  1,
+		LogLevel:           defaultPackageStartLogLevel,
+		IntfDiscovery:      IntfDiscoveryClab,
+		PodAnnotationsFile: DefaultPodAnnotationsFile,
+	}
+}
+
+// Merge updates the options with any non-zero values set in the options m.
+func (o *PackageStartOptions) Merge(m *PackageStartOptions) {
+	if m == nil {
+		return
+	}
+
+	for _, v := range []struct {
+		dst *int
+		src int
+	}{
+		{&o.ClabIntfs, m.ClabIntfs},
+		{&o.BootDelay, m.BootDelay},
+		{&o.TimeoutMultiplier, m.TimeoutMultiplier},
+		{&o.Hardware.Memory, m.Hardware.Memory},
+		{&o.Hardware.CPUCores, m.Hardware.CPUCores},
+		{&o.Hardware.NicCount, m.Hardware.NicCount},
+	} {
+		if v.src != 0 {
+			*v.dst = v.src
+		}
+	}
+
+	for _, v := range []struct {
+		dst *string
+		src string
+	}{
+		{&o.StartupConfig, m.StartupConfig},
+		{&o.LogLevel, m.LogLevel},
+		{&o.ScrapliPlatformDefinition, m.ScrapliPlatformDefinition},
+		{&o.IntfDiscovery, m.IntfDiscovery},
+		{&o.PodAnnotationsFile, m.PodAnnotationsFile},
+	} {
+		if v.src != "" {
+			*v.dst = v.src
+		}
+	}
+
+	if len(m.IntfMap) > 0 {
+		o.IntfMap = m.IntfMap
+	}
+
+	for _, v := range []struct {
+		dst **bool
+		src *bool
+	}{
+		{&o.VNC, m.VNC},
+		{&o.TCGFallback, m.TCGFallback},
+	} {
+		if v.src != nil {
+			b := *v.src
+			*v.dst = &b
+		}
+	}
+
+	if m.VNCPassword != "" {
+		o.VNCPassword = m.VNCPassword
+	}
+}
+
+// Validate checks the options are sane.
+func (o *PackageStartOptions) Validate() error {
+	for name, v := range map[string]int{
+		"clab_intfs":         o.ClabIntfs,
+		"boot_delay":         o.BootDelay,
+		"hardware.memory":    o.Hardware.Memory,
+		"hardware.cpu_cores": o.Hardware.CPUCores,
+		"hardware.nic_count": o.Hardware.NicCount,
+	} {
+		if v < 0 {
+			return fmt.Errorf("%w: %s must not be negative", util.ErrValidationError, name)
+		}
+	}
+
+	if o.TimeoutMultiplier < 1 {
+		return fmt.Errorf("%w: timeout_multiplier must be at least 1", util.ErrValidationError)
+	}
+
+	switch o.IntfDiscovery {
+	case IntfDiscoveryClab, IntfDiscoveryAnnotations:
+	case IntfDiscoveryMap:
+		if len(o.IntfMap) == 0 {
+			return fmt.Errorf(
+				"%w: intf_discovery '%s' requires intf_map",
+				util.ErrValidationError,
+				IntfDiscoveryMap,
+			)
+		}
+	default:
+		return fmt.Errorf(
+			"%w: unknown intf_discovery '%s', must be one of %s, %s, %s",
+			util.ErrValidationError,
+			o.IntfDiscovery,
+			IntfDiscoveryClab,
+			IntfDiscoveryMap,
+			IntfDiscoveryAnnotations,
+		)
+	}
+
+	if o.VNCPassword != "" {
+		err := (&config.VNC{Password: o.VNCPassword}).Validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Export sets the env vars for the process wide options (timeout multiplier, log level and scrapli
+// platform definition), these are read from the env by the packages that use them.
+func (o *PackageStartOptions) Export() error {
+	envs := map[string]string{
+		"BOXEN_TIMEOUT_MULTIPLIER": strconv.Itoa(o.TimeoutMultiplier),
+		"BOXEN_LOG_LEVEL":          o.LogLevel,
+	}
+
+	if o.ScrapliPlatformDefinition != "" {
+		envs["BOXEN_SCRAPLI_PLATFORM_DEFINITION"] = o.ScrapliPlatformDefinition
+	}
+
+	for k, v := range envs {
+		err := os.Setenv(k, v)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Dump returns the options as yaml, the vnc password is masked.
+func (o *PackageStartOptions) Dump() ([]byte, error) {
+	d := *o
+
+	if d.VNCPassword != "" {
+		d.VNCPassword = "********"
+	}
+
+	return yaml.Marshal(&d)
+}
+
+// loadPackageStartOptionsFile loads runtime options from the yaml file f. A missing file is only
+// an error if required is true, i.e. if the file was explicitly requested.
+func loadPackageStartOptionsFile(f string, required bool) (*PackageStartOptions, error) {
+	raw, err := os.ReadFile(util.ExpandPath(f))
+	if err != nil {
+		if os.IsNotExist(err) && !required {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	o := &PackageStartOptions{}
+
+	err = yaml.UnmarshalStrict(raw, o)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"%w: failed parsing runtime options file '%s': %s",
+			util.ErrValidationError,
+			f,
+			err,
+		)
+	}
+
+	return o, nil
+}
+
+// loadPackageStartOptionsEnv loads runtime options from env vars.
+func loadPackageStartOptionsEnv() (*PackageStartOptions, error) {
+	o := &PackageStartOptions{}
+
+	for k, v := range map[string]*int{
+		"CLAB_INTFS":               &o.ClabIntfs,
+		"BOOT_DELAY":               &o.BootDelay,
+		"BOXEN_TIMEOUT_MULTIPLIER": &o.TimeoutMultiplier,
+		"BOXEN_MEMORY":             &o.Hardware.Memory,
+		"BOXEN_CPU_CORES":          &o.Hardware.CPUCores,
+		"BOXEN_NIC_COUNT":          &o.Hardware.NicCount,
+	} {
+		s, ok := os.LookupEnv(k)
+		if !ok || s == "" {
+			continue
+		}
+
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: env var '%s' value '%s' is not an integer",
+				util.ErrValidationError,
+				k,
+				s,
+			)
+		}
+
+		*v = i
+	}
+
+	o.StartupConfig = util.GetEnvStrOrDefault("STARTUP_CONFIG", "")
+	o.LogLevel = util.GetEnvStrOrDefault("BOXEN_LOG_LEVEL", "")
+	o.ScrapliPlatformDefinition = util.GetEnvStrOrDefault("BOXEN_SCRAPLI_PLATFORM_DEFINITION", "")
+	o.IntfDiscovery = util.GetEnvStrOrDefault("BOXEN_INTF_DISCOVERY", "")
+	o.PodAnnotationsFile = util.GetEnvStrOrDefault("BOXEN_POD_ANNOTATIONS_FILE", "")
+
+	var err error
+
+	for k, v := range map[string]**bool{
+		"BOXEN_VNC":          &o.VNC,
+		"BOXEN_TCG_FALLBACK": &o.TCGFallback,
+	} {
+		s, ok := os.LookupEnv(k)
+		if !ok || s == "" {
+			continue
+		}
+
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: env var '%s' value '%s' is not a boolean",
+				util.ErrValidationError,
+				k,
+				s,
+			)
+		}
+
+		*v = &b
+	}
+
+	o.VNCPassword = util.GetEnvStrOrDefault("BOXEN_VNC_PASSWORD", "")
+
+	o.IntfMap, err = ParseIntfMap(util.GetEnvStrOrDefault("BOXEN_INTF_MAP", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// writePackageStartDefaults writes the PackageStartDefaultsFile f for a packaged image, holding the
+// timeout multiplier and log level boxen packages the image with.
+func writePackageStartDefaults(f string) error {
+	raw, err := yaml.Marshal(&PackageStartOptions{
+		TimeoutMultiplier: util.GetTimeoutMultiplier(),
+		LogLevel:          util.GetEnvStrOrDefault("BOXEN_LOG_LEVEL", defaultPackageStartLogLevel),
+	})
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(f, raw, util.FilePerms)
+}
+
+// ResolvePackageStartOptions returns the effective runtime options of a packaged instance. The
+// defaults (the boxen defaults, overridden by the PackageStartDefaultsFile of the image) are
+// overridden by the runtime options file f (DefaultPackageStartConfigFile if empty), which is
+// overridden by env vars, which are overridden by the (set) flags options.
+func ResolvePackageStartOptions(
+	f string,
+	flags *PackageStartOptions,
+) (*PackageStartOptions, error) {
+	required := true
+
+	if f == "" {
+		f = DefaultPackageStartConfigFile
+		required = false
+	}
+
+	imageOpts, err := loadPackageStartOptionsFile("/"+PackageStartDefaultsFile, false)
+	if err != nil {
+		return nil, err
+	}
+
+	fileOpts, err := loadPackageStartOptionsFile(f, required)
+	if err != nil {
+		return nil, err
+	}
+
+	envOpts, err := loadPackageStartOptionsEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	o := DefaultPackageStartOptions()
+
+	o.Merge(imageOpts)
+	o.Merge(fileOpts)
+	o.Merge(envOpts)
+	o.Merge(flags)
+
+	err = o.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
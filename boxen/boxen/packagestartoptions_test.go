@@ -0,0 +1,82 @@
+package boxen_test
+
+import (
+	"testing"
+
+	"github.com/carlmontanari/boxen/boxen/boxen"
+	"github.com/google/go-cmp/cmp"
+)
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestPackageStartOptionsMerge(t *testing.T) {
+	tests := []struct {
+		desc   string
+		layers []*boxen.PackageStartOptions
+		want   *boxen.PackageStartOptions
+	}{
+		{
+			desc:   "nothing set keeps the defaults",
+			layers: []*boxen.PackageStartOptions{nil, {}},
+			want:   boxen.DefaultPackageStartOptions(),
+		},
+		{
+			desc: "later layers override earlier ones",
+			layers: []*boxen.PackageStartOptions{
+				{TimeoutMultiplier: 3, LogLevel: "debug"},
+				{TimeoutMultiplier: 5},
+			},
+			want: &boxen.PackageStartOptions{
+				TimeoutMultiplier:  5,
+				LogLevel:           "debug",
+				IntfDiscovery:      boxen.IntfDiscoveryClab,
+				PodAnnotationsFile: boxen.DefaultPodAnnotationsFile,
+			},
+		},
+		{
+			desc: "false overrides true",
+			layers: []*boxen.PackageStartOptions{
+				{VNC: boolPtr(true), TCGFallback: boolPtr(true)},
+				{VNC: boolPtr(false)},
+			},
+			want: &boxen.PackageStartOptions{
+				TimeoutMultiplier:  1,
+				LogLevel:           "info",
+				IntfDiscovery:      boxen.IntfDiscoveryClab,
+				PodAnnotationsFile: boxen.DefaultPodAnnotationsFile,
+				VNC:                boolPtr(false),
+				TCGFallback:        boolPtr(true),
+			},
+		},
+		{
+			desc: "unset bools do not override",
+			layers: []*boxen.PackageStartOptions{
+				{VNC: boolPtr(false)},
+				{LogLevel: "critical"},
+			},
+			want: &boxen.PackageStartOptions{
+				TimeoutMultiplier:  1,
+				LogLevel:           "critical",
+				IntfDiscovery:      boxen.IntfDiscoveryClab,
+				PodAnnotationsFile: boxen.DefaultPodAnnotationsFile,
+				VNC:                boolPtr(false),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			o := boxen.DefaultPackageStartOptions()
+
+			for _, l := range tt.layers {
+				o.Merge(l)
+			}
+
+			if diff := cmp.Diff(tt.want, o); diff != "" {
+				t.Fatalf("%s: merged options do not match (-want +got):\n%s", tt.desc, diff)
+			}
+		})
+	}
+}
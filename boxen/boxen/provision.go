@@ -63,6 +63,15 @@ func (b *Boxen) provision(
 	hw.SerialPorts = serialPorts
 	hw.MonitorPort = monitorPort
 
+	if profileObj.Advanced.VNCEnabled() {
+		hw.VNCPort, err = b.allocateVNCPort(instanceID)
+		if err != nil {
+			b.Logger.Critical("failed to allocate vnc port")
+
+			return err
+		}
+	}
+
 	b.Config.AddInstance(name, &config.Instance{
 		Name:         name,
 		PlatformType: platformType,
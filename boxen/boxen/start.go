@@ -49,15 +49,36 @@ func (b *Boxen) copySourceDiskToInstanceDir(name, disk string) error {
 	return nil
 }
 
+// sourceRunFiles returns the paths of the run files (everything but the disk itself) stored with
+// the installed source disk of platform type pT and version.
+func (b *Boxen) sourceRunFiles(pT, version string) ([]string, error) {
+	sourceDir := fmt.Sprintf("%s/%s/%s", b.Config.Options.Build.SourcePath, pT, version)
+
+	entries, err := os.ReadDir(sourceDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var runFiles []string
+
+	for _, e := range entries {
+		if e.IsDir() || e.Name() == "disk.qcow2" {
+			continue
+		}
+
+		runFiles = append(runFiles, fmt.Sprintf("%s/%s", sourceDir, e.Name()))
+	}
+
+	return runFiles, nil
+}
+
 func (b *Boxen) copySourceRunFilesToInstanceDir(name, disk string) error {
-	runFiles, err := filepath.Glob(
-		fmt.Sprintf("%s/%s/%s/[^disk.qcow2]*", b.Config.Options.Build.SourcePath,
-			b.Config.Instances[name].PlatformType,
-			b.Config.Instances[name].Disk),
+	runFiles, err := b.sourceRunFiles(
+		b.Config.Instances[name].PlatformType,
+		b.Config.Instances[name].Disk,
 	)
-
 	if err != nil {
-		b.Logger.Criticalf("error globbing run files associated with disk: %s\n", err)
+		b.Logger.Criticalf("error listing run files associated with disk: %s\n", err)
 
 		return err
 	}
@@ -102,6 +123,27 @@ func (b *Boxen) startCheckDisk(name, disk string) error {
 	return nil
 }
 
+// startAllocateVNCPort allocates a vnc port for instances that have vnc enabled (after they were
+// provisioned) but no port yet, the port is stored when the config is dumped after start.
+func (b *Boxen) startAllocateVNCPort(name string) error {
+	i := b.Config.Instances[name]
+
+	if !i.Advanced.VNCEnabled() || i.Hardware.VNCPort != 0 {
+		return nil
+	}
+
+	port, err := b.allocateVNCPort(i.ID)
+	if err != nil {
+		b.Logger.Critical("failed to allocate vnc port")
+
+		return err
+	}
+
+	i.Hardware.VNCPort = port
+
+	return nil
+}
+
 // Start starts a local boxen instance.
 func (b *Boxen) Start(name string) error {
 	b.Logger.Infof("start for instance '%s' requested", name)
@@ -111,11 +153,16 @@ func (b *Boxen) Start(name string) error {
 		return fmt.Errorf("%w: no instance name '%s' in the config", util.ErrInstanceError, name)
 	}
 
+	err := b.startAllocateVNCPort(name)
+	if err != nil {
+		return err
+	}
+
 	instanceDir := fmt.Sprintf("%s/%s", b.Config.Options.Build.InstancePath, name)
 	instanceDirExists := util.DirectoryExists(instanceDir)
 
 	if !instanceDirExists {
-		err := os.Mkdir(instanceDir, os.ModePerm)
+		err = os.Mkdir(instanceDir, os.ModePerm)
 		if err != nil {
 			b.Logger.Criticalf("error creating instance directory: %s", err)
 
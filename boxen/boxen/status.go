@@ -0,0 +1,76 @@
+package boxen
+
+import (
+	"net"
+	"sort"
+	"strconv"
+
+	"github.com/carlmontanari/boxen/boxen/instance"
+)
+
+const (
+	// StateRunning is the state of an instance with a running qemu process.
+	StateRunning = "running"
+	// StateStopped is the state of an instance without a running qemu process.
+	StateStopped = "stopped"
+)
+
+// InstanceStatus is the status of a local boxen instance and the addresses of its consoles.
+type InstanceStatus struct {
+	Name         string
+	PlatformType string
+	State        string
+	PID          int
+	MonitorPort  int
+	// SerialPorts are the addresses the serial consoles are served on.
+	SerialPorts []string
+	// VNC is the address the vnc console is served on, empty if vnc is not enabled.
+	VNC string
+}
+
+// Status returns the status of all local boxen instances, sorted by name.
+func (b *Boxen) Status() []*InstanceStatus {
+	names := make([]string, 0, len(b.Config.Instances))
+
+	for name := range b.Config.Instances {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	statuses := make([]*InstanceStatus, 0, len(names))
+
+	for _, name := range names {
+		i := b.Config.Instances[name]
+
+		s := &InstanceStatus{
+			Name:         name,
+			PlatformType: i.PlatformType,
+			State:        StateStopped,
+		}
+
+		if instance.ProcessRunning(i.PID, name) {
+			s.State = StateRunning
+			s.PID = i.PID
+		}
+
+		if i.Hardware != nil {
+			s.MonitorPort = i.Hardware.MonitorPort
+
+			for _, port := range i.Hardware.SerialPorts {
+				s.SerialPorts = append(
+					s.SerialPorts,
+					net.JoinHostPort(b.Config.Options.Console.Address(), strconv.Itoa(port)),
+				)
+			}
+
+			if i.Advanced.VNCEnabled() && i.Hardware.VNCPort != 0 {
+				s.VNC = net.JoinHostPort(i.Advanced.VNC.Address(), strconv.Itoa(i.Hardware.VNCPort))
+			}
+		}
+
+		statuses = append(statuses, s)
+	}
+
+	return statuses
+}
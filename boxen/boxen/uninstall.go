@@ -52,6 +52,12 @@ func (b *Boxen) UnInstall(pT, disk string) error {
 
 	b.Config.Platforms[pT].SourceDisks = updatedSourceDisks
 
+	for v := range b.Config.Platforms[pT].SourceCredentials {
+		if strings.HasPrefix(v, disk) {
+			delete(b.Config.Platforms[pT].SourceCredentials, v)
+		}
+	}
+
 	if len(b.Config.Platforms[pT].SourceDisks) == 0 {
 		b.Logger.Debug("no disks remain for platform, deleting platform source directory")
 
@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/carlmontanari/boxen/boxen/boxen"
+
+	"github.com/urfave/cli/v2"
+)
+
+// installCacheShortKeyLen is how much of the cache keys is shown when listing entries.
+const installCacheShortKeyLen = 12
+
+func installCacheCommands() []*cli.Command {
+	cacheDir := installCacheDirFlag()
+
+	jsonOut := &cli.BoolFlag{
+		Name:     "json",
+		Usage:    "print entries as json",
+		Required: false,
+	}
+
+	olderThan := &cli.DurationFlag{
+		Name:     "older-than",
+		Usage:    "prune entries not used for longer than this, ex: '720h'",
+		Required: false,
+	}
+
+	platformType := &cli.StringFlag{
+		Name:     "platform-type",
+		Usage:    "prune entries of this platform type only, ex: 'cisco_xrv9k'",
+		Required: false,
+	}
+
+	all := &cli.BoolFlag{
+		Name:     "all",
+		Usage:    "prune all entries",
+		Required: false,
+	}
+
+	return []*cli.Command{{
+		Name:  "cache",
+		Usage: "manage the install cache of installed disks used when packaging",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "list install cache entries",
+				Flags: []cli.Flag{
+					cacheDir,
+					jsonOut,
+				},
+				Action: func(c *cli.Context) error {
+					return listInstallCache(c.String("install-cache-dir"), c.Bool("json"))
+				},
+			},
+			{
+				Name:  "prune",
+				Usage: "remove install cache entries",
+				Flags: []cli.Flag{
+					cacheDir,
+					olderThan,
+					platformType,
+					all,
+					jsonOut,
+				},
+				Action: func(c *cli.Context) error {
+					return pruneInstallCache(
+						c.String("install-cache-dir"),
+						c.Duration("older-than"),
+						c.String("platform-type"),
+						c.Bool("all"),
+						c.Bool("json"),
+					)
+				},
+			},
+		},
+	}}
+}
+
+func listInstallCache(cacheDir string, jsonOut bool) error {
+	b, err := boxen.NewBoxen(boxen.WithInstallCacheDir(cacheDir))
+	if err != nil {
+		return err
+	}
+
+	entries, err := b.ListInstallCache()
+	if err != nil {
+		return err
+	}
+
+	return printInstallCacheEntries(entries, jsonOut)
+}
+
+func pruneInstallCache(
+	cacheDir string,
+	olderThan time.Duration,
+	platformType string,
+	all, jsonOut bool,
+) error {
+	b, err := boxen.NewBoxen(boxen.WithInstallCacheDir(cacheDir))
+	if err != nil {
+		return err
+	}
+
+	pruned, err := b.PruneInstallCache(olderThan, platformType, all)
+	if err != nil {
+		return err
+	}
+
+	return printInstallCacheEntries(pruned, jsonOut)
+}
+
+func printInstallCacheEntries(entries []*boxen.InstallCacheEntry, jsonOut bool) error {
+	if jsonOut {
+		if entries == nil {
+			entries = []*boxen.InstallCacheEntry{}
+		}
+
+		out, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(out))
+
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:gomnd
+
+	fmt.Fprintln(w, "KEY\tPLATFORM TYPE\tVERSION\tBOXEN VERSION\tSIZE (MB)\tLAST USED")
+
+	for _, e := range entries {
+		key := e.Key
+		if len(key) > installCacheShortKeyLen {
+			key = key[:installCacheShortKeyLen]
+		}
+
+		fmt.Fprintf(
+			w,
+			"%s\t%s\t%s\t%s\t%d\t%s\n",
+			key,
+			e.PlatformType,
+			e.Version,
+			e.BoxenVersion,
+			e.Size/1024/1024, //nolint:gomnd
+			e.LastUsed,
+		)
+	}
+
+	return w.Flush()
+}
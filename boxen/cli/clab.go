@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/carlmontanari/boxen/boxen/boxen"
+	"github.com/carlmontanari/boxen/boxen/util"
+
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v2"
+)
+
+func clabCommands() []*cli.Command {
+	config := boxenGlobalFlags()
+
+	instances := &cli.StringFlag{
+		Name:     "instances",
+		Usage:    "instance or comma sep string of instances to export, default all instances",
+		Required: false,
+	}
+
+	group := &cli.StringFlag{
+		Name:     "group",
+		Usage:    "name of instance group to export",
+		Required: false,
+	}
+
+	name := &cli.StringFlag{
+		Name:     "name",
+		Usage:    "name of the containerlab topology",
+		Required: false,
+		Value:    "boxen",
+	}
+
+	output := &cli.StringFlag{
+		Name:     "output",
+		Usage:    "file to write the topology to, default stdout",
+		Required: false,
+	}
+
+	images := &cli.StringSliceFlag{
+		Name:     "image",
+		Usage:    "image to use for a platform type, ex: 'arista_veos=myrepo/veos:4.22.1F'",
+		Required: false,
+	}
+
+	repoPrefix := &cli.StringFlag{
+		Name:     "repo-prefix",
+		Usage:    "prefix for the default image names, ex: 'localhost:5000/'",
+		Required: false,
+	}
+
+	kinds := &cli.StringSliceFlag{
+		Name:     "kind",
+		Usage:    "containerlab kind to use for a platform type, ex: 'arista_veos=vr-veos'",
+		Required: false,
+	}
+
+	return []*cli.Command{{
+		Name:  "clab",
+		Usage: "containerlab integration",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "export",
+				Usage: "export boxen instances as a containerlab topology",
+				Flags: []cli.Flag{
+					config,
+					instances,
+					group,
+					name,
+					output,
+					images,
+					repoPrefix,
+					kinds,
+				},
+				Action: func(c *cli.Context) error {
+					return ClabExport(
+						c.String("config"),
+						c.String("instances"),
+						c.String("group"),
+						c.String("name"),
+						c.String("output"),
+						c.StringSlice("image"),
+						c.String("repo-prefix"),
+						c.StringSlice("kind"),
+					)
+				},
+			},
+		},
+	}}
+}
+
+// parsePlatformTypeMap parses a list of "platform_type=value" strings.
+func parsePlatformTypeMap(flag string, values []string) (map[string]string, error) {
+	m := map[string]string{}
+
+	for _, v := range values {
+		kv := strings.SplitN(v, "=", 2) //nolint:gomnd
+		if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+			return nil, fmt.Errorf(
+				"%w: %s '%s' is not of the form 'platform_type=value'",
+				util.ErrValidationError,
+				flag,
+				v,
+			)
+		}
+
+		m[kv[0]] = kv[1]
+	}
+
+	return m, nil
+}
+
+func ClabExport(
+	config, instances, group, name, output string,
+	images []string,
+	repoPrefix string,
+	kinds []string,
+) error {
+	if instances != "" && group != "" {
+		return fmt.Errorf("%w: only one of instances or group may be set", util.ErrValidationError)
+	}
+
+	b, err := boxen.NewBoxen(boxen.WithConfig(config))
+	if err != nil {
+		return err
+	}
+
+	opts := &boxen.ClabExportOptions{
+		Name:       name,
+		RepoPrefix: repoPrefix,
+	}
+
+	switch {
+	case group != "":
+		opts.Instances, err = b.GetGroupInstances(group)
+		if err != nil {
+			return err
+		}
+	case instances != "":
+		opts.Instances = strings.Split(instances, ",")
+	}
+
+	opts.Images, err = parsePlatformTypeMap("image", images)
+	if err != nil {
+		return err
+	}
+
+	opts.Kinds, err = parsePlatformTypeMap("kind", kinds)
+	if err != nil {
+		return err
+	}
+
+	t, err := b.ClabExport(opts)
+	if err != nil {
+		return err
+	}
+
+	out, err := yaml.Marshal(t)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		fmt.Print(string(out))
+
+		return nil
+	}
+
+	return os.WriteFile(util.ExpandPath(output), out, util.FilePerms)
+}
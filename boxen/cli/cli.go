@@ -4,6 +4,9 @@ import (
 	"fmt"
 
 	"github.com/carlmontanari/boxen/boxen/boxen"
+	"github.com/carlmontanari/boxen/boxen/config"
+	"github.com/carlmontanari/boxen/boxen/docker"
+	"github.com/carlmontanari/boxen/boxen/util"
 
 	"github.com/urfave/cli/v2"
 )
@@ -41,6 +44,103 @@ func customizationFlags() (username, password, hostname *cli.StringFlag) {
 	return username, password, hostname
 }
 
+func credentialSourceFlags() (usernameFrom, passwordFrom *cli.StringFlag) {
+	usernameFrom = &cli.StringFlag{
+		Name: "username-from",
+		Usage: "source to read the username from instead of providing it directly, one of " +
+			"'env:NAME', 'file:PATH', 'command:COMMAND', or 'keyring:ATTR=VALUE[,ATTR=VALUE]'",
+		Required: false,
+	}
+
+	passwordFrom = &cli.StringFlag{
+		Name: "password-from",
+		Usage: "source to read the password from instead of providing it directly, one of " +
+			"'env:NAME', 'file:PATH', 'command:COMMAND', or 'keyring:ATTR=VALUE[,ATTR=VALUE]'",
+		Required: false,
+	}
+
+	return usernameFrom, passwordFrom
+}
+
+func sshKeysFlag() *cli.StringSliceFlag {
+	return &cli.StringSliceFlag{
+		Name: "ssh-key",
+		Usage: "ssh public key, or path to a public key/authorized_keys file, to authorize for the " +
+			"user, may be provided multiple times",
+		Required: false,
+	}
+}
+
+func containerEngineFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name: "container-engine",
+		Usage: "container engine to use, one of 'docker', 'podman' or 'auto' (prefer docker, fall " +
+			"back to podman)",
+		Required: false,
+		Value:    docker.EngineAuto,
+		EnvVars:  []string{"BOXEN_CONTAINER_ENGINE"},
+	}
+}
+
+func installCacheDirFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:     "install-cache-dir",
+		Usage:    "directory installed disks are cached in",
+		Required: false,
+		Value:    boxen.DefaultInstallCacheDir,
+		EnvVars:  []string{"BOXEN_INSTALL_CACHE_DIR"},
+	}
+}
+
+// credentialsFromFlags builds a Credentials object from the username/password, credential source
+// and ssh key flags. The literal flags and the source flags for the same value are mutually
+// exclusive.
+func credentialsFromFlags(c *cli.Context) (*config.Credentials, error) {
+	creds := &config.Credentials{
+		Username: c.String("username"),
+		Password: c.String("password"),
+	}
+
+	for _, f := range []struct {
+		literal, source string
+		target          **config.CredentialSource
+	}{
+		{"username", "username-from", &creds.UsernameFrom},
+		{"password", "password-from", &creds.PasswordFrom},
+	} {
+		if c.String(f.source) == "" {
+			continue
+		}
+
+		if c.String(f.literal) != "" {
+			return nil, fmt.Errorf(
+				"%w: only one of '--%s' and '--%s' may be provided",
+				util.ErrValidationError,
+				f.literal,
+				f.source,
+			)
+		}
+
+		src, err := config.ParseCredentialSource(c.String(f.source))
+		if err != nil {
+			return nil, err
+		}
+
+		*f.target = src
+	}
+
+	for _, s := range c.StringSlice("ssh-key") {
+		keys, err := util.LoadSSHPublicKeys(s)
+		if err != nil {
+			return nil, err
+		}
+
+		creds.SSHKeys = append(creds.SSHKeys, keys...)
+	}
+
+	return creds, creds.Validate()
+}
+
 func platformTargetFlags() (vendor, platform, version *cli.StringFlag) {
 	vendor = &cli.StringFlag{
 		Name:     "vendor",
@@ -77,8 +177,10 @@ func operationCommands() []*cli.Command {
 		Required: false,
 	}
 
+	dryRun, format := dryRunFlags()
+
 	return []*cli.Command{
-		{ //nolint:dupl
+		{
 			Name:  "start",
 			Usage: "start boxen instance(s)/group(s)",
 			Subcommands: []*cli.Command{
@@ -88,8 +190,18 @@ func operationCommands() []*cli.Command {
 					Flags: []cli.Flag{
 						config,
 						instances,
+						dryRun,
+						format,
 					},
 					Action: func(c *cli.Context) error {
+						if c.Bool("dry-run") {
+							return StartDryRun(
+								c.String("config"),
+								c.String("instances"),
+								c.String("format"),
+							)
+						}
+
 						return Start(c.String("config"), c.String("instances"))
 					},
 				},
@@ -99,8 +211,18 @@ func operationCommands() []*cli.Command {
 					Flags: []cli.Flag{
 						config,
 						group,
+						dryRun,
+						format,
 					},
 					Action: func(c *cli.Context) error {
+						if c.Bool("dry-run") {
+							return StartGroupDryRun(
+								c.String("config"),
+								c.String("group"),
+								c.String("format"),
+							)
+						}
+
 						return StartGroup(c.String("config"), c.String("group"))
 					},
 				},
@@ -146,11 +268,16 @@ func NewCLI() *cli.App {
 	commands = append(commands, packageBuildCommands()...)
 	commands = append(commands, packageInstallCommands()...)
 	commands = append(commands, packageStartCommands()...)
+	commands = append(commands, inspectImageCommands()...)
+	commands = append(commands, installCacheCommands()...)
+	commands = append(commands, clabCommands()...)
 	commands = append(commands, installCommands()...)
 	commands = append(commands, unInstallCommands()...)
 	commands = append(commands, provisionCommands()...)
 	commands = append(commands, deProvisionCommands()...)
 	commands = append(commands, operationCommands()...)
+	commands = append(commands, statusCommands()...)
+	commands = append(commands, consoleMuxCommands()...)
 
 	app := &cli.App{
 		Name:     "boxen",
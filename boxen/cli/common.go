@@ -12,7 +12,7 @@ import (
 // for their sudo password. We do this in the cli module prior to the "spin" starting so that we
 // don't need to interrupt the spin to prompt for passwords. This function should be called prior to
 // any operations that may require elevated permissions, tasks requiring elevated permissions are:
-//     - packagebuild (required to run privileged containers)
+//     - packagebuild (required to run privileged containers, unless using rootless podman)
 //     - install (because qemu may require elevated permissions for kvm/taps/nat interfaces/etc.)
 //     - start (same as install)
 //     - stop (because we launch qemu instances w/ sudo)
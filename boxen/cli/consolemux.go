@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/carlmontanari/boxen/boxen/console"
+	"github.com/carlmontanari/boxen/boxen/instance"
+
+	"github.com/urfave/cli/v2"
+)
+
+func consoleMuxCommands() []*cli.Command {
+	socket := &cli.StringFlag{
+		Name:     "socket",
+		Usage:    "unix socket qemu connects the serial port to",
+		Required: true,
+	}
+
+	listen := &cli.StringFlag{
+		Name:     "listen",
+		Usage:    "tcp address to serve console clients on",
+		Required: true,
+	}
+
+	logFile := &cli.StringFlag{
+		Name:     "log",
+		Usage:    "file to append all console output to",
+		Required: false,
+	}
+
+	ringSize := &cli.IntFlag{
+		Name:     "ring-size",
+		Usage:    "bytes of recent console output replayed to attaching clients",
+		Required: false,
+		Value:    console.DefaultRingSize,
+	}
+
+	writeLease := &cli.IntFlag{
+		Name:     "write-lease",
+		Usage:    "seconds a client holds the console input after its last write",
+		Required: false,
+		Value:    int(console.DefaultWriteLease / time.Second),
+	}
+
+	return []*cli.Command{{
+		Name:   instance.ConsoleMuxCommand,
+		Usage:  "multiplex an instance serial console",
+		Hidden: true,
+		Flags:  []cli.Flag{socket, listen, logFile, ringSize, writeLease},
+		Action: func(c *cli.Context) error {
+			return consoleMux(
+				c.String("socket"),
+				c.String("listen"),
+				c.String("log"),
+				c.Int("ring-size"),
+				c.Int("write-lease"),
+			)
+		},
+	}}
+}
+
+func consoleMux(socket, listen, logFile string, ringSize, writeLease int) error {
+	m, err := console.NewMux(
+		socket,
+		listen,
+		console.WithLogFile(logFile),
+		console.WithRingSize(ringSize),
+		console.WithWriteLease(time.Duration(writeLease)*time.Second),
+	)
+	if err != nil {
+		return err
+	}
+
+	// the multiplexer lives as long as the instance, not as long as the terminal boxen ran in
+	signal.Ignore(syscall.SIGHUP)
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGTERM, os.Interrupt)
+
+	go func() {
+		<-sigs
+
+		_ = m.Close()
+	}()
+
+	return m.Run()
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/carlmontanari/boxen/boxen/boxen"
+	"github.com/carlmontanari/boxen/boxen/instance"
+	"github.com/carlmontanari/boxen/boxen/util"
+
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	dryRunFormatShell   = "shell"
+	dryRunFormatJSON    = "json"
+	dryRunFormatExplain = "explain"
+)
+
+func dryRunFlags() (dryRun *cli.BoolFlag, format *cli.StringFlag) {
+	dryRun = &cli.BoolFlag{
+		Name:     "dry-run",
+		Usage:    "print the qemu launch command instead of launching the instance(s)",
+		Required: false,
+	}
+
+	format = &cli.StringFlag{
+		Name: "format",
+		Usage: fmt.Sprintf(
+			"dry run output format, one of '%s', '%s' or '%s' (annotated with the launch command "+
+				"field and platform hook of each argument)",
+			dryRunFormatShell,
+			dryRunFormatJSON,
+			dryRunFormatExplain,
+		),
+		Required: false,
+		Value:    dryRunFormatShell,
+	}
+
+	return dryRun, format
+}
+
+func printLaunchCmds(cmds []*instance.LaunchCmdExplain, format string) error {
+	switch format {
+	case dryRunFormatShell:
+		for _, c := range cmds {
+			fmt.Println(c.Shell())
+		}
+	case dryRunFormatExplain:
+		for _, c := range cmds {
+			fmt.Printf("# instance '%s'\n%s\n", c.Instance, c.Explain())
+		}
+	case dryRunFormatJSON:
+		out, err := json.MarshalIndent(cmds, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(out))
+	default:
+		return fmt.Errorf(
+			"%w: unknown dry run format '%s', must be one of %s, %s, %s",
+			util.ErrValidationError,
+			format,
+			dryRunFormatShell,
+			dryRunFormatJSON,
+			dryRunFormatExplain,
+		)
+	}
+
+	return nil
+}
+
+// StartDryRun prints the launch commands of the provided instance(s) (provided as comma separated
+// string) without starting them.
+func StartDryRun(config, instances, format string) error {
+	b, err := boxen.NewBoxen(boxen.WithConfig(config))
+	if err != nil {
+		return err
+	}
+
+	var cmds []*instance.LaunchCmdExplain
+
+	for _, name := range strings.Split(instances, ",") {
+		c, err := b.StartDryRun(name)
+		if err != nil {
+			return err
+		}
+
+		cmds = append(cmds, c)
+	}
+
+	return printLaunchCmds(cmds, format)
+}
+
+// StartGroupDryRun prints the launch commands of all local instances in a group without starting
+// them.
+func StartGroupDryRun(config, group, format string) error {
+	b, err := boxen.NewBoxen(boxen.WithConfig(config))
+	if err != nil {
+		return err
+	}
+
+	instances, err := b.GetGroupInstances(group)
+	if err != nil {
+		return err
+	}
+
+	return StartDryRun(config, strings.Join(instances, ","), format)
+}
+
+// InstallDryRun prints the launch command of the installation instance of the disk without
+// installing it.
+func InstallDryRun(config, disk, vendor, platform, version, format string) error {
+	b, err := boxen.NewBoxen(boxen.WithConfig(config))
+	if err != nil {
+		return err
+	}
+
+	c, err := b.InstallDryRun(disk, vendor, platform, version)
+	if err != nil {
+		return err
+	}
+
+	return printLaunchCmds([]*instance.LaunchCmdExplain{c}, format)
+}
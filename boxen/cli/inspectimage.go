@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/carlmontanari/boxen/boxen/boxen"
+
+	"github.com/urfave/cli/v2"
+)
+
+func inspectImageCommands() []*cli.Command {
+	image := &cli.StringFlag{
+		Name:     "image",
+		Usage:    "packaged image to inspect, ex: 'boxen_arista_veos:4.22.1F'",
+		Required: true,
+	}
+
+	labels := &cli.BoolFlag{
+		Name: "labels",
+		Usage: "print the image labels rather than the embedded manifest, this does not require " +
+			"creating a container from the image",
+		Required: false,
+	}
+
+	engine := containerEngineFlag()
+
+	return []*cli.Command{{
+		Name:  "inspect-image",
+		Usage: "print the boxen manifest (or labels) of a packaged image as json",
+		Flags: []cli.Flag{
+			image,
+			labels,
+			engine,
+		},
+		Action: func(c *cli.Context) error {
+			return inspectImage(
+				c.String("image"),
+				c.Bool("labels"),
+				c.String("container-engine"),
+			)
+		},
+	}}
+}
+
+// splitImage splits an image reference into its repository and tag, the tag defaults to "latest".
+// Only a ":" after the last "/" is a tag separator, anything before that is a registry port.
+func splitImage(image string) (repo, tag string) {
+	i := strings.LastIndex(image, ":")
+	if i < 0 || i < strings.LastIndex(image, "/") {
+		return image, "latest"
+	}
+
+	return image[:i], image[i+1:]
+}
+
+func inspectImage(image string, labels bool, engine string) error {
+	b, err := boxen.NewBoxen(boxen.WithContainerEngine(engine))
+	if err != nil {
+		return err
+	}
+
+	repo, tag := splitImage(image)
+
+	m, l, err := b.InspectImage(repo, tag, labels)
+	if err != nil {
+		return err
+	}
+
+	var out []byte
+
+	if labels {
+		out, err = json.MarshalIndent(l, "", "  ")
+	} else {
+		out, err = json.MarshalIndent(m, "", "  ")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(out))
+
+	return nil
+}
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"github.com/carlmontanari/boxen/boxen/boxen"
+	"github.com/carlmontanari/boxen/boxen/config"
 
 	"github.com/urfave/cli/v2"
 )
@@ -16,7 +17,10 @@ func installCommands() []*cli.Command {
 	}
 
 	username, password, _ := customizationFlags()
+	usernameFrom, passwordFrom := credentialSourceFlags()
+	sshKeys := sshKeysFlag()
 	vendor, platform, version := platformTargetFlags()
+	dryRun, format := dryRunFlags()
 
 	return []*cli.Command{{
 		Name:  "install",
@@ -26,16 +30,36 @@ func installCommands() []*cli.Command {
 			disk,
 			username,
 			password,
+			usernameFrom,
+			passwordFrom,
+			sshKeys,
 			vendor,
 			platform,
 			version,
+			dryRun,
+			format,
 		},
 		Action: func(c *cli.Context) error {
+			if c.Bool("dry-run") {
+				return InstallDryRun(
+					c.String("config"),
+					c.String("disk"),
+					c.String("vendor"),
+					c.String("platform"),
+					c.String("version"),
+					c.String("format"),
+				)
+			}
+
+			creds, err := credentialsFromFlags(c)
+			if err != nil {
+				return err
+			}
+
 			return Install(
 				c.String("config"),
 				c.String("disk"),
-				c.String("username"),
-				c.String("password"),
+				creds,
 				c.String("vendor"),
 				c.String("platform"),
 				c.String("version"),
@@ -45,7 +69,11 @@ func installCommands() []*cli.Command {
 }
 
 // Install is the cli entrypoint to install a disk as a local source disk.
-func Install(config, disk, username, password, vendor, platform, version string) error {
+func Install(
+	config, disk string,
+	credentials *config.Credentials,
+	vendor, platform, version string,
+) error {
 	err := checkSudo()
 	if err != nil {
 		return err
@@ -64,6 +92,6 @@ func Install(config, disk, username, password, vendor, platform, version string)
 	return spin(
 		l,
 		li,
-		func() error { return b.Install(disk, username, password, vendor, platform, version) },
+		func() error { return b.Install(disk, credentials, vendor, platform, version) },
 	)
 }
@@ -1,20 +1,39 @@
 package cli
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
 	"github.com/carlmontanari/boxen/boxen/boxen"
+	"github.com/carlmontanari/boxen/boxen/config"
+	"github.com/carlmontanari/boxen/boxen/docker"
+	"github.com/carlmontanari/boxen/boxen/util"
 
 	"github.com/urfave/cli/v2"
 )
 
 func packageBuildCommands() []*cli.Command {
 	username, password, _ := customizationFlags()
+	usernameFrom, passwordFrom := credentialSourceFlags()
+	sshKeys := sshKeysFlag()
 
 	disk := &cli.StringFlag{
 		Name:     "disk",
 		Usage:    "disk image to target, ex: 'vEOS-lab-4.22.1F.vmdk'",
-		Required: true,
+		Required: false,
+	}
+
+	fromSource := &cli.StringFlag{
+		Name: "from-source",
+		Usage: "package an already installed local source disk instead of a disk image, as " +
+			"'<platform type>/<version>', ex: 'arista_veos/4.22.1F', skips the install container",
+		Required: false,
 	}
 
+	config := boxenGlobalFlags()
+
 	repo := &cli.StringFlag{
 		Name:     "repo",
 		Usage:    "name of repository to tag packaged instance to",
@@ -27,6 +46,51 @@ func packageBuildCommands() []*cli.Command {
 		Required: false,
 	}
 
+	engine := containerEngineFlag()
+
+	push := &cli.BoolFlag{
+		Name: "push",
+		Usage: "push the packaged image to its registry (taken from the repo, ex: " +
+			"'localhost:5000/boxen_arista_veos') using the container engine credential store, the " +
+			"image digest is printed as json",
+		Required: false,
+	}
+
+	pushTags := &cli.StringSliceFlag{
+		Name: "push-tag",
+		Usage: fmt.Sprintf(
+			"tag to push the image as, '%s' and '%s' are replaced with the image and boxen "+
+				"version, may be provided multiple times (default: %s)",
+			boxen.PushTagVersion,
+			boxen.PushTagBoxenVersion,
+			strings.Join(boxen.DefaultPushTags(), ", "),
+		),
+		Required: false,
+	}
+
+	installCacheDir := installCacheDirFlag()
+
+	noInstallCache := &cli.BoolFlag{
+		Name: "no-install-cache",
+		Usage: "don't use the install cache, always run the instance installation and don't " +
+			"store the installed disk",
+		Required: false,
+	}
+
+	pushDigestFile := &cli.StringFlag{
+		Name: "push-digest-file",
+		Usage: "file to (also) write the push result json to, handy for pipelines as stdout is " +
+			"shared with the progress output",
+		Required: false,
+	}
+
+	pushRetries := &cli.IntFlag{
+		Name:     "push-retries",
+		Usage:    "number of times to retry a failed push",
+		Required: false,
+		Value:    3, //nolint:gomnd
+	}
+
 	vendor, platform, version := platformTargetFlags()
 
 	return []*cli.Command{{
@@ -34,50 +98,186 @@ func packageBuildCommands() []*cli.Command {
 		Usage: "package a vm instance as a container",
 		Flags: []cli.Flag{
 			disk,
+			fromSource,
+			config,
 			username,
 			password,
+			usernameFrom,
+			passwordFrom,
+			sshKeys,
 			repo,
 			tag,
+			engine,
+			installCacheDir,
+			noInstallCache,
+			push,
+			pushTags,
+			pushRetries,
+			pushDigestFile,
 			vendor,
 			platform,
 			version,
 		},
 		Action: func(c *cli.Context) error {
-			return packageBuild(
-				c.String("disk"),
-				c.String("username"),
-				c.String("password"),
-				c.String("repo"),
-				c.String("tag"),
-				c.String("vendor"),
-				c.String("platform"),
-				c.String("version"),
-			)
+			creds, err := credentialsFromFlags(c)
+			if err != nil {
+				return err
+			}
+
+			var pushConfig *boxen.PushConfig
+
+			if c.Bool("push") {
+				pushConfig = &boxen.PushConfig{
+					Tags:    c.StringSlice("push-tag"),
+					Retries: c.Int("push-retries"),
+				}
+			}
+
+			cacheDir := c.String("install-cache-dir")
+			if c.Bool("no-install-cache") {
+				cacheDir = ""
+			}
+
+			return packageBuild(&packageBuildArgs{
+				disk:            c.String("disk"),
+				fromSource:      c.String("from-source"),
+				config:          c.String("config"),
+				credentials:     creds,
+				repo:            c.String("repo"),
+				tag:             c.String("tag"),
+				engine:          c.String("container-engine"),
+				installCacheDir: cacheDir,
+				vendor:          c.String("vendor"),
+				platform:        c.String("platform"),
+				version:         c.String("version"),
+				push:            pushConfig,
+				pushDigestFile:  c.String("push-digest-file"),
+			})
 		},
 	}}
 }
 
-func packageBuild(disk, username, password, repo, tag, vendor, platform, version string) error {
-	err := checkSudo()
+type packageBuildArgs struct {
+	disk            string
+	fromSource      string
+	config          string
+	credentials     *config.Credentials
+	repo            string
+	tag             string
+	engine          string
+	installCacheDir string
+	vendor          string
+	platform        string
+	version         string
+	push            *boxen.PushConfig
+	pushDigestFile  string
+}
+
+func (a *packageBuildArgs) validate() error {
+	if (a.disk == "") == (a.fromSource == "") {
+		return fmt.Errorf(
+			"%w: exactly one of disk or from-source must be provided",
+			util.ErrValidationError,
+		)
+	}
+
+	if a.fromSource != "" && !util.AllStringVal("", a.vendor, a.platform, a.version) {
+		return fmt.Errorf(
+			"%w: vendor/platform/version can't be provided with from-source, the source disk "+
+				"reference already identifies the platform and version",
+			util.ErrValidationError,
+		)
+	}
+
+	return nil
+}
+
+func packageBuild(a *packageBuildArgs) error {
+	err := a.validate()
 	if err != nil {
 		return err
 	}
 
+	e, err := docker.NewEngine(a.engine)
+	if err != nil {
+		return err
+	}
+
+	// rootless engines (podman) don't need sudo, so don't bother prompting for it, nor does
+	// packaging from source as no (privileged) install container is run
+	if e.PrivilegedSudo() && a.fromSource == "" {
+		err = checkSudo()
+		if err != nil {
+			return err
+		}
+	}
+
 	l, li, err := spinLogger()
 	if err != nil {
 		return err
 	}
 
-	b, err := boxen.NewBoxen(boxen.WithLogger(li))
+	opts := []boxen.Option{
+		boxen.WithLogger(li),
+		boxen.WithContainerEngine(e.Name()),
+		boxen.WithInstallCacheDir(a.installCacheDir),
+	}
+
+	if a.fromSource != "" {
+		opts = append(opts, boxen.WithConfig(a.config))
+	}
+
+	b, err := boxen.NewBoxen(opts...)
 	if err != nil {
 		return err
 	}
 
-	return spin(
+	var res *boxen.PushResult
+
+	err = spin(
 		l,
 		li,
 		func() error {
-			return b.PackageBuild(disk, username, password, repo, tag, vendor, platform, version)
+			if a.fromSource != "" {
+				res, err = b.PackageBuildFromSource(
+					a.fromSource,
+					a.credentials,
+					a.repo,
+					a.tag,
+					a.push,
+				)
+
+				return err
+			}
+
+			res, err = b.PackageBuild(
+				a.disk,
+				a.credentials,
+				a.repo,
+				a.tag,
+				a.vendor,
+				a.platform,
+				a.version,
+				a.push,
+			)
+
+			return err
 		},
 	)
+	if err != nil || res == nil {
+		return err
+	}
+
+	out, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(out))
+
+	if a.pushDigestFile != "" {
+		return os.WriteFile(util.ExpandPath(a.pushDigestFile), append(out, '\n'), util.FilePerms)
+	}
+
+	return nil
 }
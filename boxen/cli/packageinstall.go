@@ -2,9 +2,6 @@ package cli
 
 import (
 	"fmt"
-	"os"
-	"strconv"
-	"strings"
 
 	"github.com/carlmontanari/boxen/boxen/boxen"
 	"github.com/carlmontanari/boxen/boxen/logging"
@@ -25,36 +22,22 @@ func packageInstallCommands() []*cli.Command {
 }
 
 func packageInstall() error {
-	v, ok := os.LookupEnv("BOXEN_LOG_TARGET")
-	if !ok {
-		// for this block and the rest, we actually do want to panic since that will kill the
-		// container, dump the panic output to container logs, and ultimately bubble the error back
-		// up to the cli process that is managing the installation.
-		panic("no boxen log target set, this shouldn't happen!")
-	}
-
-	parts := strings.Split(v, ":")
-	a := parts[0]
-	p, _ := strconv.Atoi(parts[1])
-
-	sl, err := logging.NewSocketSender(a, p)
-	if err != nil {
-		panic("could not setup socket sender")
-	}
-
 	logLevel := util.GetEnvStrOrDefault("BOXEN_LOG_LEVEL", "info")
 
-	li, err := logging.NewInstance(sl.Emit, logging.WithLevel(logLevel))
+	// log messages are emitted (encoded) on the container stdout, the cli process managing the
+	// installation follows that and pumps them into its own logger.
+	li, err := logging.NewInstance(
+		func(o ...interface{}) { fmt.Println(o...) },
+		logging.WithLevel(logLevel),
+	)
 	if err != nil {
 		return err
 	}
 
 	b, err := boxen.NewBoxen(boxen.WithLogger(li), boxen.WithConfig("boxen.yaml"))
 	if err != nil {
-		panic(fmt.Sprintf("error spawning boxen instance: %s\n", err))
+		return fmt.Errorf("error spawning boxen instance: %w", err)
 	}
 
-	err = b.PackageInstall()
-
-	return err
+	return b.PackageInstall()
 }
@@ -1,14 +1,15 @@
 package cli
 
 import (
+	"fmt"
+
 	"github.com/carlmontanari/boxen/boxen/boxen"
+	"github.com/carlmontanari/boxen/boxen/config"
 
 	"github.com/urfave/cli/v2"
 )
 
-func vrnetlabFlags() ( //nolint: gocritic
-	*cli.StringFlag, *cli.BoolFlag, *cli.StringFlag, *cli.StringFlag,
-) {
+func vrnetlabFlags() (*cli.StringFlag, *cli.BoolFlag) {
 	vrConnectionMode := &cli.StringFlag{
 		Name:     "connection-mode",
 		Usage:    "ignored",
@@ -21,60 +22,208 @@ func vrnetlabFlags() ( //nolint: gocritic
 		Required: false,
 	}
 
-	vrVCPU := &cli.StringFlag{
-		Name:     "vcpu",
-		Usage:    "ignored",
-		Required: false,
+	return vrConnectionMode, vrTrace
+}
+
+// packageStartOptionsFlags returns the flags for the package-start runtime options, the vrnetlab
+// "vcpu" and "ram" flags are aliases for the cpu cores and memory overrides.
+func packageStartOptionsFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "start-config",
+			Usage:    "path to the runtime options file",
+			Required: false,
+			EnvVars:  []string{boxen.PackageStartConfigFileEnv},
+		},
+		&cli.BoolFlag{
+			Name:     "print-effective-config",
+			Usage:    "print the effective runtime options and exit",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "startup-config",
+			Usage:    "path to startup-config file if desired",
+			Required: false,
+		},
+		&cli.IntFlag{
+			Name:     "clab-intfs",
+			Usage:    "number of containerlab data plane interfaces to wait for",
+			Required: false,
+		},
+		&cli.IntFlag{
+			Name:     "boot-delay",
+			Usage:    "seconds to wait before booting the instance",
+			Required: false,
+		},
+		&cli.IntFlag{
+			Name:     "timeout-multiplier",
+			Usage:    "multiplier for all boxen timeouts",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "log-level",
+			Usage:    "log level, one of debug, info or critical",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "scrapli-platform-definition",
+			Usage:    "override the scrapli platform definition",
+			Required: false,
+		},
+		&cli.IntFlag{
+			Name:     "memory",
+			Aliases:  []string{"ram"},
+			Usage:    "override the instance memory (MB)",
+			Required: false,
+		},
+		&cli.IntFlag{
+			Name:     "cpu-cores",
+			Aliases:  []string{"vcpu"},
+			Usage:    "override the instance cpu cores",
+			Required: false,
+		},
+		&cli.IntFlag{
+			Name:     "nic-count",
+			Usage:    "override the instance data plane nic count",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "intf-discovery",
+			Usage:    "data plane interface discovery mode, one of clab, map or annotations",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "intf-map",
+			Usage:    "interface to data plane nic mapping, ex: 'net1=1,net2=2'",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "pod-annotations-file",
+			Usage:    "path to the downward api pod annotations file",
+			Required: false,
+		},
+		&cli.BoolFlag{
+			Name:     "vnc",
+			Usage:    "enable the vnc console, served on port 6001",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "vnc-password",
+			Usage:    "vnc password, at most 8 characters",
+			Required: false,
+		},
+		&cli.BoolFlag{
+			Name:     "tcg-fallback",
+			Usage:    "run without hardware acceleration (tcg) if kvm is not available",
+			Required: false,
+		},
 	}
+}
 
-	vrRAM := &cli.StringFlag{
-		Name:     "ram",
-		Usage:    "ignored",
-		Required: false,
+func packageStartOptionsFromFlags(c *cli.Context) (*boxen.PackageStartOptions, error) {
+	intfMap, err := boxen.ParseIntfMap(c.String("intf-map"))
+	if err != nil {
+		return nil, err
+	}
+
+	o := &boxen.PackageStartOptions{
+		ClabIntfs:                 c.Int("clab-intfs"),
+		BootDelay:                 c.Int("boot-delay"),
+		StartupConfig:             c.String("startup-config"),
+		TimeoutMultiplier:         c.Int("timeout-multiplier"),
+		LogLevel:                  c.String("log-level"),
+		ScrapliPlatformDefinition: c.String("scrapli-platform-definition"),
+		Hardware: boxen.PackageStartHardware{
+			Memory:   c.Int("memory"),
+			CPUCores: c.Int("cpu-cores"),
+			NicCount: c.Int("nic-count"),
+		},
+		IntfDiscovery:      c.String("intf-discovery"),
+		IntfMap:            intfMap,
+		PodAnnotationsFile: c.String("pod-annotations-file"),
+		VNCPassword:        c.String("vnc-password"),
 	}
 
-	return vrConnectionMode, vrTrace, vrVCPU, vrRAM
+	// bool flags only override the other sources if they are actually set, i.e. --vnc=false
+	for name, v := range map[string]**bool{
+		"vnc":          &o.VNC,
+		"tcg-fallback": &o.TCGFallback,
+	} {
+		if c.IsSet(name) {
+			b := c.Bool(name)
+			*v = &b
+		}
+	}
+
+	return o, nil
 }
 
 func packageStartCommands() []*cli.Command {
 	// vrnetlab compatibility flags are ignored, but exist so containerlab doesn't require any
 	// changes to work with boxen!
-	vrConnectionMode, vrTrace, vrVCPU, vrRAM := vrnetlabFlags()
+	vrConnectionMode, vrTrace := vrnetlabFlags()
 
 	username, password, hostname := customizationFlags()
-
-	startupConfig := &cli.StringFlag{
-		Name:     "startup-config",
-		Usage:    "path to startup-config file if desired",
-		Required: false,
-	}
+	usernameFrom, passwordFrom := credentialSourceFlags()
+	sshKeys := sshKeysFlag()
 
 	return []*cli.Command{{
 		Name:   "package-start",
 		Usage:  "start a packaged instance",
 		Hidden: true,
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			username,
 			password,
+			usernameFrom,
+			passwordFrom,
+			sshKeys,
 			hostname,
 			vrConnectionMode,
 			vrTrace,
-			vrVCPU,
-			vrRAM,
-			startupConfig,
-		},
+		}, packageStartOptionsFlags()...),
 		Action: func(c *cli.Context) error {
-			return packageStart(
-				c.String("username"),
-				c.String("password"),
-				c.String("hostname"),
-				c.String("startup-config"),
-			)
+			flagOpts, err := packageStartOptionsFromFlags(c)
+			if err != nil {
+				return err
+			}
+
+			opts, err := boxen.ResolvePackageStartOptions(c.String("start-config"), flagOpts)
+			if err != nil {
+				return err
+			}
+
+			if c.Bool("print-effective-config") {
+				out, dumpErr := opts.Dump()
+				if dumpErr != nil {
+					return dumpErr
+				}
+
+				fmt.Print(string(out))
+
+				return nil
+			}
+
+			creds, err := credentialsFromFlags(c)
+			if err != nil {
+				return err
+			}
+
+			return packageStart(creds, c.String("hostname"), opts)
 		},
 	}}
 }
 
-func packageStart(username, password, hostname, config string) error {
+func packageStart(
+	credentials *config.Credentials,
+	hostname string,
+	opts *boxen.PackageStartOptions,
+) error {
+	// the process wide options are read from the env, so export them before anything uses them
+	err := opts.Export()
+	if err != nil {
+		return err
+	}
+
 	l, li, err := spinLogger()
 	if err != nil {
 		return err
@@ -85,5 +234,5 @@ func packageStart(username, password, hostname, config string) error {
 		return err
 	}
 
-	return spin(l, li, func() error { return b.PackageStart(username, password, hostname, config) })
+	return spin(l, li, func() error { return b.PackageStart(credentials, hostname, opts) })
 }
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/carlmontanari/boxen/boxen/boxen"
+
+	"github.com/urfave/cli/v2"
+)
+
+func statusCommands() []*cli.Command {
+	config := boxenGlobalFlags()
+
+	return []*cli.Command{{
+		Name:  "status",
+		Usage: "show the state and console addresses of boxen instances",
+		Flags: []cli.Flag{
+			config,
+		},
+		Action: func(c *cli.Context) error {
+			return Status(c.String("config"))
+		},
+	}}
+}
+
+// orDash returns s, or "-" if s is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
+
+func Status(config string) error {
+	b, err := boxen.NewBoxen(boxen.WithConfig(config))
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:gomnd
+
+	fmt.Fprintln(w, "NAME\tPLATFORM\tSTATE\tPID\tMONITOR\tSERIAL\tVNC")
+
+	for _, s := range b.Status() {
+		pid := ""
+		if s.PID > 0 {
+			pid = strconv.Itoa(s.PID)
+		}
+
+		monitor := ""
+		if s.MonitorPort > 0 {
+			monitor = strconv.Itoa(s.MonitorPort)
+		}
+
+		fmt.Fprintf(
+			w,
+			"%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			s.Name,
+			s.PlatformType,
+			s.State,
+			orDash(pid),
+			orDash(monitor),
+			orDash(strings.Join(s.SerialPorts, ",")),
+			orDash(s.VNC),
+		)
+	}
+
+	return w.Flush()
+}
@@ -28,7 +28,12 @@ func Execute(cmd string, opts ...ExecuteOption) (*Result, error) {
 	}
 
 	if a.sudo {
-		cmd, a.args = newSudoer().updateCmd(cmd, a.args)
+		var err error
+
+		cmd, a.args, err = newSudoer().updateCmd(cmd, a.args)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	r := &Result{
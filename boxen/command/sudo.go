@@ -7,6 +7,8 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/carlmontanari/boxen/boxen/util"
+
 	"golang.org/x/term"
 )
 
@@ -60,32 +62,46 @@ func (s *sudoer) init() {
 	}
 }
 
-func (s *sudoer) getSudoPassword() {
+func (s *sudoer) getSudoPassword() error {
 	fmt.Printf(
 		"privilege escalation required and user is not passwordless sudoer, please enter sudo password: ",
 	)
 
 	userInput, err := term.ReadPassword(int(os.Stdin.Fd()))
 	if err != nil {
-		panic("something went wrong getting password from user, cannot continue")
+		return fmt.Errorf(
+			"%w: failed reading sudo password (%s), run boxen from a terminal, with sudo, or as a "+
+				"passwordless sudoer",
+			util.ErrCommandError,
+			err,
+		)
+	}
+
+	if len(userInput) == 0 {
+		return fmt.Errorf("%w: no sudo password entered", util.ErrCommandError)
 	}
 
 	s.password = string(userInput)
+
+	return nil
 }
 
-func (s *sudoer) updateCmd(cmd string, args []string) (string, []string) { //nolint:gocritic
+func (s *sudoer) updateCmd(cmd string, args []string) (string, []string, error) { //nolint:gocritic
 	if !s.available {
-		return cmd, args
+		return cmd, args, nil
 	}
 
 	if s.passwordless {
 		args = append([]string{cmd}, args...)
 
-		return "sudo", args
+		return "sudo", args, nil
 	}
 
 	if s.password == "" {
-		s.getSudoPassword()
+		err := s.getSudoPassword()
+		if err != nil {
+			return "", nil, err
+		}
 	}
 
 	args = []string{
@@ -93,5 +109,5 @@ func (s *sudoer) updateCmd(cmd string, args []string) (string, []string) { //nol
 		fmt.Sprintf("echo '%s' | sudo -S %s %s", s.password, cmd, strings.Join(args, " ")),
 	}
 
-	return "sh", args
+	return "sh", args, nil
 }
@@ -0,0 +1,57 @@
+package command
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+// withStdin replaces stdin with a pipe holding input for the duration of the test, a pipe is not a
+// terminal so reading a password from it fails.
+func withStdin(t *testing.T, input string) {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed creating pipe: %s", err)
+	}
+
+	_, _ = w.WriteString(input)
+	_ = w.Close()
+
+	orig := os.Stdin
+	os.Stdin = r
+
+	t.Cleanup(func() {
+		os.Stdin = orig
+		_ = r.Close()
+	})
+}
+
+func TestGetSudoPasswordNotATerminal(t *testing.T) {
+	withStdin(t, "password\n")
+
+	s := &sudoer{available: true}
+
+	err := s.getSudoPassword()
+	if !errors.Is(err, util.ErrCommandError) {
+		t.Fatalf("expected command error, got: %v", err)
+	}
+
+	if s.password != "" {
+		t.Fatalf("expected no password to be stored, got: %q", s.password)
+	}
+}
+
+func TestUpdateCmdSudoPasswordError(t *testing.T) {
+	withStdin(t, "")
+
+	s := &sudoer{available: true}
+
+	_, _, err := s.updateCmd("ls", []string{"-la"})
+	if !errors.Is(err, util.ErrCommandError) {
+		t.Fatalf("expected command error, got: %v", err)
+	}
+}
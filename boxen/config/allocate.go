@@ -36,6 +36,20 @@ func (c *Config) AllocatedSerialPorts() []int {
 	return allocatedSerialPorts
 }
 
+// AllocatedVNCPorts returns a slice of integers of all currently allocated vnc port IDs in the
+// local boxen config.
+func (c *Config) AllocatedVNCPorts() []int {
+	allocatedVNCPorts := make([]int, 0)
+
+	for _, data := range c.Instances {
+		if data.Hardware.VNCPort != 0 {
+			allocatedVNCPorts = append(allocatedVNCPorts, data.Hardware.VNCPort)
+		}
+	}
+
+	return allocatedVNCPorts
+}
+
 // AllocatedHostSideNatPorts returns a slice of integers of all currently allocated "host side" nat
 // ports in the local boxen config. These ports are the ephemeral range ports that get applied to
 // the qemu hostfwd nat directives for the local virtual machines.
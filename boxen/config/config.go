@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 
 	"github.com/carlmontanari/boxen/boxen/util"
@@ -21,6 +22,12 @@ const (
 	SERIALPORTLOW = 5001
 	// SERIALPORTHI is the last possible serial port ID.
 	SERIALPORTHI = 5999
+	// VNCPORTBASE is the starting port ID for vnc ports.
+	VNCPORTBASE = 6000
+	// VNCPORTLOW is the first possible vnc port ID.
+	VNCPORTLOW = 6001
+	// VNCPORTHI is the last possible vnc port ID.
+	VNCPORTHI = 6999
 	// MGMTNATLOW is the first possible "management NAT" port ID.
 	MGMTNATLOW = 30001
 	// MGMTNATHI is the last possible "management NAT" port ID.
@@ -190,6 +197,79 @@ func (c *Config) validateListenPorts() error {
 	return nil
 }
 
+func (c *Config) validateVNCPorts() error {
+	allocatedVNCPorts := c.AllocatedVNCPorts()
+
+	uniqueVNCPorts := util.IntSliceUniqify(allocatedVNCPorts)
+
+	if len(allocatedVNCPorts) != len(uniqueVNCPorts) {
+		return fmt.Errorf("%w: one or more overlapping vnc ports", util.ErrValidationError)
+	}
+
+	for _, port := range allocatedVNCPorts {
+		if port < VNCPORTLOW || port > VNCPORTHI {
+			return fmt.Errorf(
+				"%w: vnc port %d is outside of the vnc port range %d-%d",
+				util.ErrValidationError,
+				port,
+				VNCPORTLOW,
+				VNCPORTHI,
+			)
+		}
+	}
+
+	for name, i := range c.Instances {
+		if i.Advanced == nil {
+			continue
+		}
+
+		err := i.Advanced.VNC.Validate()
+		if err != nil {
+			return fmt.Errorf("%w (instance '%s')", err, name)
+		}
+
+		if i.Advanced.VNC != nil && strings.HasPrefix(i.Advanced.Display, "vnc") {
+			return fmt.Errorf(
+				"%w: instance '%s' sets both a vnc display and the managed vnc console",
+				util.ErrValidationError,
+				name,
+			)
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) validateConsole() error {
+	if c.Options == nil {
+		return nil
+	}
+
+	return c.Options.Console.Validate()
+}
+
+func (c *Config) validateCredentials() error {
+	if c.Options != nil && c.Options.Credentials != nil {
+		err := c.Options.Credentials.Validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	for name, i := range c.Instances {
+		if i.Credentials == nil {
+			continue
+		}
+
+		err := i.Credentials.Validate()
+		if err != nil {
+			return fmt.Errorf("%w (instance '%s')", err, name)
+		}
+	}
+
+	return nil
+}
+
 // Validate provides basic configuration validation -- checking for things like duplicate device IDs
 // and duplicate allocated ports.
 func (c *Config) Validate() error {
@@ -197,8 +277,11 @@ func (c *Config) Validate() error {
 		c.validateIDs,
 		c.validateMonitorPorts,
 		c.validateSerialPorts,
+		c.validateVNCPorts,
 		c.validateNATPorts,
-		c.validateListenPorts} {
+		c.validateListenPorts,
+		c.validateCredentials,
+		c.validateConsole} {
 		err := f()
 		if err != nil {
 			return err
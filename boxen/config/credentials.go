@@ -0,0 +1,369 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/carlmontanari/boxen/boxen/command"
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+const (
+	credentialSourceEnv     = "env"
+	credentialSourceFile    = "file"
+	credentialSourceCommand = "command"
+	credentialSourceKeyring = "keyring"
+
+	// secretToolCmd is the libsecret cli, used to look up secrets from the os keyring via the
+	// freedesktop Secret Service D-Bus API (gnome-keyring, kwallet, keepassxc, etc.).
+	secretToolCmd = "secret-tool"
+)
+
+// CredentialSource is a reference to where a credential value should be fetched from. Exactly one
+// of the fields should be set. Credential sources are only resolved when the value is actually
+// needed, and the resolved value is never stored in the config.
+type CredentialSource struct {
+	// Env is the name of an environment variable holding the value.
+	Env string `yaml:"env,omitempty"`
+	// File is the path to a file holding the value (i.e. a docker/kubernetes secret), trailing
+	// newlines are stripped.
+	File string `yaml:"file,omitempty"`
+	// Command is a command (executed via "sh -c") that prints the value to stdout, i.e. a password
+	// manager cli, trailing newlines are stripped.
+	Command string `yaml:"command,omitempty"`
+	// Keyring is a set of attributes to look up in the os keyring via the Secret Service API.
+	Keyring map[string]string `yaml:"keyring,omitempty"`
+}
+
+// ParseCredentialSource parses a credential source from a string of the form "env:NAME",
+// "file:PATH", "command:COMMAND" or "keyring:ATTR=VALUE[,ATTR=VALUE]".
+func ParseCredentialSource(s string) (*CredentialSource, error) {
+	parts := strings.SplitN(s, ":", 2) //nolint:gomnd
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, fmt.Errorf(
+			"%w: credential source '%s' is not of the form 'kind:reference'",
+			util.ErrValidationError,
+			s,
+		)
+	}
+
+	kind, ref := parts[0], parts[1]
+
+	src := &CredentialSource{}
+
+	switch kind {
+	case credentialSourceEnv:
+		src.Env = ref
+	case credentialSourceFile:
+		src.File = ref
+	case credentialSourceCommand:
+		src.Command = ref
+	case credentialSourceKeyring:
+		src.Keyring = map[string]string{}
+
+		for _, attr := range strings.Split(ref, ",") {
+			kv := strings.SplitN(attr, "=", 2) //nolint:gomnd
+			if len(kv) != 2 || kv[0] == "" {
+				return nil, fmt.Errorf(
+					"%w: keyring attribute '%s' is not of the form 'attr=value'",
+					util.ErrValidationError,
+					attr,
+				)
+			}
+
+			src.Keyring[kv[0]] = kv[1]
+		}
+	default:
+		return nil, fmt.Errorf(
+			"%w: unknown credential source kind '%s', must be one of %s, %s, %s, %s",
+			util.ErrValidationError,
+			kind,
+			credentialSourceEnv,
+			credentialSourceFile,
+			credentialSourceCommand,
+			credentialSourceKeyring,
+		)
+	}
+
+	return src, nil
+}
+
+// Kind returns the kind of the credential source (env, file, command, or keyring).
+func (s *CredentialSource) Kind() string {
+	switch {
+	case s.Env != "":
+		return credentialSourceEnv
+	case s.File != "":
+		return credentialSourceFile
+	case s.Command != "":
+		return credentialSourceCommand
+	case len(s.Keyring) > 0:
+		return credentialSourceKeyring
+	}
+
+	return ""
+}
+
+// String returns the credential source in the same "kind:reference" form that
+// ParseCredentialSource accepts.
+func (s *CredentialSource) String() string {
+	switch s.Kind() {
+	case credentialSourceEnv:
+		return fmt.Sprintf("%s:%s", credentialSourceEnv, s.Env)
+	case credentialSourceFile:
+		return fmt.Sprintf("%s:%s", credentialSourceFile, s.File)
+	case credentialSourceCommand:
+		return fmt.Sprintf("%s:%s", credentialSourceCommand, s.Command)
+	case credentialSourceKeyring:
+		attrs := make([]string, 0, len(s.Keyring))
+
+		for k, v := range s.Keyring {
+			attrs = append(attrs, fmt.Sprintf("%s=%s", k, v))
+		}
+
+		sort.Strings(attrs)
+
+		return fmt.Sprintf("%s:%s", credentialSourceKeyring, strings.Join(attrs, ","))
+	}
+
+	return ""
+}
+
+// Validate checks that exactly one kind of source is set.
+func (s *CredentialSource) Validate() error {
+	set := 0
+
+	for _, b := range []bool{s.Env != "", s.File != "", s.Command != "", len(s.Keyring) > 0} {
+		if b {
+			set++
+		}
+	}
+
+	if set != 1 {
+		return fmt.Errorf(
+			"%w: credential source must have exactly one of env, file, command, or keyring set",
+			util.ErrValidationError,
+		)
+	}
+
+	return nil
+}
+
+func (s *CredentialSource) resolveCommand(cmd string, args []string) (string, error) {
+	r, err := command.Execute(cmd, command.WithArgs(args), command.WithWait(true))
+	if err != nil {
+		return "", fmt.Errorf(
+			"%w: failed resolving %s credential source: %s",
+			util.ErrCommandError,
+			s.Kind(),
+			err,
+		)
+	}
+
+	out, err := r.ReadStdout()
+	if err != nil {
+		return "", err
+	}
+
+	// the command result stdout buffer may be padded with null bytes, strip those too
+	return strings.TrimRight(string(out), "\x00\r\n"), nil
+}
+
+// Resolve fetches the actual value the credential source refers to.
+func (s *CredentialSource) Resolve() (string, error) {
+	err := s.Validate()
+	if err != nil {
+		return "", err
+	}
+
+	switch s.Kind() {
+	case credentialSourceEnv:
+		v, ok := os.LookupEnv(s.Env)
+		if !ok {
+			return "", fmt.Errorf(
+				"%w: credential source env var '%s' is not set",
+				util.ErrValidationError,
+				s.Env,
+			)
+		}
+
+		return v, nil
+	case credentialSourceFile:
+		f, err := util.ResolveFile(s.File)
+		if err != nil {
+			return "", err
+		}
+
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return "", err
+		}
+
+		return strings.TrimRight(string(b), "\r\n"), nil
+	case credentialSourceCommand:
+		return s.resolveCommand("sh", []string{"-c", s.Command})
+	default:
+		attrs := make([]string, 0, len(s.Keyring))
+
+		for k := range s.Keyring {
+			attrs = append(attrs, k)
+		}
+
+		sort.Strings(attrs)
+
+		args := []string{"lookup"}
+
+		for _, k := range attrs {
+			args = append(args, k, s.Keyring[k])
+		}
+
+		v, err := s.resolveCommand(secretToolCmd, args)
+		if err != nil {
+			return "", err
+		}
+
+		if v == "" {
+			return "", fmt.Errorf(
+				"%w: no secret found in keyring for attributes %v",
+				util.ErrValidationError,
+				s.Keyring,
+			)
+		}
+
+		return v, nil
+	}
+}
+
+// Validate checks any credential sources and ssh keys in the Credentials object are valid -- it
+// does *not* resolve the credential sources though.
+func (c *Credentials) Validate() error {
+	_, err := util.ParseSSHPublicKeys(c.SSHKeys)
+	if err != nil {
+		return err
+	}
+
+	for _, src := range []*CredentialSource{c.UsernameFrom, c.PasswordFrom} {
+		if src == nil {
+			continue
+		}
+
+		err = src.Validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Resolve returns a copy of the Credentials object with any credential sources resolved to their
+// actual values. The returned object is what should be handed to anything that actually needs to
+// use the credentials, it should never be stored back in the config!
+func (c *Credentials) Resolve() (*Credentials, error) {
+	r := &Credentials{
+		Username: c.Username,
+		Password: c.Password,
+		SSHKeys:  c.SSHKeys,
+	}
+
+	var err error
+
+	if c.UsernameFrom != nil {
+		r.Username, err = c.UsernameFrom.Resolve()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.PasswordFrom != nil {
+		r.Password, err = c.PasswordFrom.Resolve()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// Merge updates the Credentials object with any values set in the Credentials object o. A literal
+// value replaces a source (and vice versa), any ssh keys in o replace the existing ssh keys.
+func (c *Credentials) Merge(o *Credentials) {
+	if o == nil {
+		return
+	}
+
+	if o.Username != "" {
+		c.Username = o.Username
+		c.UsernameFrom = nil
+	}
+
+	if o.UsernameFrom != nil {
+		c.Username = ""
+		c.UsernameFrom = o.UsernameFrom
+	}
+
+	if o.Password != "" {
+		c.Password = o.Password
+		c.PasswordFrom = nil
+	}
+
+	if o.PasswordFrom != nil {
+		c.Password = ""
+		c.PasswordFrom = o.PasswordFrom
+	}
+
+	if len(o.SSHKeys) > 0 {
+		c.SSHKeys = o.SSHKeys
+	}
+}
+
+// References returns a copy of the Credentials object that is safe to store in the config. The
+// username, credential sources and ssh keys are kept, a literal password is only kept if it is the
+// boxen default password (which is no secret), any other literal password is dropped.
+func (c *Credentials) References() *Credentials {
+	r := &Credentials{
+		Username:     c.Username,
+		UsernameFrom: c.UsernameFrom,
+		PasswordFrom: c.PasswordFrom,
+		SSHKeys:      c.SSHKeys,
+	}
+
+	if c.PasswordFrom == nil && c.Password == NewDefaultCredentials().Password {
+		r.Password = c.Password
+	}
+
+	return r
+}
+
+// Sources returns all credential sources set on the Credentials object.
+func (c *Credentials) Sources() []*CredentialSource {
+	var srcs []*CredentialSource
+
+	for _, src := range []*CredentialSource{c.UsernameFrom, c.PasswordFrom} {
+		if src != nil {
+			srcs = append(srcs, src)
+		}
+	}
+
+	return srcs
+}
+
+// MarshalYAML ensures that if a credential source is set, no (possibly resolved) literal value is
+// ever written alongside of it.
+func (c *Credentials) MarshalYAML() (interface{}, error) {
+	type credentials Credentials
+
+	out := credentials(*c)
+
+	if out.UsernameFrom != nil {
+		out.Username = ""
+	}
+
+	if out.PasswordFrom != nil {
+		out.Password = ""
+	}
+
+	return &out, nil
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+const (
+	// DiskBusIDE attaches a disk to the (default) ide controller.
+	DiskBusIDE = "ide"
+	// DiskBusVirtioBlk attaches a disk as a virtio-blk pci device.
+	DiskBusVirtioBlk = "virtio-blk"
+	// DiskBusVirtioSCSI attaches a disk to a virtio-scsi controller.
+	DiskBusVirtioSCSI = "virtio-scsi"
+	// DiskBusSATA attaches a disk to an ahci (sata) controller.
+	DiskBusSATA = "sata"
+
+	// DiskFormatQcow2 is the default disk format.
+	DiskFormatQcow2 = "qcow2"
+
+	diskAIONative = "native"
+)
+
+// Disk describes a disk attached to an instance. A disk without a file is the instance disk,
+// relative files are relative to the directory of the instance disk.
+type Disk struct {
+	File      string `yaml:"file,omitempty"`
+	Format    string `yaml:"format,omitempty"`
+	Bus       string `yaml:"bus,omitempty"`
+	Cache     string `yaml:"cache,omitempty"`
+	AIO       string `yaml:"aio,omitempty"`
+	Discard   bool   `yaml:"discard,omitempty"`
+	ReadOnly  bool   `yaml:"readonly,omitempty"`
+	BootIndex int    `yaml:"bootindex,omitempty"`
+}
+
+// Validate checks the disk settings are valid (as far as qemu is concerned).
+func (d *Disk) Validate() error {
+	bus := d.Bus
+	if bus == "" {
+		bus = DiskBusIDE
+	}
+
+	if !util.StringSliceContains(
+		bus,
+		[]string{DiskBusIDE, DiskBusVirtioBlk, DiskBusVirtioSCSI, DiskBusSATA},
+	) {
+		return fmt.Errorf(
+			"%w: unknown disk bus '%s', must be one of %s, %s, %s, %s",
+			util.ErrValidationError,
+			d.Bus,
+			DiskBusIDE,
+			DiskBusVirtioBlk,
+			DiskBusVirtioSCSI,
+			DiskBusSATA,
+		)
+	}
+
+	if d.Cache != "" &&
+		!util.StringSliceContains(
+			d.Cache,
+			[]string{"none", "writeback", "writethrough", "directsync", "unsafe"},
+		) {
+		return fmt.Errorf("%w: unknown disk cache mode '%s'", util.ErrValidationError, d.Cache)
+	}
+
+	if d.AIO != "" &&
+		!util.StringSliceContains(d.AIO, []string{"threads", diskAIONative, "io_uring"}) {
+		return fmt.Errorf("%w: unknown disk aio mode '%s'", util.ErrValidationError, d.AIO)
+	}
+
+	// native aio requires O_DIRECT, which only the none and directsync cache modes use
+	if d.AIO == diskAIONative && d.Cache != "none" && d.Cache != "directsync" {
+		return fmt.Errorf(
+			"%w: disk aio mode 'native' requires cache mode 'none' or 'directsync'",
+			util.ErrValidationError,
+		)
+	}
+
+	if d.ReadOnly && (bus == DiskBusIDE || bus == DiskBusSATA) {
+		return fmt.Errorf(
+			"%w: ide and sata disks can not be readonly, use a virtio bus",
+			util.ErrValidationError,
+		)
+	}
+
+	if d.BootIndex < 0 {
+		return fmt.Errorf("%w: disk bootindex must not be negative", util.ErrValidationError)
+	}
+
+	return nil
+}
+
+// ValidateDisks validates all disks, and that at most one of them is the instance disk.
+func ValidateDisks(disks []*Disk) error {
+	instanceDisks := 0
+
+	for _, d := range disks {
+		err := d.Validate()
+		if err != nil {
+			return err
+		}
+
+		if d.File == "" {
+			instanceDisks++
+		}
+	}
+
+	if instanceDisks > 1 {
+		return fmt.Errorf(
+			"%w: only one disk may be the instance disk (have no file)",
+			util.ErrValidationError,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+const (
+	// FirmwareBIOS boots the instance with the qemu default (seabios) firmware.
+	FirmwareBIOS = "bios"
+	// FirmwareUEFI boots the instance with OVMF (uefi) firmware.
+	FirmwareUEFI = "uefi"
+
+	// DefaultOVMFCode is the OVMF code (read only firmware) file used if none is set.
+	DefaultOVMFCode = "/usr/share/OVMF/OVMF_CODE.fd"
+	// DefaultOVMFVars is the OVMF vars (nvram) template file used if none is set.
+	DefaultOVMFVars = "/usr/share/OVMF/OVMF_VARS.fd"
+	// DefaultOVMFSecureBootCode is the OVMF code file used if none is set and secure boot is on.
+	DefaultOVMFSecureBootCode = "/usr/share/OVMF/OVMF_CODE.secboot.fd"
+	// DefaultOVMFSecureBootVars is the OVMF vars template used if none is set and secure boot is
+	// on, this template has the microsoft keys enrolled.
+	DefaultOVMFSecureBootVars = "/usr/share/OVMF/OVMF_VARS.ms.fd"
+
+	// FirmwareVarsFile is the name of the per instance copy of the OVMF vars, it lives next to the
+	// instance disk and is carried from the installed source disk to the instances like any other
+	// run file.
+	FirmwareVarsFile = "OVMF_VARS.fd"
+
+	machineQ35 = "q35"
+)
+
+// Firmware is the firmware an instance boots with.
+type Firmware struct {
+	// Type is one of FirmwareBIOS (the default) or FirmwareUEFI.
+	Type string `yaml:"type,omitempty"`
+	// Code is the OVMF code file, DefaultOVMFCode (or DefaultOVMFSecureBootCode) if not set.
+	Code string `yaml:"code,omitempty"`
+	// Vars is the OVMF vars template copied for each instance, DefaultOVMFVars (or
+	// DefaultOVMFSecureBootVars) if not set.
+	Vars string `yaml:"vars,omitempty"`
+	// SecureBoot enables secure boot, this requires a q35 machine type.
+	SecureBoot bool `yaml:"secure_boot,omitempty"`
+}
+
+// IsUEFI returns true if the firmware is uefi.
+func (f *Firmware) IsUEFI() bool {
+	return f != nil && f.Type == FirmwareUEFI
+}
+
+// CodeFile returns the OVMF code file of the firmware.
+func (f *Firmware) CodeFile() string {
+	switch {
+	case f.Code != "":
+		return f.Code
+	case f.SecureBoot:
+		return DefaultOVMFSecureBootCode
+	default:
+		return DefaultOVMFCode
+	}
+}
+
+// VarsTemplate returns the OVMF vars template file of the firmware.
+func (f *Firmware) VarsTemplate() string {
+	switch {
+	case f.Vars != "":
+		return f.Vars
+	case f.SecureBoot:
+		return DefaultOVMFSecureBootVars
+	default:
+		return DefaultOVMFVars
+	}
+}
+
+// Validate checks the firmware settings are valid for the machine type machine.
+func (f *Firmware) Validate(machine string) error {
+	if f == nil {
+		return nil
+	}
+
+	if f.Type != "" && f.Type != FirmwareBIOS && f.Type != FirmwareUEFI {
+		return fmt.Errorf(
+			"%w: unknown firmware type '%s', must be one of %s, %s",
+			util.ErrValidationError,
+			f.Type,
+			FirmwareBIOS,
+			FirmwareUEFI,
+		)
+	}
+
+	if !f.IsUEFI() {
+		if f.SecureBoot || f.Code != "" || f.Vars != "" {
+			return fmt.Errorf(
+				"%w: firmware code, vars and secure_boot require firmware type '%s'",
+				util.ErrValidationError,
+				FirmwareUEFI,
+			)
+		}
+
+		return nil
+	}
+
+	if f.SecureBoot && machine != "" && !strings.Contains(machine, machineQ35) {
+		return fmt.Errorf(
+			"%w: secure boot requires a '%s' machine type, got '%s'",
+			util.ErrValidationError,
+			machineQ35,
+			machine,
+		)
+	}
+
+	return nil
+}
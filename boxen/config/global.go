@@ -1,14 +1,36 @@
 package config
 
+import (
+	"fmt"
+	"net"
+
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+const (
+	// DefaultConsoleListenAddress is the address serial ports are served on if not configured.
+	DefaultConsoleListenAddress = "127.0.0.1"
+	// PackageConsoleListenAddress is the address serial ports of packaged instances are served on
+	// if not configured.
+	PackageConsoleListenAddress = "0.0.0.0"
+)
+
 type GlobalOptions struct {
 	Credentials *Credentials `yaml:"credentials,omitempty"`
 	Qemu        *Qemu        `yaml:"qemu,omitempty"`
 	Build       *Build       `yaml:"build,omitempty"`
+	Console     *Console     `yaml:"console,omitempty"`
 }
 
 type Credentials struct {
 	Username string `yaml:"username,omitempty"`
 	Password string `yaml:"password,omitempty"`
+	// UsernameFrom and PasswordFrom are references to where the username/password should be fetched
+	// from at use time, if set these take precedence over the literal Username/Password.
+	UsernameFrom *CredentialSource `yaml:"username_from,omitempty"`
+	PasswordFrom *CredentialSource `yaml:"password_from,omitempty"`
+	// SSHKeys is a list of openssh public keys ("type key [comment]") to authorize for the user.
+	SSHKeys []string `yaml:"ssh_keys,omitempty"`
 }
 
 // NewDefaultCredentials returns a Credentials object with the boxen default creds set.
@@ -24,9 +46,59 @@ type Qemu struct {
 	Binary       string   `yaml:"binary,omitempty"`
 	// UseThickDisks copies full disks instead of creating qemu disks with a backing chain
 	UseThickDisks bool `yaml:"use_thick_disks,omitempty"`
+	// TCGFallback launches instances with tcg (software emulation) rather than failing when none
+	// of the preferred acceleration types of the instance are available.
+	TCGFallback bool `yaml:"tcg_fallback,omitempty"`
 }
 
 type Build struct {
 	InstancePath string `yaml:"instance_path,omitempty"`
 	SourcePath   string `yaml:"source_path,omitempty"`
 }
+
+// Console holds the settings of the serial console multiplexers boxen runs for the instances.
+type Console struct {
+	// ListenAddress is the address the serial ports are served on, 127.0.0.1 if not set (0.0.0.0
+	// for packaged instances, the container is the boundary there).
+	ListenAddress string `yaml:"listen_address,omitempty"`
+	// RingSize is the number of bytes of recent console output replayed to attaching clients.
+	RingSize int `yaml:"ring_size,omitempty"`
+	// WriteLease is the number of seconds a client holds the console input after its last write.
+	WriteLease int `yaml:"write_lease,omitempty"`
+	// Binary is the boxen binary the console multiplexers are run with, if not set the running
+	// binary is used if it is boxen, otherwise boxen is looked up in the path.
+	Binary string `yaml:"binary,omitempty"`
+}
+
+// Address returns the address serial ports are served on, c may be nil.
+func (c *Console) Address() string {
+	if c == nil || c.ListenAddress == "" {
+		return DefaultConsoleListenAddress
+	}
+
+	return c.ListenAddress
+}
+
+// Validate checks the console settings, c may be nil.
+func (c *Console) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	if c.ListenAddress != "" && net.ParseIP(c.ListenAddress) == nil {
+		return fmt.Errorf(
+			"%w: console listen address '%s' is not an ip address",
+			util.ErrValidationError,
+			c.ListenAddress,
+		)
+	}
+
+	if c.RingSize < 0 || c.WriteLease < 0 {
+		return fmt.Errorf(
+			"%w: console ring size and write lease must not be negative",
+			util.ErrValidationError,
+		)
+	}
+
+	return nil
+}
@@ -7,6 +7,7 @@ type ProfileHardware struct {
 	NicType         string   `yaml:"nic_type,omitempty"`
 	NicCount        int      `yaml:"nic_count,omitempty"`
 	NicPerBus       int      `yaml:"nic_per_bus,omitempty"`
+	Disks           []*Disk  `yaml:"disks,omitempty"`
 }
 
 func (p *ProfileHardware) ToHardware() *Hardware {
@@ -19,6 +20,7 @@ func (p *ProfileHardware) ToHardware() *Hardware {
 		NicType:      p.NicType,
 		NicCount:     p.NicCount,
 		NicPerBus:    p.NicPerBus,
+		Disks:        p.Disks,
 	}
 }
 
@@ -27,15 +29,29 @@ type Hardware struct {
 	Acceleration []string `yaml:"acceleration,omitempty"`
 	MonitorPort  int      `yaml:"monitor_port,omitempty"`
 	SerialPorts  []int    `yaml:"serial_ports,omitempty"`
-	NicType      string   `yaml:"nic_type,omitempty"`
-	NicCount     int      `yaml:"nic_count,omitempty"`
-	NicPerBus    int      `yaml:"nic_per_bus,omitempty"`
+	// VNCPort is the allocated vnc port, only set for instances with vnc enabled.
+	VNCPort   int     `yaml:"vnc_port,omitempty"`
+	NicType   string  `yaml:"nic_type,omitempty"`
+	NicCount  int     `yaml:"nic_count,omitempty"`
+	NicPerBus int     `yaml:"nic_per_bus,omitempty"`
+	Disks     []*Disk `yaml:"disks,omitempty"`
 }
 
 type Advanced struct {
-	Display string       `yaml:"display,omitempty"`
-	Machine string       `yaml:"machine,omitempty"`
-	CPU     *AdvancedCPU `yaml:"cpu,omitempty"`
+	Display  string          `yaml:"display,omitempty"`
+	Machine  string          `yaml:"machine,omitempty"`
+	CPU      *AdvancedCPU    `yaml:"cpu,omitempty"`
+	Memory   *AdvancedMemory `yaml:"memory,omitempty"`
+	Firmware *Firmware       `yaml:"firmware,omitempty"`
+	VNC      *VNC            `yaml:"vnc,omitempty"`
+	// QemuBinary overrides the qemu binary (path or name in the path) of the global qemu options
+	// for this instance.
+	QemuBinary string `yaml:"qemu_binary,omitempty"`
+}
+
+// VNCEnabled returns true if the managed vnc console is enabled, a may be nil.
+func (a *Advanced) VNCEnabled() bool {
+	return a != nil && a.VNC != nil
 }
 
 type AdvancedCPU struct {
@@ -43,4 +59,9 @@ type AdvancedCPU struct {
 	Cores     int    `yaml:"cores,omitempty"`
 	Threads   int    `yaml:"threads,omitempty"`
 	Sockets   int    `yaml:"sockets,omitempty"`
+	// Pinning is the host cpu list (i.e. "2-5,8") the vCPUs are pinned to, vCPU n is pinned to the
+	// nth cpu of the list (wrapping around if there are more vCPUs than cpus).
+	Pinning string `yaml:"pinning,omitempty"`
+	// PinningMode is one of PinningModeTaskset (the default) or PinningModeCpuset.
+	PinningMode string `yaml:"pinning_mode,omitempty"`
 }
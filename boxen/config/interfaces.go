@@ -25,6 +25,9 @@ type Bridge struct{}
 
 type DataPlaneIntf struct {
 	SocketConnectMap map[int]*SocketConnectPair `yaml:"socket_connect_map,omitempty"`
+	// HostIntfs maps data plane nic ids to the (container) host interface the nic is tapped to, by
+	// default nic n is tapped to "eth<n>" if it exists.
+	HostIntfs map[int]string `yaml:"host_interfaces,omitempty"`
 }
 
 type SocketConnectPair struct {
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+const (
+	// PinningModeTaskset pins each vCPU thread to its host cpu with taskset once the instance is
+	// launched.
+	PinningModeTaskset = "taskset"
+	// PinningModeCpuset confines the whole qemu process to the pinned host cpus (and the memory of
+	// the numa node, if set) with a cgroup cpuset, this requires running boxen as root.
+	PinningModeCpuset = "cpuset"
+
+	// DefaultHugepagesPath is the hugetlbfs mount used for hugepage backed memory if none is set.
+	DefaultHugepagesPath = "/dev/hugepages"
+)
+
+// AdvancedMemory holds the memory placement settings of an instance.
+type AdvancedMemory struct {
+	// Hugepages backs the instance memory with (preallocated) hugepages.
+	Hugepages bool `yaml:"hugepages,omitempty"`
+	// HugepagesPath is the hugetlbfs mount, DefaultHugepagesPath if not set.
+	HugepagesPath string `yaml:"hugepages_path,omitempty"`
+	// NumaNode binds the instance memory to the host numa node.
+	NumaNode *int `yaml:"numa_node,omitempty"`
+}
+
+// HugepagesDir returns the hugetlbfs mount used for hugepage backed memory.
+func (m *AdvancedMemory) HugepagesDir() string {
+	if m.HugepagesPath != "" {
+		return m.HugepagesPath
+	}
+
+	return DefaultHugepagesPath
+}
+
+// Validate checks the memory settings are valid.
+func (m *AdvancedMemory) Validate() error {
+	if m == nil {
+		return nil
+	}
+
+	if m.NumaNode != nil && *m.NumaNode < 0 {
+		return fmt.Errorf("%w: numa_node must not be negative", util.ErrValidationError)
+	}
+
+	if m.HugepagesPath != "" && !m.Hugepages {
+		return fmt.Errorf("%w: hugepages_path requires hugepages", util.ErrValidationError)
+	}
+
+	return nil
+}
+
+// ParseCPUList parses a cpu list in the kernel list format, i.e. "0-3,8,10-11", the cpus are
+// returned in the order they are listed.
+func ParseCPUList(s string) ([]int, error) {
+	var cpus []int
+
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		bounds := strings.SplitN(part, "-", 2) //nolint:gomnd
+
+		first, err := strconv.Atoi(bounds[0])
+		if err != nil || first < 0 {
+			return nil, fmt.Errorf("%w: invalid cpu list entry '%s'", util.ErrValidationError, part)
+		}
+
+		last := first
+
+		if len(bounds) == 2 { //nolint:gomnd
+			last, err = strconv.Atoi(bounds[1])
+			if err != nil || last < first {
+				return nil, fmt.Errorf(
+					"%w: invalid cpu list entry '%s'",
+					util.ErrValidationError,
+					part,
+				)
+			}
+		}
+
+		for cpu := first; cpu <= last; cpu++ {
+			cpus = append(cpus, cpu)
+		}
+	}
+
+	return cpus, nil
+}
+
+// PinnedCPUs returns the host cpus the vCPUs are pinned to, nil if pinning is not set.
+func (c *AdvancedCPU) PinnedCPUs() ([]int, error) {
+	if c == nil || c.Pinning == "" {
+		if c != nil && c.PinningMode != "" {
+			return nil, fmt.Errorf("%w: pinning_mode requires pinning", util.ErrValidationError)
+		}
+
+		return nil, nil
+	}
+
+	if c.PinningMode != "" && c.PinningMode != PinningModeTaskset &&
+		c.PinningMode != PinningModeCpuset {
+		return nil, fmt.Errorf(
+			"%w: unknown pinning_mode '%s', must be one of %s, %s",
+			util.ErrValidationError,
+			c.PinningMode,
+			PinningModeTaskset,
+			PinningModeCpuset,
+		)
+	}
+
+	cpus, err := ParseCPUList(c.Pinning)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cpus) == 0 {
+		return nil, fmt.Errorf("%w: pinning cpu list is empty", util.ErrValidationError)
+	}
+
+	return cpus, nil
+}
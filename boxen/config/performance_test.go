@@ -0,0 +1,217 @@
+package config_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/carlmontanari/boxen/boxen/config"
+	"github.com/carlmontanari/boxen/boxen/util"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseCPUList(t *testing.T) {
+	tests := []struct {
+		desc    string
+		s       string
+		want    []int
+		wantErr bool
+	}{
+		{
+			desc: "single cpu",
+			s:    "3",
+			want: []int{3},
+		},
+		{
+			desc: "ranges and cpus in listed order",
+			s:    "8,0-3,10-11",
+			want: []int{8, 0, 1, 2, 3, 10, 11},
+		},
+		{
+			desc: "single cpu range",
+			s:    "4-4",
+			want: []int{4},
+		},
+		{
+			desc: "sysfs online file",
+			s:    "0-7\n",
+			want: []int{0, 1, 2, 3, 4, 5, 6, 7},
+		},
+		{
+			desc: "blanks",
+			s:    " 0-1, ,4,",
+			want: []int{0, 1, 4},
+		},
+		{
+			desc: "empty",
+			s:    "",
+			want: nil,
+		},
+		{
+			desc:    "reversed range",
+			s:       "5-2",
+			wantErr: true,
+		},
+		{
+			desc:    "open range",
+			s:       "2-",
+			wantErr: true,
+		},
+		{
+			desc:    "negative cpu",
+			s:       "-1",
+			wantErr: true,
+		},
+		{
+			desc:    "not a cpu",
+			s:       "0,a",
+			wantErr: true,
+		},
+		{
+			desc:    "stride",
+			s:       "0-7:2",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := config.ParseCPUList(tt.s)
+
+			if tt.wantErr {
+				if !errors.Is(err, util.ErrValidationError) {
+					t.Fatalf("%s: expected validation error, got: %v", tt.desc, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("%s: failed parsing cpu list: %s", tt.desc, err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("%s: cpus do not match (-want +got):\n%s", tt.desc, diff)
+			}
+		})
+	}
+}
+
+func TestPinnedCPUs(t *testing.T) {
+	tests := []struct {
+		desc    string
+		cpu     *config.AdvancedCPU
+		want    []int
+		wantErr bool
+	}{
+		{
+			desc: "no cpu settings",
+			cpu:  nil,
+			want: nil,
+		},
+		{
+			desc: "no pinning",
+			cpu:  &config.AdvancedCPU{Cores: 2},
+			want: nil,
+		},
+		{
+			desc: "default pinning mode",
+			cpu:  &config.AdvancedCPU{Pinning: "2-3"},
+			want: []int{2, 3},
+		},
+		{
+			desc: "taskset pinning mode",
+			cpu:  &config.AdvancedCPU{Pinning: "2,4", PinningMode: config.PinningModeTaskset},
+			want: []int{2, 4},
+		},
+		{
+			desc: "cpuset pinning mode",
+			cpu:  &config.AdvancedCPU{Pinning: "0-1", PinningMode: config.PinningModeCpuset},
+			want: []int{0, 1},
+		},
+		{
+			desc:    "pinning mode without pinning",
+			cpu:     &config.AdvancedCPU{PinningMode: config.PinningModeCpuset},
+			wantErr: true,
+		},
+		{
+			desc:    "unknown pinning mode",
+			cpu:     &config.AdvancedCPU{Pinning: "0-1", PinningMode: "numactl"},
+			wantErr: true,
+		},
+		{
+			desc:    "empty cpu list",
+			cpu:     &config.AdvancedCPU{Pinning: " , "},
+			wantErr: true,
+		},
+		{
+			desc:    "invalid cpu list",
+			cpu:     &config.AdvancedCPU{Pinning: "3-1"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := tt.cpu.PinnedCPUs()
+
+			if tt.wantErr {
+				if !errors.Is(err, util.ErrValidationError) {
+					t.Fatalf("%s: expected validation error, got: %v", tt.desc, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("%s: failed getting pinned cpus: %s", tt.desc, err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("%s: cpus do not match (-want +got):\n%s", tt.desc, diff)
+			}
+		})
+	}
+}
+
+func TestAdvancedMemoryValidate(t *testing.T) {
+	node := func(n int) *int { return &n }
+
+	tests := []struct {
+		desc    string
+		m       *config.AdvancedMemory
+		wantErr bool
+	}{
+		{
+			desc: "no memory settings",
+			m:    nil,
+		},
+		{
+			desc: "hugepages on a numa node",
+			m:    &config.AdvancedMemory{Hugepages: true, NumaNode: node(0)},
+		},
+		{
+			desc:    "negative numa node",
+			m:       &config.AdvancedMemory{NumaNode: node(-1)},
+			wantErr: true,
+		},
+		{
+			desc:    "hugepages path without hugepages",
+			m:       &config.AdvancedMemory{HugepagesPath: "/mnt/huge"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := tt.m.Validate()
+
+			if tt.wantErr && !errors.Is(err, util.ErrValidationError) {
+				t.Fatalf("%s: expected validation error, got: %v", tt.desc, err)
+			}
+
+			if !tt.wantErr && err != nil {
+				t.Fatalf("%s: expected no error, got: %s", tt.desc, err)
+			}
+		})
+	}
+}
@@ -7,14 +7,24 @@ type Profile struct {
 	Advanced    *Advanced        `yaml:"advanced,omitempty"`
 	TPCNatPorts []int            `yaml:"tcp_nat_ports,omitempty"`
 	UDPNatPorts []int            `yaml:"udp_nat_ports,omitempty"`
+	Limits      *ProfileLimits   `yaml:"limits,omitempty"`
+}
+
+// ProfileLimits holds the hardware minimums of a platform, used to validate hardware overrides.
+type ProfileLimits struct {
+	MinMemory int `yaml:"min_memory,omitempty"`
+	MinVCPUs  int `yaml:"min_vcpus,omitempty"`
 }
 
 // Platform contains information about a given platform -- i.e. Arista vEOS -- that is stored in the
 // boxen configuration this includes the available hardware profiles and source disks that have
 // been "installed".
 type Platform struct {
-	SourceDisks []string            `yaml:"source_disks,omitempty"`
-	Profiles    map[string]*Profile `yaml:"profiles,omitempty"`
+	SourceDisks []string `yaml:"source_disks,omitempty"`
+	// SourceCredentials are the credentials each source disk (by version) was installed with, only
+	// references are stored -- see Credentials.References.
+	SourceCredentials map[string]*Credentials `yaml:"source_credentials,omitempty"`
+	Profiles          map[string]*Profile     `yaml:"profiles,omitempty"`
 }
 
 // NewPlatform returns an empty Platform object.
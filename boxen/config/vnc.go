@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+const (
+	// VNCDisplayPortBase is the port of vnc display 0, qemu takes vnc displays rather than ports.
+	VNCDisplayPortBase = 5900
+	// vncMaxPasswordLen is the maximum vnc password length, vnc auth silently truncates longer
+	// passwords.
+	vncMaxPasswordLen = 8
+)
+
+// VNC is a managed vnc graphical console of an instance, the port is allocated by boxen (see
+// Hardware.VNCPort).
+type VNC struct {
+	// ListenAddress is the address vnc is served on, 127.0.0.1 if not set (0.0.0.0 for packaged
+	// instances, the container is the boundary there).
+	ListenAddress string `yaml:"listen_address,omitempty"`
+	// Password is the vnc password, vnc is served without authentication if neither this nor
+	// PasswordFrom is set.
+	Password     string            `yaml:"password,omitempty"`
+	PasswordFrom *CredentialSource `yaml:"password_from,omitempty"`
+}
+
+// Address returns the address vnc is served on.
+func (v *VNC) Address() string {
+	if v.ListenAddress == "" {
+		return DefaultConsoleListenAddress
+	}
+
+	return v.ListenAddress
+}
+
+// Validate checks the vnc settings, v may be nil.
+func (v *VNC) Validate() error {
+	if v == nil {
+		return nil
+	}
+
+	if v.ListenAddress != "" && net.ParseIP(v.ListenAddress) == nil {
+		return fmt.Errorf(
+			"%w: vnc listen address '%s' is not an ip address",
+			util.ErrValidationError,
+			v.ListenAddress,
+		)
+	}
+
+	if v.PasswordFrom != nil {
+		return v.PasswordFrom.Validate()
+	}
+
+	return validateVNCPassword(v.Password)
+}
+
+func validateVNCPassword(p string) error {
+	if len(p) > vncMaxPasswordLen || strings.ContainsAny(p, " \t\r\n\"") {
+		return fmt.Errorf(
+			"%w: vnc password must be at most %d characters without whitespace or quotes",
+			util.ErrValidationError,
+			vncMaxPasswordLen,
+		)
+	}
+
+	return nil
+}
+
+// HasPassword returns true if vnc is served with password authentication.
+func (v *VNC) HasPassword() bool {
+	return v.Password != "" || v.PasswordFrom != nil
+}
+
+// ResolvePassword returns the vnc password, resolving the password source if set.
+func (v *VNC) ResolvePassword() (string, error) {
+	if v.PasswordFrom == nil {
+		return v.Password, nil
+	}
+
+	p, err := v.PasswordFrom.Resolve()
+	if err != nil {
+		return "", err
+	}
+
+	return p, validateVNCPassword(p)
+}
+
+// VNCDisplay returns the qemu vnc display number of the vnc port.
+func VNCDisplay(port int) int {
+	return port - VNCDisplayPortBase
+}
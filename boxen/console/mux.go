@@ -0,0 +1,394 @@
+package console
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+const (
+	// DefaultRingSize is the default number of bytes of recent output replayed to new clients.
+	DefaultRingSize = 64 * 1024
+	// DefaultWriteLease is the default time a client holds the console input after its last write.
+	DefaultWriteLease = 60 * time.Second
+
+	readSize       = 4096
+	clientQueueLen = 256
+)
+
+// client is a single attached console client.
+type client struct {
+	conn   net.Conn
+	out    chan []byte
+	parser *telnetParser
+	// automation is true for boxen automation, which takes priority over all other clients.
+	automation bool
+	// noticed is true once the client was told its input is ignored, until it gets the input.
+	noticed bool
+}
+
+// Mux is a serial console multiplexer. qemu connects the serial port to the unix socket of the Mux,
+// console output is fanned out to every attached (telnet) client, an optional log file and a ring
+// buffer that is replayed to clients when they attach. Console input is arbitrated: the first
+// client to write holds the input until it disconnects or has not written for the write lease;
+// boxen automation (see AutomationHello) holds the input for as long as it is attached. Input of
+// other clients is dropped.
+type Mux struct {
+	socket     string
+	listen     string
+	logFile    string
+	ringSize   int
+	writeLease time.Duration
+
+	ring *ring
+	log  *os.File
+
+	lock      *sync.Mutex
+	backend   net.Conn
+	clients   map[*client]bool
+	writer    *client
+	lastWrite time.Time
+
+	backendListener net.Listener
+	clientListener  net.Listener
+	closed          bool
+}
+
+// NewMux returns a Mux for the unix socket socket serving clients on the tcp address listen.
+func NewMux(socket, listen string, opts ...Option) (*Mux, error) {
+	m := &Mux{
+		socket:     socket,
+		listen:     listen,
+		ringSize:   DefaultRingSize,
+		writeLease: DefaultWriteLease,
+		lock:       &sync.Mutex{},
+		clients:    map[*client]bool{},
+	}
+
+	for _, o := range opts {
+		err := o(m)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	m.ring = newRing(m.ringSize)
+
+	return m, nil
+}
+
+// Run listens on the unix socket and the client address and multiplexes the console until Close is
+// called or a listener fails.
+func (m *Mux) Run() error {
+	var err error
+
+	if m.logFile != "" {
+		m.log, err = os.OpenFile(
+			m.logFile,
+			os.O_APPEND|os.O_CREATE|os.O_WRONLY,
+			util.FilePerms,
+		)
+		if err != nil {
+			return err
+		}
+
+		defer m.log.Close()
+	}
+
+	// a stale socket of a previous mux would fail the listen
+	_ = os.Remove(m.socket)
+
+	m.backendListener, err = net.Listen("unix", m.socket)
+	if err != nil {
+		return fmt.Errorf("%w: failed listening on '%s': %s", util.ErrConsoleError, m.socket, err)
+	}
+
+	defer os.Remove(m.socket)
+
+	m.clientListener, err = net.Listen("tcp", m.listen)
+	if err != nil {
+		_ = m.backendListener.Close()
+
+		return fmt.Errorf("%w: failed listening on '%s': %s", util.ErrConsoleError, m.listen, err)
+	}
+
+	errs := make(chan error, 2) //nolint:gomnd
+
+	go func() { errs <- m.acceptBackend() }()
+
+	go func() { errs <- m.acceptClients() }()
+
+	err = <-errs
+
+	_ = m.Close()
+
+	m.lock.Lock()
+	closed := m.closed
+	m.lock.Unlock()
+
+	if closed && errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+
+	return err
+}
+
+// Close stops the Mux, disconnecting qemu and all clients.
+func (m *Mux) Close() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.closed {
+		return nil
+	}
+
+	m.closed = true
+
+	if m.backendListener != nil {
+		_ = m.backendListener.Close()
+	}
+
+	if m.clientListener != nil {
+		_ = m.clientListener.Close()
+	}
+
+	if m.backend != nil {
+		_ = m.backend.Close()
+	}
+
+	for c := range m.clients {
+		m.dropLocked(c)
+	}
+
+	return nil
+}
+
+// acceptBackend accepts the qemu connections, a new connection (i.e. qemu reconnecting) replaces
+// the current one.
+func (m *Mux) acceptBackend() error {
+	for {
+		conn, err := m.backendListener.Accept()
+		if err != nil {
+			return err
+		}
+
+		m.lock.Lock()
+
+		if m.backend != nil {
+			_ = m.backend.Close()
+		}
+
+		m.backend = conn
+
+		m.lock.Unlock()
+
+		go m.readBackend(conn)
+	}
+}
+
+func (m *Mux) readBackend(conn net.Conn) {
+	buf := make([]byte, readSize)
+
+	for {
+		n, err := conn.Read(buf)
+		if n > 0 {
+			m.broadcast(buf[:n])
+		}
+
+		if err != nil {
+			break
+		}
+	}
+
+	m.lock.Lock()
+
+	if m.backend == conn {
+		m.backend = nil
+	}
+
+	m.lock.Unlock()
+
+	_ = conn.Close()
+}
+
+// broadcast writes the console output b to the ring, the log file and every client. Clients that
+// can't keep up are disconnected rather than holding up the console.
+func (m *Mux) broadcast(b []byte) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	_, _ = m.ring.Write(b)
+
+	if m.log != nil {
+		_, _ = m.log.Write(b)
+	}
+
+	for c := range m.clients {
+		select {
+		case c.out <- append([]byte{}, b...):
+		default:
+			m.dropLocked(c)
+		}
+	}
+}
+
+func (m *Mux) acceptClients() error {
+	for {
+		conn, err := m.clientListener.Accept()
+		if err != nil {
+			return err
+		}
+
+		go m.serveClient(conn)
+	}
+}
+
+func (m *Mux) serveClient(conn net.Conn) {
+	c := &client{
+		conn:   conn,
+		out:    make(chan []byte, clientQueueLen),
+		parser: &telnetParser{},
+	}
+
+	m.lock.Lock()
+
+	if m.closed {
+		m.lock.Unlock()
+
+		_ = conn.Close()
+
+		return
+	}
+
+	// the replay is queued while holding the lock, so the client sees no gap (nor duplicates)
+	// between the replay and the live output
+	c.out <- append(telnetGreeting(), m.ring.Bytes()...)
+	m.clients[c] = true
+
+	m.lock.Unlock()
+
+	go m.writeClient(c)
+
+	buf := make([]byte, readSize)
+
+	for {
+		n, err := conn.Read(buf)
+		if n > 0 {
+			data, automation := c.parser.parse(buf[:n])
+
+			if automation {
+				m.lock.Lock()
+				c.automation = true
+				m.writer = c
+				m.lock.Unlock()
+			}
+
+			if len(data) > 0 {
+				m.input(c, data)
+			}
+		}
+
+		if err != nil {
+			break
+		}
+	}
+
+	m.lock.Lock()
+	m.dropLocked(c)
+	m.lock.Unlock()
+}
+
+func (m *Mux) writeClient(c *client) {
+	for b := range c.out {
+		_, err := c.conn.Write(b)
+		if err != nil {
+			_ = c.conn.Close()
+
+			// keep draining so broadcast never blocks on this client before it is dropped
+			for range c.out {
+			}
+
+			return
+		}
+	}
+
+	_ = c.conn.Close()
+}
+
+// dropLocked disconnects the client, m.lock must be held.
+func (m *Mux) dropLocked(c *client) {
+	if !m.clients[c] {
+		return
+	}
+
+	delete(m.clients, c)
+	close(c.out)
+
+	if m.writer == c {
+		m.writer = nil
+	}
+}
+
+// acquireLocked returns true if the client may write to the console, taking the input if it is
+// free, m.lock must be held.
+func (m *Mux) acquireLocked(c *client) bool {
+	switch {
+	case m.writer == nil, m.writer == c:
+	case m.writer.automation:
+		return false
+	case time.Since(m.lastWrite) < m.writeLease:
+		return false
+	}
+
+	m.writer = c
+	m.lastWrite = time.Now()
+	c.noticed = false
+
+	return true
+}
+
+// input writes the client input data to qemu if the client holds the console input.
+func (m *Mux) input(c *client, data []byte) {
+	m.lock.Lock()
+
+	if !m.acquireLocked(c) {
+		if !c.noticed && m.clients[c] {
+			c.noticed = true
+
+			holder := "another client"
+			if m.writer.automation {
+				holder = "boxen"
+			}
+
+			select {
+			case c.out <- []byte(
+				fmt.Sprintf("\r\n[boxen] console input is held by %s, input ignored\r\n", holder),
+			):
+			default:
+			}
+		}
+
+		m.lock.Unlock()
+
+		return
+	}
+
+	backend := m.backend
+
+	m.lock.Unlock()
+
+	if backend == nil {
+		return
+	}
+
+	_, err := backend.Write(data)
+	if err != nil && !errors.Is(err, io.EOF) {
+		_ = backend.Close()
+	}
+}
@@ -0,0 +1,245 @@
+package console
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testWriteLease = 200 * time.Millisecond
+	testTimeout    = time.Second
+)
+
+// testOutput collects everything a client receives.
+type testOutput struct {
+	lock *sync.Mutex
+	buf  *bytes.Buffer
+}
+
+func (o *testOutput) String() string {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	return o.buf.String()
+}
+
+// newTestMux returns a Mux with its backend (qemu) side connected to the returned net.Pipe end.
+func newTestMux(t *testing.T) (*Mux, net.Conn) {
+	t.Helper()
+
+	m, err := NewMux("", "", WithWriteLease(testWriteLease))
+	if err != nil {
+		t.Fatalf("failed creating mux: %s", err)
+	}
+
+	qemu, backend := net.Pipe()
+	m.backend = backend
+
+	t.Cleanup(func() {
+		_ = m.Close()
+		_ = qemu.Close()
+	})
+
+	return m, qemu
+}
+
+// attach attaches a client to the mux, returning the client side of the connection and the output
+// the client receives.
+func attach(t *testing.T, m *Mux) (net.Conn, *testOutput) {
+	t.Helper()
+
+	conn, muxSide := net.Pipe()
+	out := &testOutput{lock: &sync.Mutex{}, buf: &bytes.Buffer{}}
+
+	go m.serveClient(muxSide)
+
+	go func() {
+		buf := make([]byte, readSize)
+
+		for {
+			n, err := conn.Read(buf)
+
+			out.lock.Lock()
+			out.buf.Write(buf[:n])
+			out.lock.Unlock()
+
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn, out
+}
+
+func write(t *testing.T, c net.Conn, b []byte) {
+	t.Helper()
+
+	_ = c.SetWriteDeadline(time.Now().Add(testTimeout))
+
+	_, err := c.Write(b)
+	if err != nil {
+		t.Fatalf("failed writing client input: %s", err)
+	}
+}
+
+// expectInput asserts qemu receives want.
+func expectInput(t *testing.T, qemu net.Conn, want string) {
+	t.Helper()
+
+	_ = qemu.SetReadDeadline(time.Now().Add(testTimeout))
+
+	buf := make([]byte, len(want))
+
+	n, err := qemu.Read(buf)
+	if err != nil {
+		t.Fatalf("expected console input %q, got error: %s", want, err)
+	}
+
+	if string(buf[:n]) != want {
+		t.Fatalf("expected console input %q, got %q", want, buf[:n])
+	}
+}
+
+// expectNoInput asserts qemu receives nothing.
+func expectNoInput(t *testing.T, qemu net.Conn) {
+	t.Helper()
+
+	_ = qemu.SetReadDeadline(time.Now().Add(2 * testWriteLease))
+
+	buf := make([]byte, readSize)
+
+	n, err := qemu.Read(buf)
+	if !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("expected no console input, got %q (%v)", buf[:n], err)
+	}
+}
+
+func expectOutput(t *testing.T, out *testOutput, want string) {
+	t.Helper()
+
+	deadline := time.Now().Add(testTimeout)
+
+	for time.Now().Before(deadline) {
+		if strings.Contains(out.String(), want) {
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("expected client output to contain %q, got %q", want, out.String())
+}
+
+func TestMuxFirstWriterHoldsInput(t *testing.T) {
+	m, qemu := newTestMux(t)
+
+	a, _ := attach(t, m)
+	b, bOut := attach(t, m)
+
+	write(t, a, []byte("a"))
+	expectInput(t, qemu, "a")
+
+	write(t, b, []byte("b"))
+	expectNoInput(t, qemu)
+	expectOutput(t, bOut, "console input is held by another client")
+
+	// a keeps writing, so it keeps the input
+	write(t, a, []byte("c"))
+	expectInput(t, qemu, "c")
+}
+
+func TestMuxWriteLeaseExpiry(t *testing.T) {
+	m, qemu := newTestMux(t)
+
+	a, _ := attach(t, m)
+	b, _ := attach(t, m)
+
+	write(t, a, []byte("a"))
+	expectInput(t, qemu, "a")
+
+	time.Sleep(2 * testWriteLease)
+
+	write(t, b, []byte("b"))
+	expectInput(t, qemu, "b")
+
+	// the input moved to b, so a has to wait for the lease now
+	write(t, a, []byte("c"))
+	expectNoInput(t, qemu)
+}
+
+func TestMuxWriterDisconnect(t *testing.T) {
+	m, qemu := newTestMux(t)
+
+	a, _ := attach(t, m)
+	b, _ := attach(t, m)
+
+	write(t, a, []byte("a"))
+	expectInput(t, qemu, "a")
+
+	_ = a.Close()
+
+	deadline := time.Now().Add(testTimeout)
+
+	for {
+		m.lock.Lock()
+		writer := m.writer
+		m.lock.Unlock()
+
+		if writer == nil {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("expected input to be released when the writer disconnects")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	write(t, b, []byte("b"))
+	expectInput(t, qemu, "b")
+}
+
+func TestMuxAutomationHoldsInput(t *testing.T) {
+	m, qemu := newTestMux(t)
+
+	a, aOut := attach(t, m)
+	boxen, _ := attach(t, m)
+
+	write(t, boxen, append(AutomationHello(), 'x'))
+	expectInput(t, qemu, "x")
+
+	// automation holds the input regardless of the write lease
+	time.Sleep(2 * testWriteLease)
+
+	write(t, a, []byte("a"))
+	expectNoInput(t, qemu)
+	expectOutput(t, aOut, "console input is held by boxen")
+
+	write(t, boxen, []byte("y"))
+	expectInput(t, qemu, "y")
+}
+
+func TestMuxBroadcastReplay(t *testing.T) {
+	m, _ := newTestMux(t)
+
+	_, aOut := attach(t, m)
+
+	// wait for a to be attached so it sees the output live rather than replayed
+	expectOutput(t, aOut, string(telnetGreeting()))
+
+	m.broadcast([]byte("login: "))
+	expectOutput(t, aOut, "login: ")
+
+	_, bOut := attach(t, m)
+	expectOutput(t, bOut, string(telnetGreeting())+"login: ")
+}
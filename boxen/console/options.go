@@ -0,0 +1,46 @@
+package console
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+// Option is a functional option for the Mux.
+type Option func(m *Mux) error
+
+// WithLogFile sets a file all console output is appended to.
+func WithLogFile(f string) Option {
+	return func(m *Mux) error {
+		m.logFile = f
+
+		return nil
+	}
+}
+
+// WithRingSize sets the number of bytes of recent console output replayed to attaching clients.
+func WithRingSize(n int) Option {
+	return func(m *Mux) error {
+		if n < 0 {
+			return fmt.Errorf("%w: ring size must not be negative", util.ErrValidationError)
+		}
+
+		m.ringSize = n
+
+		return nil
+	}
+}
+
+// WithWriteLease sets how long a client holds the console input after its last write.
+func WithWriteLease(d time.Duration) Option {
+	return func(m *Mux) error {
+		if d <= 0 {
+			return fmt.Errorf("%w: write lease must be positive", util.ErrValidationError)
+		}
+
+		m.writeLease = d
+
+		return nil
+	}
+}
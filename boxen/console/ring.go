@@ -0,0 +1,66 @@
+package console
+
+import "sync"
+
+// ring is a fixed size buffer holding the most recently written bytes.
+type ring struct {
+	lock *sync.Mutex
+	buf  []byte
+	pos  int
+	full bool
+}
+
+func newRing(size int) *ring {
+	return &ring{
+		lock: &sync.Mutex{},
+		buf:  make([]byte, size),
+	}
+}
+
+// Write writes b to the ring, overwriting the oldest bytes once the ring is full.
+func (r *ring) Write(b []byte) (int, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	n := len(b)
+
+	if len(r.buf) == 0 {
+		return n, nil
+	}
+
+	if n >= len(r.buf) {
+		copy(r.buf, b[n-len(r.buf):])
+
+		r.pos = 0
+		r.full = true
+
+		return n, nil
+	}
+
+	copied := copy(r.buf[r.pos:], b)
+	if copied < n {
+		copy(r.buf, b[copied:])
+
+		r.full = true
+	}
+
+	r.pos = (r.pos + n) % len(r.buf)
+
+	if r.pos == 0 {
+		r.full = true
+	}
+
+	return n, nil
+}
+
+// Bytes returns a copy of the contents of the ring, oldest byte first.
+func (r *ring) Bytes() []byte {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.full {
+		return append([]byte{}, r.buf[:r.pos]...)
+	}
+
+	return append(append([]byte{}, r.buf[r.pos:]...), r.buf[:r.pos]...)
+}
@@ -0,0 +1,81 @@
+package console
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRing(t *testing.T) {
+	tests := []struct {
+		desc   string
+		size   int
+		writes []string
+		want   string
+	}{
+		{
+			desc: "empty",
+			size: 8,
+			want: "",
+		},
+		{
+			desc:   "partially filled",
+			size:   8,
+			writes: []string{"abc", "de"},
+			want:   "abcde",
+		},
+		{
+			desc:   "exactly filled",
+			size:   8,
+			writes: []string{"abcd", "efgh"},
+			want:   "abcdefgh",
+		},
+		{
+			desc:   "wraps around",
+			size:   8,
+			writes: []string{"abcdef", "ghij"},
+			want:   "cdefghij",
+		},
+		{
+			desc:   "wraps around more than once",
+			size:   8,
+			writes: []string{"abcdef", "ghij", "klmnop", "q"},
+			want:   "jklmnopq",
+		},
+		{
+			desc:   "write larger than the ring",
+			size:   8,
+			writes: []string{"ab", "0123456789"},
+			want:   "23456789",
+		},
+		{
+			desc:   "write after a write larger than the ring",
+			size:   8,
+			writes: []string{"0123456789", "xy"},
+			want:   "456789xy",
+		},
+		{
+			desc:   "zero size",
+			size:   0,
+			writes: []string{"abc"},
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			r := newRing(tt.size)
+
+			for _, w := range tt.writes {
+				n, err := r.Write([]byte(w))
+				if err != nil || n != len(w) {
+					t.Fatalf("%s: write returned %d, %v", tt.desc, n, err)
+				}
+			}
+
+			if diff := cmp.Diff(tt.want, string(r.Bytes())); diff != "" {
+				t.Fatalf("%s: ring contents do not match (-want +got):\n%s", tt.desc, diff)
+			}
+		})
+	}
+}
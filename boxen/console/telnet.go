@@ -0,0 +1,110 @@
+package console
+
+const (
+	iac  = byte(255)
+	dont = byte(254)
+	do   = byte(253)
+	wont = byte(252)
+	will = byte(251)
+	sb   = byte(250)
+	se   = byte(240)
+
+	optBinary = byte(0)
+	optEcho   = byte(1)
+	optSGA    = byte(3)
+
+	// optBoxen is the (unassigned) telnet option boxen automation identifies itself with.
+	optBoxen = byte(176)
+)
+
+// telnetGreeting returns the option negotiation sent to clients when they attach, the same
+// negotiation the qemu telnet server sends.
+func telnetGreeting() []byte {
+	return []byte{
+		iac, will, optEcho,
+		iac, will, optSGA,
+		iac, will, optBinary,
+		iac, do, optBinary,
+	}
+}
+
+// AutomationHello returns the telnet subnegotiation boxen sends after attaching to a console, it
+// marks the client as boxen automation which takes priority over console input of other clients.
+func AutomationHello() []byte {
+	return []byte{iac, sb, optBoxen, iac, se}
+}
+
+const (
+	stateData = iota
+	stateIAC
+	stateOpt
+	stateSBOpt
+	stateSBData
+	stateSBIAC
+	stateCR
+)
+
+// telnetParser strips telnet commands from client input, the state carries over between reads.
+type telnetParser struct {
+	state int
+	sbOpt byte
+}
+
+// parse returns the data in b without any telnet commands, and whether b contained the automation
+// hello.
+func (p *telnetParser) parse(b []byte) (data []byte, automation bool) {
+	for _, c := range b {
+		switch p.state {
+		case stateData, stateCR:
+			switch {
+			case c == iac:
+				p.state = stateIAC
+			case c == 0 && p.state == stateCR:
+				// clients not in binary mode send a return as cr nul
+				p.state = stateData
+			default:
+				data = append(data, c)
+
+				p.state = stateData
+				if c == '\r' {
+					p.state = stateCR
+				}
+			}
+		case stateIAC:
+			switch c {
+			case iac:
+				data = append(data, iac)
+
+				p.state = stateData
+			case will, wont, do, dont:
+				p.state = stateOpt
+			case sb:
+				p.state = stateSBOpt
+			default:
+				p.state = stateData
+			}
+		case stateOpt:
+			p.state = stateData
+		case stateSBOpt:
+			p.sbOpt = c
+			p.state = stateSBData
+		case stateSBData:
+			if c == iac {
+				p.state = stateSBIAC
+			}
+		case stateSBIAC:
+			switch c {
+			case se:
+				if p.sbOpt == optBoxen {
+					automation = true
+				}
+
+				p.state = stateData
+			default:
+				p.state = stateSBData
+			}
+		}
+	}
+
+	return data, automation
+}
@@ -0,0 +1,94 @@
+package console
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTelnetParser(t *testing.T) {
+	tests := []struct {
+		desc           string
+		reads          [][]byte
+		wantData       []byte
+		wantAutomation bool
+	}{
+		{
+			desc:     "plain data",
+			reads:    [][]byte{[]byte("show version\r\n")},
+			wantData: []byte("show version\r\n"),
+		},
+		{
+			desc:     "escaped iac",
+			reads:    [][]byte{{'a', iac, iac, 'b'}},
+			wantData: []byte{'a', iac, 'b'},
+		},
+		{
+			desc:     "escaped iac across reads",
+			reads:    [][]byte{{'a', iac}, {iac, 'b'}},
+			wantData: []byte{'a', iac, 'b'},
+		},
+		{
+			desc:     "cr nul",
+			reads:    [][]byte{{'a', '\r', 0, 'b'}},
+			wantData: []byte("a\rb"),
+		},
+		{
+			desc:     "cr nul across reads",
+			reads:    [][]byte{{'a', '\r'}, {0, 'b'}},
+			wantData: []byte("a\rb"),
+		},
+		{
+			desc:     "nul not after cr",
+			reads:    [][]byte{{'a', 0, 'b'}},
+			wantData: []byte{'a', 0, 'b'},
+		},
+		{
+			desc:     "option negotiation across reads",
+			reads:    [][]byte{{'a', iac}, {do}, {optSGA, 'b'}},
+			wantData: []byte("ab"),
+		},
+		{
+			desc:           "automation hello",
+			reads:          [][]byte{append(AutomationHello(), 'a')},
+			wantData:       []byte("a"),
+			wantAutomation: true,
+		},
+		{
+			desc:           "automation hello across reads",
+			reads:          [][]byte{{'a', iac, sb}, {optBoxen, iac}, {se, 'b'}},
+			wantData:       []byte("ab"),
+			wantAutomation: true,
+		},
+		{
+			desc:     "other subnegotiation with escaped iac",
+			reads:    [][]byte{{iac, sb, 24, 'x', iac}, {iac, 'y', iac, se, 'a'}},
+			wantData: []byte("a"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			p := &telnetParser{}
+
+			var data []byte
+
+			var automation bool
+
+			for _, r := range tt.reads {
+				d, a := p.parse(r)
+
+				data = append(data, d...)
+				automation = automation || a
+			}
+
+			if diff := cmp.Diff(tt.wantData, data); diff != "" {
+				t.Fatalf("%s: parsed data does not match (-want +got):\n%s", tt.desc, diff)
+			}
+
+			if automation != tt.wantAutomation {
+				t.Fatalf("%s: got automation %t, want %t", tt.desc, automation, tt.wantAutomation)
+			}
+		})
+	}
+}
@@ -21,6 +21,8 @@ func Build(opts ...Option) error {
 
 	cmdArgs := []string{"build"}
 
+	cmdArgs = append(cmdArgs, a.getEngine().BuildArgs()...)
+
 	if a.workDir != "" {
 		cmdArgs = append(cmdArgs, a.workDir)
 	}
@@ -39,7 +41,7 @@ func Build(opts ...Option) error {
 
 	executeArgs = append(executeArgs, command.WithArgs(cmdArgs))
 
-	r, err := command.Execute(dockerCmd, executeArgs...)
+	r, err := command.Execute(a.getEngine().Command(), executeArgs...)
 	if err != nil {
 		return err
 	}
@@ -37,7 +37,7 @@ func Commit(opts ...Option) error {
 
 	executeArgs = append(executeArgs, command.WithArgs(cmdArgs))
 
-	r, err := command.Execute(dockerCmd, executeArgs...)
+	r, err := command.Execute(a.getEngine().Command(), executeArgs...)
 	if err != nil {
 		return err
 	}
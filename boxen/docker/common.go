@@ -20,10 +20,22 @@ type args struct {
 	privileged   bool
 	commitChange string
 	nocache      bool
+	envFile      string
+	volumes      []string
+	engine       Engine
 	stdOut       io.Writer
 	stdErr       io.Writer
 }
 
+// getEngine returns the Engine set in the args, defaulting to docker if none was provided.
+func (a *args) getEngine() Engine {
+	if a.engine == nil {
+		return &Docker{}
+	}
+
+	return a.engine
+}
+
 func setExecuteArgs(a *args) []command.ExecuteOption {
 	var executeArgs []command.ExecuteOption
 
@@ -39,7 +51,7 @@ func setExecuteArgs(a *args) []command.ExecuteOption {
 		executeArgs = append(executeArgs, command.WithStdErr(a.stdErr))
 	}
 
-	if a.privileged {
+	if a.privileged && a.getEngine().PrivilegedSudo() {
 		executeArgs = append(executeArgs, command.WithSudo(true))
 	}
 
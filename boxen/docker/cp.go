@@ -29,7 +29,7 @@ func CopyFromContainer(s, d string, opts ...Option) error {
 
 	executeArgs = append(executeArgs, command.WithArgs(cmdArgs))
 
-	r, err := command.Execute(dockerCmd, executeArgs...)
+	r, err := command.Execute(a.getEngine().Command(), executeArgs...)
 	if err != nil {
 		return err
 	}
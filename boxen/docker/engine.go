@@ -0,0 +1,191 @@
+package docker
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/carlmontanari/boxen/boxen/command"
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+const (
+	// EngineDocker is the name of the docker container engine.
+	EngineDocker = "docker"
+	// EnginePodman is the name of the podman container engine.
+	EnginePodman = "podman"
+	// EngineAuto auto-detects the container engine to use, see DetectEngine.
+	EngineAuto = "auto"
+
+	podmanCmd = "podman"
+)
+
+// Engine is a container engine (cli) that the functions in this package shell out to. Engines
+// handle the (small) differences between the clis, the operations themselves are the same.
+type Engine interface {
+	// Name returns the name of the engine.
+	Name() string
+	// Command returns the cli command of the engine.
+	Command() string
+	// PrivilegedSudo returns true if privileged operations need to be run with sudo.
+	PrivilegedSudo() bool
+	// BuildArgs returns any additional engine specific arguments for build operations.
+	BuildArgs() []string
+	// ExitCode parses the output of the engines wait command and returns the containers exit code.
+	ExitCode(waitOutput []byte) (int, error)
+	// PushArgs returns any additional engine specific arguments for push operations, digestFile is
+	// the file the engine should write the pushed image digest to (if it supports that).
+	PushArgs(digestFile string) []string
+}
+
+func parseWaitOutput(waitOutput []byte) (int, error) {
+	lines := strings.Split(strings.TrimSpace(string(bytes.Trim(waitOutput, "\x00"))), "\n")
+
+	exitCode, err := strconv.Atoi(strings.TrimSpace(lines[len(lines)-1]))
+	if err != nil {
+		return 0, fmt.Errorf(
+			"%w: failed parsing container exit code from wait output '%s'",
+			util.ErrCommandError,
+			waitOutput,
+		)
+	}
+
+	return exitCode, nil
+}
+
+// Docker is the docker Engine, it assumes a (rootful) docker daemon so privileged operations are
+// run with sudo.
+type Docker struct{}
+
+// Name returns the name of the engine.
+func (e *Docker) Name() string {
+	return EngineDocker
+}
+
+// Command returns the cli command of the engine.
+func (e *Docker) Command() string {
+	return dockerCmd
+}
+
+// PrivilegedSudo returns true if privileged operations need to be run with sudo.
+func (e *Docker) PrivilegedSudo() bool {
+	return true
+}
+
+// BuildArgs returns any additional engine specific arguments for build operations.
+func (e *Docker) BuildArgs() []string {
+	return nil
+}
+
+// ExitCode parses the output of the engines wait command and returns the containers exit code.
+func (e *Docker) ExitCode(waitOutput []byte) (int, error) {
+	return parseWaitOutput(waitOutput)
+}
+
+// PushArgs returns any additional engine specific arguments for push operations. Docker can't write
+// the digest to a file, the digest is parsed from the push output instead.
+func (e *Docker) PushArgs(_ string) []string {
+	return nil
+}
+
+// Podman is the podman Engine. Podman is assumed to be run rootless -- privileged operations are
+// *not* run with sudo as root has its own image and container storage, so images built as the
+// user would not be visible to root (and vice versa).
+type Podman struct{}
+
+// Name returns the name of the engine.
+func (e *Podman) Name() string {
+	return EnginePodman
+}
+
+// Command returns the cli command of the engine.
+func (e *Podman) Command() string {
+	return podmanCmd
+}
+
+// PrivilegedSudo returns true if privileged operations need to be run with sudo.
+func (e *Podman) PrivilegedSudo() bool {
+	return false
+}
+
+// BuildArgs returns any additional engine specific arguments for build operations. Podman builds
+// oci images by default which do not support HEALTHCHECK, so build docker format images instead.
+func (e *Podman) BuildArgs() []string {
+	return []string{"--format", "docker"}
+}
+
+// ExitCode parses the output of the engines wait command and returns the containers exit code.
+// Podman reports -1 if the exit code could not be determined (i.e. the container was removed).
+func (e *Podman) ExitCode(waitOutput []byte) (int, error) {
+	exitCode, err := parseWaitOutput(waitOutput)
+	if err != nil {
+		return exitCode, err
+	}
+
+	if exitCode < 0 {
+		return exitCode, fmt.Errorf(
+			"%w: podman could not determine container exit code",
+			util.ErrCommandError,
+		)
+	}
+
+	return exitCode, nil
+}
+
+// PushArgs returns any additional engine specific arguments for push operations.
+func (e *Podman) PushArgs(digestFile string) []string {
+	return []string{"--digestfile", digestFile}
+}
+
+// NewEngine returns the Engine for the given engine name, if the name is empty or "auto" the
+// engine is auto-detected.
+func NewEngine(name string) (Engine, error) {
+	switch name {
+	case EngineDocker:
+		return &Docker{}, nil
+	case EnginePodman:
+		return &Podman{}, nil
+	case EngineAuto, "":
+		return DetectEngine()
+	}
+
+	return nil, fmt.Errorf(
+		"%w: unknown container engine '%s', must be one of %s, %s, %s",
+		util.ErrValidationError,
+		name,
+		EngineDocker,
+		EnginePodman,
+		EngineAuto,
+	)
+}
+
+// DetectEngine returns the Engine available on the host, preferring docker. If the docker command
+// is actually the podman docker "emulation" (podman-docker) podman is returned.
+func DetectEngine() (Engine, error) {
+	if util.CommandExists(dockerCmd) {
+		r, err := command.Execute(
+			dockerCmd,
+			command.WithArgs([]string{"--version"}),
+			command.WithWait(true),
+		)
+		if err == nil {
+			out, _ := r.ReadStdout()
+
+			if bytes.Contains(bytes.ToLower(out), []byte(podmanCmd)) {
+				return &Podman{}, nil
+			}
+		}
+
+		return &Docker{}, nil
+	}
+
+	if util.CommandExists(podmanCmd) {
+		return &Podman{}, nil
+	}
+
+	return nil, fmt.Errorf(
+		"%w: no container engine found, install docker or podman",
+		util.ErrCommandError,
+	)
+}
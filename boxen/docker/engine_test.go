@@ -0,0 +1,177 @@
+package docker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+func TestParseWaitOutput(t *testing.T) {
+	tests := []struct {
+		desc    string
+		out     string
+		want    int
+		wantErr bool
+	}{
+		{
+			desc: "exit code",
+			out:  "0\n",
+			want: 0,
+		},
+		{
+			desc: "non zero exit code",
+			out:  "137\n",
+			want: 137,
+		},
+		{
+			desc: "no trailing newline",
+			out:  "1",
+			want: 1,
+		},
+		{
+			desc: "carriage return and nul bytes",
+			out:  "\x000\r\n\x00",
+			want: 0,
+		},
+		{
+			desc: "exit code on the last line",
+			out:  "[sudo] password for boxen: \n2\n",
+			want: 2,
+		},
+		{
+			desc: "negative exit code",
+			out:  "-1\n",
+			want: -1,
+		},
+		{
+			desc:    "empty",
+			out:     "",
+			wantErr: true,
+		},
+		{
+			desc:    "whitespace",
+			out:     " \n",
+			wantErr: true,
+		},
+		{
+			desc:    "garbage",
+			out:     "Error: No such container: 3f6c1d4be2a8\n",
+			wantErr: true,
+		},
+		{
+			desc:    "exit code followed by garbage",
+			out:     "0\nError: No such container: 3f6c1d4be2a8\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := parseWaitOutput([]byte(tt.out))
+
+			if tt.wantErr {
+				if !errors.Is(err, util.ErrCommandError) {
+					t.Fatalf("%s: expected command error, got: %v", tt.desc, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("%s: failed parsing wait output: %s", tt.desc, err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("%s: got exit code %d, want %d", tt.desc, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngineExitCode(t *testing.T) {
+	tests := []struct {
+		desc    string
+		engine  Engine
+		out     string
+		want    int
+		wantErr bool
+	}{
+		{
+			desc:   "docker",
+			engine: &Docker{},
+			out:    "0\n",
+			want:   0,
+		},
+		{
+			desc:   "docker non zero exit code",
+			engine: &Docker{},
+			out:    "3\n",
+			want:   3,
+		},
+		{
+			desc:    "docker empty",
+			engine:  &Docker{},
+			out:     "",
+			wantErr: true,
+		},
+		{
+			desc:    "docker garbage",
+			engine:  &Docker{},
+			out:     "Error response from daemon: No such container: boxen\n",
+			wantErr: true,
+		},
+		{
+			desc:   "podman",
+			engine: &Podman{},
+			out:    "0\n",
+			want:   0,
+		},
+		{
+			desc:   "podman non zero exit code",
+			engine: &Podman{},
+			out:    "125\n",
+			want:   125,
+		},
+		{
+			desc:    "podman undetermined exit code",
+			engine:  &Podman{},
+			out:     "-1\n",
+			wantErr: true,
+		},
+		{
+			desc:    "podman empty",
+			engine:  &Podman{},
+			out:     "",
+			wantErr: true,
+		},
+		{
+			desc:    "podman garbage",
+			engine:  &Podman{},
+			out:     "Error: no container with name or ID \"boxen\" found: no such container\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := tt.engine.ExitCode([]byte(tt.out))
+
+			if tt.wantErr {
+				if !errors.Is(err, util.ErrCommandError) {
+					t.Fatalf("%s: expected command error, got: %v", tt.desc, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("%s: failed parsing exit code: %s", tt.desc, err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("%s: got exit code %d, want %d", tt.desc, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package docker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/carlmontanari/boxen/boxen/command"
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+// ImageLabels returns the labels of the image repo:tag.
+func ImageLabels(repo, tag string, opts ...Option) (map[string]string, error) {
+	a := &args{}
+
+	for _, o := range opts {
+		err := o(a)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	stdout := &bytes.Buffer{}
+	a.stdOut = stdout
+
+	cmdArgs := []string{
+		"image",
+		"inspect",
+		"--format",
+		"{{json .Config.Labels}}",
+		fmt.Sprintf("%s:%s", repo, tag),
+	}
+
+	executeArgs := setExecuteArgs(a)
+
+	executeArgs = append(executeArgs, command.WithArgs(cmdArgs))
+
+	r, err := command.Execute(a.getEngine().Command(), executeArgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.Proc.Wait()
+	if err != nil {
+		return nil, fmt.Errorf(
+			"%w: failed inspecting image '%s:%s': %s",
+			util.ErrCommandError,
+			repo,
+			tag,
+			err,
+		)
+	}
+
+	out := strings.TrimSpace(string(bytes.Trim(stdout.Bytes(), "\x00")))
+
+	labels := map[string]string{}
+
+	// images w/out any labels report "null", which unmarshals to an empty map just fine
+	err = json.Unmarshal([]byte(out), &labels)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"%w: failed parsing labels of image '%s:%s': %s",
+			util.ErrInspectionError,
+			repo,
+			tag,
+			err,
+		)
+	}
+
+	return labels, nil
+}
+
+// Create creates (but does not start) a container from the repo and tag provided in the options,
+// returning the container id. This is handy for copying files out of an image.
+func Create(opts ...Option) (string, error) {
+	a := &args{}
+
+	for _, o := range opts {
+		err := o(a)
+
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if a.repo == "" || a.tag == "" {
+		return "", fmt.Errorf(
+			"%w: repo and tag not provided, can't create container",
+			util.ErrValidationError,
+		)
+	}
+
+	stdout := &bytes.Buffer{}
+	a.stdOut = stdout
+
+	cmdArgs := []string{"create", fmt.Sprintf("%s:%s", a.repo, a.tag)}
+
+	executeArgs := setExecuteArgs(a)
+
+	executeArgs = append(executeArgs, command.WithArgs(cmdArgs))
+
+	r, err := command.Execute(a.getEngine().Command(), executeArgs...)
+	if err != nil {
+		return "", err
+	}
+
+	err = r.Proc.Wait()
+	if err != nil {
+		return "", fmt.Errorf(
+			"%w: failed creating container from image '%s:%s': %s",
+			util.ErrCommandError,
+			a.repo,
+			a.tag,
+			err,
+		)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(bytes.Trim(stdout.Bytes(), "\x00"))), "\n")
+
+	// the container id is the last line of output, anything before that is pull output
+	return strings.TrimSpace(lines[len(lines)-1]), nil
+}
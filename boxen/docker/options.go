@@ -103,3 +103,32 @@ func WithNoCache(c bool) Option {
 		return nil
 	}
 }
+
+// WithEnvFile sets the env-file argument to the provided file f for docker run operations. Note
+// that privileged containers are run with sudo which does not preserve the callers environment, so
+// an env-file is the way to get environment variables into the container.
+func WithEnvFile(f string) Option {
+	return func(o *args) error {
+		o.envFile = f
+
+		return nil
+	}
+}
+
+// WithVolume adds a volume (in the docker "src:dst[:opts]" format) to docker run operations.
+func WithVolume(v string) Option {
+	return func(o *args) error {
+		o.volumes = append(o.volumes, v)
+
+		return nil
+	}
+}
+
+// WithEngine sets the container Engine to use for operations, if not set docker is used.
+func WithEngine(e Engine) Option {
+	return func(o *args) error {
+		o.engine = e
+
+		return nil
+	}
+}
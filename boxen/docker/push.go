@@ -0,0 +1,144 @@
+package docker
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/carlmontanari/boxen/boxen/command"
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+// pushDigestPattern matches the digest in the (docker) push output, i.e.:
+// "latest: digest: sha256:abc... size: 1234".
+var pushDigestPattern = regexp.MustCompile( //nolint:gochecknoglobals
+	`digest: (sha256:[a-f0-9]{64})`,
+)
+
+// Tag tags the image repo:tag provided in the options as newRepo:newTag.
+func Tag(newRepo, newTag string, opts ...Option) error {
+	a := &args{}
+
+	for _, o := range opts {
+		err := o(a)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if a.repo == "" || a.tag == "" {
+		return fmt.Errorf("%w: repo and tag not provided, can't tag image", util.ErrValidationError)
+	}
+
+	cmdArgs := []string{
+		"tag",
+		fmt.Sprintf("%s:%s", a.repo, a.tag),
+		fmt.Sprintf("%s:%s", newRepo, newTag),
+	}
+
+	executeArgs := setExecuteArgs(a)
+
+	executeArgs = append(executeArgs, command.WithArgs(cmdArgs))
+
+	r, err := command.Execute(a.getEngine().Command(), executeArgs...)
+	if err != nil {
+		return err
+	}
+
+	return r.Proc.Wait()
+}
+
+// Push pushes the image repo:tag provided in the options to its registry and returns the digest of
+// the pushed image. Authentication is left to the engine, so whatever credentials were stored with
+// "docker login"/"podman login" (or a credential helper) are used.
+func Push(opts ...Option) (string, error) {
+	a := &args{}
+
+	for _, o := range opts {
+		err := o(a)
+
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if a.repo == "" || a.tag == "" {
+		return "", fmt.Errorf("%w: repo and tag not provided, can't push image", util.ErrValidationError)
+	}
+
+	digestFile, err := os.CreateTemp(os.TempDir(), "boxen-digest")
+	if err != nil {
+		return "", err
+	}
+
+	_ = digestFile.Close()
+
+	defer os.Remove(digestFile.Name())
+
+	stdout := &bytes.Buffer{}
+
+	if a.stdOut != nil {
+		a.stdOut = io.MultiWriter(a.stdOut, stdout)
+	} else {
+		a.stdOut = stdout
+	}
+
+	cmdArgs := []string{"push"}
+
+	cmdArgs = append(cmdArgs, a.getEngine().PushArgs(digestFile.Name())...)
+
+	cmdArgs = append(cmdArgs, fmt.Sprintf("%s:%s", a.repo, a.tag))
+
+	executeArgs := setExecuteArgs(a)
+
+	executeArgs = append(executeArgs, command.WithArgs(cmdArgs))
+
+	r, err := command.Execute(a.getEngine().Command(), executeArgs...)
+	if err != nil {
+		return "", err
+	}
+
+	err = r.Proc.Wait()
+	if err != nil {
+		return "", fmt.Errorf(
+			"%w: failed pushing image '%s:%s': %s",
+			util.ErrCommandError,
+			a.repo,
+			a.tag,
+			err,
+		)
+	}
+
+	digest, _ := os.ReadFile(digestFile.Name())
+
+	d := pushDigest(digest, stdout.Bytes())
+	if d == "" {
+		return "", fmt.Errorf(
+			"%w: could not determine digest of pushed image '%s:%s'",
+			util.ErrInspectionError,
+			a.repo,
+			a.tag,
+		)
+	}
+
+	return d, nil
+}
+
+// pushDigest returns the digest of a pushed image from the digest file written by the engine (if
+// any, podman), falling back to the digest in the push output (docker).
+func pushDigest(digestFile, out []byte) string {
+	if d := strings.TrimSpace(string(digestFile)); d != "" {
+		return d
+	}
+
+	m := pushDigestPattern.FindSubmatch(out)
+	if m == nil {
+		return ""
+	}
+
+	return string(m[1])
+}
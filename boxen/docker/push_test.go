@@ -0,0 +1,70 @@
+package docker
+
+import (
+	"testing"
+)
+
+const testPushDigest = "sha256:3f6c1d4be2a8e1a1c3a0b5f4d2c9e8b7a6f5e4d3c2b1a0f9e8d7c6b5a4f3e2d1"
+
+func TestPushDigest(t *testing.T) {
+	tests := []struct {
+		desc       string
+		digestFile string
+		out        string
+		want       string
+	}{
+		{
+			desc:       "podman digest file",
+			digestFile: testPushDigest,
+			out: "Getting image source signatures\n" +
+				"Copying blob sha256:5f70bf18a086007016e948b04aed3b82103a36bea41755b6cddfaf10ace3c6ef\n" +
+				"Copying config sha256:9b2cb4e1e6e0a2c43d6b1e1cb0a1d24f7c7c0e3c2e4b1a5f6d7c8b9a0e1f2d3c\n" +
+				"Writing manifest to image destination\n",
+			want: testPushDigest,
+		},
+		{
+			desc:       "digest file with trailing newline",
+			digestFile: testPushDigest + "\n",
+			want:       testPushDigest,
+		},
+		{
+			desc: "docker push output",
+			out: "The push refers to repository [localhost:5000/boxen_arista_veos]\n" +
+				"a4d8a7c1b2e3: Pushed\n" +
+				"5f70bf18a086: Layer already exists\n" +
+				"4.22.1F: digest: " + testPushDigest + " size: 1154\n",
+			want: testPushDigest,
+		},
+		{
+			desc:       "digest file takes precedence",
+			digestFile: testPushDigest,
+			out: "latest: digest: sha256:" +
+				"0000000000000000000000000000000000000000000000000000000000000000 size: 1154\n",
+			want: testPushDigest,
+		},
+		{
+			desc: "no digest",
+			out:  "The push refers to repository [localhost:5000/boxen_arista_veos]\n",
+			want: "",
+		},
+		{
+			desc: "truncated digest",
+			out:  "latest: digest: sha256:3f6c1d4be2a8 size: 1154\n",
+			want: "",
+		},
+		{
+			desc: "empty",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := pushDigest([]byte(tt.digestFile), []byte(tt.out))
+
+			if got != tt.want {
+				t.Fatalf("%s: got digest %q, want %q", tt.desc, got, tt.want)
+			}
+		})
+	}
+}
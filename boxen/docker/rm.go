@@ -23,7 +23,7 @@ func RmImage(repo, tag string, opts ...Option) error {
 
 	executeArgs = append(executeArgs, command.WithArgs(cmdArgs))
 
-	r, err := command.Execute(dockerCmd, executeArgs...)
+	r, err := command.Execute(a.getEngine().Command(), executeArgs...)
 	if err != nil {
 		return err
 	}
@@ -48,7 +48,7 @@ func RmContainer(container string, opts ...Option) error {
 
 	executeArgs = append(executeArgs, command.WithArgs(cmdArgs))
 
-	r, err := command.Execute(dockerCmd, executeArgs...)
+	r, err := command.Execute(a.getEngine().Command(), executeArgs...)
 	if err != nil {
 		return err
 	}
@@ -2,6 +2,8 @@ package docker
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 
 	"github.com/carlmontanari/boxen/boxen/command"
 )
@@ -24,6 +26,15 @@ func Run(opts ...Option) (*command.Result, error) {
 	cmdArgs := []string{"run"}
 
 	if a.cidFile != "" {
+		// docker and podman both refuse to run if the cidfile already exists, so clean up any stale
+		// cidfile (from a previous failed run) first
+		cidFile := a.cidFile
+		if !filepath.IsAbs(cidFile) {
+			cidFile = filepath.Join(a.workDir, cidFile)
+		}
+
+		_ = os.Remove(cidFile)
+
 		cmdArgs = append(cmdArgs, "--cidfile", a.cidFile)
 	}
 
@@ -31,13 +42,21 @@ func Run(opts ...Option) (*command.Result, error) {
 		cmdArgs = append(cmdArgs, "--privileged")
 	}
 
+	if a.envFile != "" {
+		cmdArgs = append(cmdArgs, "--env-file", a.envFile)
+	}
+
+	for _, v := range a.volumes {
+		cmdArgs = append(cmdArgs, "--volume", v)
+	}
+
 	cmdArgs = append(cmdArgs, fmt.Sprintf("%s:%s", a.repo, a.tag))
 
 	executeArgs := setExecuteArgs(a)
 
 	executeArgs = append(executeArgs, command.WithArgs(cmdArgs))
 
-	r, err := command.Execute(dockerCmd, executeArgs...)
+	r, err := command.Execute(a.getEngine().Command(), executeArgs...)
 	if err != nil {
 		return r, err
 	}
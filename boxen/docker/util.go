@@ -1,12 +1,42 @@
 package docker
 
 import (
+	"fmt"
 	"os"
 	"strings"
+	"time"
+
+	"github.com/carlmontanari/boxen/boxen/util"
 )
 
+const cidFilePollInterval = 250
+
 // ReadCidFile loads provided cidfile f and returns trimmed string contents.
 func ReadCidFile(f string) string {
 	o, _ := os.ReadFile(f)
 	return strings.TrimSpace(string(o))
 }
+
+// WaitCidFile waits up to timeout for the cidfile f to be written and returns the container id.
+// The cidfile is only written once the container has been created, with podman in particular this
+// can be a bit after the run command has been executed.
+func WaitCidFile(f string, timeout time.Duration) (string, error) {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		cid := ReadCidFile(f)
+		if cid != "" {
+			return cid, nil
+		}
+
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf(
+				"%w: timed out waiting for container id file '%s'",
+				util.ErrCommandError,
+				f,
+			)
+		}
+
+		time.Sleep(cidFilePollInterval * time.Millisecond)
+	}
+}
@@ -1,6 +1,8 @@
 package docker
 
 import (
+	"bytes"
+
 	"github.com/carlmontanari/boxen/boxen/command"
 )
 
@@ -26,10 +28,33 @@ func Wait(opts ...Option) error {
 
 	executeArgs = append(executeArgs, command.WithArgs(cmdArgs))
 
-	r, err := command.Execute(dockerCmd, executeArgs...)
+	r, err := command.Execute(a.getEngine().Command(), executeArgs...)
 	if err != nil {
 		return err
 	}
 
 	return r.Proc.Wait()
 }
+
+// WaitExitCode runs the "wait" command for a provided container ID (as provided in options) and
+// returns the exit code of the container, as parsed by the Engine.
+func WaitExitCode(opts ...Option) (int, error) {
+	a := &args{}
+
+	for _, o := range opts {
+		err := o(a)
+
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	waitStdout := &bytes.Buffer{}
+
+	err := Wait(append(opts, WithStdOut(waitStdout))...)
+	if err != nil {
+		return 0, err
+	}
+
+	return a.getEngine().ExitCode(waitStdout.Bytes())
+}
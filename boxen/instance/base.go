@@ -9,5 +9,7 @@ type Base interface {
 	Install(...Option) error
 	Start(...Option) error
 	Stop(...Option) error
+	DryRun(...Option) (*LaunchCmdExplain, error)
+	StartHealthServer()
 	RunUntilSigInt()
 }
@@ -9,10 +9,17 @@ const (
 	AccelHAX = "hax"
 	// AccelNone represents no acceleration.
 	AccelNone = "none"
+	// AccelTCG represents the qemu TCG (software emulation) accelerator, only used as a fallback
+	// when none of the preferred accelerators are available.
+	AccelTCG = "tcg"
+	// tcgTimeoutScale is what all timeouts are multiplied by when an instance falls back to tcg.
+	tcgTimeoutScale = 4
 	// OptNone represents a literal string "none" to be used for some qemu command options.
 	OptNone = "none"
 	// OptMachinePc represents the Qemu "machine" type of "pc".
 	OptMachinePc = "pc"
+	// OptMachineQ35 represents the Qemu "machine" type of "q35".
+	OptMachineQ35 = "q35"
 	// NicE1000 represents an E1000 virtual nic.
 	NicE1000 = "e1000"
 	// NicVirtio represents an virtio-net-pci virtual nic.
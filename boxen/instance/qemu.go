@@ -2,7 +2,6 @@ package instance
 
 import (
 	"bytes"
-	"errors"
 	"fmt"
 	"os/exec"
 	"strconv"
@@ -18,7 +17,8 @@ type Qemu struct {
 	ID   int
 	PID  int
 
-	Qemu *config.Qemu
+	Qemu    *config.Qemu
+	Console *config.Console
 
 	Proc *exec.Cmd
 
@@ -31,8 +31,32 @@ type Qemu struct {
 	DataPlaneIntf *config.DataPlaneIntf
 
 	LaunchCmd *QemuLaunchCmd
+	// caps are the capabilities of the qemu binary, probed at start.
+	caps *QemuCapabilities
 
 	Loggers *Loggers
+
+	health qemuHealth
+}
+
+// ResolveCredentials resolves any credential sources of the instance credentials and returns the
+// resolved credentials. Sources are resolved lazily -- when the console logs in or a config is
+// rendered -- so building an instance (i.e. to stop it) never runs a credential command or needs a
+// secret to be available. The resolved values only ever live on the instance, the config retains
+// the source references so nothing sensitive is written back to disk.
+func (i *Qemu) ResolveCredentials() (*config.Credentials, error) {
+	if i.Credentials == nil || len(i.Credentials.Sources()) == 0 {
+		return i.Credentials, nil
+	}
+
+	creds, err := i.Credentials.Resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	i.Credentials = creds
+
+	return creds, nil
 }
 
 // NewQemu returns a new "blank" qemu instance based on the provided instance name and boxen Config
@@ -43,6 +67,7 @@ func NewQemu(n string, c *config.Config, l *Loggers) (*Qemu, error) {
 		ID:            c.Instances[n].ID,
 		PID:           c.Instances[n].PID,
 		Qemu:          c.Options.Qemu,
+		Console:       c.Options.Console,
 		Credentials:   c.Instances[n].Credentials,
 		Disk:          c.Instances[n].Disk,
 		Hardware:      c.Instances[n].Hardware,
@@ -52,6 +77,17 @@ func NewQemu(n string, c *config.Config, l *Loggers) (*Qemu, error) {
 		Loggers:       l,
 	}
 
+	if i.Advanced != nil && i.Advanced.QemuBinary != "" {
+		// copy, the global qemu options are shared by all instances
+		q := config.Qemu{}
+		if i.Qemu != nil {
+			q = *i.Qemu
+		}
+
+		q.Binary = i.Advanced.QemuBinary
+		i.Qemu = &q
+	}
+
 	if i.PID > 0 && !i.validatePid() {
 		// failed to validate a stored pid; we'll assume it's not running
 		i.PID = -1
@@ -65,9 +101,23 @@ func NewQemu(n string, c *config.Config, l *Loggers) (*Qemu, error) {
 		return i, nil
 	}
 
-	profile, ok := c.Platforms[c.Instances[n].PlatformType].Profiles[c.Instances[n].Profile]
+	var profile *config.Profile
+
+	ok := false
+
+	if platform := c.Platforms[c.Instances[n].PlatformType]; platform != nil {
+		profile, ok = platform.Profiles[c.Instances[n].Profile]
+	}
+
 	if !ok {
-		panic("unknown profile for instance, cannot continue")
+		return nil, fmt.Errorf(
+			"%w: instance '%s' uses profile '%s' which does not exist for platform type '%s', "+
+				"check the profiles of the platform in the boxen config",
+			util.ErrValidationError,
+			n,
+			c.Instances[n].Profile,
+			c.Instances[n].PlatformType,
+		)
 	}
 
 	mergeProfileHardware(profile.Hardware, i)
@@ -98,28 +148,51 @@ func mergeProfileHardware(
 	if i.Hardware.NicPerBus == 0 && hw.NicPerBus > 0 {
 		i.Hardware.NicPerBus = hw.NicPerBus
 	}
+
+	if len(i.Hardware.Disks) == 0 && len(hw.Disks) > 0 {
+		i.Hardware.Disks = hw.Disks
+	}
 }
 
-func (i *Qemu) buildLaunchCmd() {
+func (i *Qemu) buildLaunchCmd() error {
 	if i.LaunchCmd != nil {
 		i.LaunchCmd = nil
 	}
 
+	accel, err := i.launchCmdAccel()
+	if err != nil {
+		return err
+	}
+
 	i.LaunchCmd = &QemuLaunchCmd{
-		Name:    []string{"-name", i.Name},
+		Name:    i.Name,
 		UUID:    i.launchCmdUUID(),
-		Accel:   i.launchCmdAccel(),
+		Accel:   accel,
 		Display: i.launchCmdDisplay(),
+		VNC:     i.launchCmdVNC(),
 		Machine: i.launchCmdMachine(),
 		Memory:  i.launchCmdMemory(),
 		CPU:     i.launchCmdCPU(),
 		Monitor: i.launchCmdMonitor(),
 		Serial:  i.launchCmdSerial(),
-		Disk:    i.launchCmdDisk(),
 		Pci:     i.launchCmdPci(),
-		MgmtNic: i.launchCmdMgmtNic(),
 		DataNic: i.launchCmdDataNic(),
 	}
+
+	i.LaunchCmd.MgmtNic, err = i.launchCmdMgmtNic()
+	if err != nil {
+		return err
+	}
+
+	i.LaunchCmd.Firmware, i.LaunchCmd.Global = i.launchCmdFirmware()
+	i.LaunchCmd.DiskController, i.LaunchCmd.Disk = i.launchCmdDisk()
+
+	// host cpu passthrough requires hardware acceleration, tcg emulates as much as it can instead
+	if i.LaunchCmd.usesTCG() && i.LaunchCmd.CPU != nil && i.LaunchCmd.CPU.Model == "host" {
+		i.LaunchCmd.CPU.Model = "max"
+	}
+
+	return nil
 }
 
 // Install "installs" a qemu disk -- meaning it runs through any "initial config" type processes for
@@ -147,36 +220,95 @@ func (i *Qemu) Start(opts ...Option) error {
 	if !diskExists {
 		i.Loggers.Base.Critical("cannot start, source disk does not exist")
 
-		panic(
-			"disk doesnt exist, in the future, depending on operation mode we will " +
-				"copy from the base disks here",
+		return fmt.Errorf(
+			"%w: instance disk '%s' does not exist, check the source disk of the instance is "+
+				"installed (see 'boxen install')",
+			util.ErrInstanceError,
+			i.Disk,
 		)
 	}
 
-	i.buildLaunchCmd()
+	err := config.ValidateDisks(i.Hardware.Disks)
+	if err != nil {
+		i.Loggers.Base.Criticalf("cannot start, invalid disks: %s", err)
 
-	qOpts := &qemuOpts{}
+		return err
+	}
 
-	for _, option := range opts {
-		err := option(qOpts)
+	err = i.CheckHostCapabilities()
+	if err != nil {
+		i.Loggers.Base.Criticalf("cannot start, host capability check failed: %s", err)
 
-		if err != nil {
-			if errors.Is(err, util.ErrIgnoredOption) {
-				continue
-			} else {
-				return err
-			}
-		}
+		return err
+	}
+
+	err = i.prepareFirmware()
+	if err != nil {
+		i.Loggers.Base.Criticalf("cannot start, invalid firmware: %s", err)
+
+		return err
+	}
+
+	i.caps, err = ProbeQemuCapabilities(i.Qemu.Binary)
+	if err != nil {
+		i.Loggers.Base.Criticalf("cannot start, failed probing qemu binary: %s", err)
+
+		return err
+	}
+
+	i.Loggers.Base.Debugf("using qemu binary '%s' version %s", i.caps.Binary, i.caps.Version)
+
+	err = i.buildLaunchCmd()
+	if err != nil {
+		i.Loggers.Base.Criticalf("cannot start, failed building launch command: %s", err)
+
+		return err
+	}
+
+	if i.LaunchCmd.usesTCG() {
+		i.Loggers.Base.Criticalf(
+			"warning: none of the preferred acceleration type(s) %v are available, falling back to "+
+				"tcg -- the instance will be much slower, all timeouts are scaled by %d",
+			i.Hardware.Acceleration,
+			tcgTimeoutScale,
+		)
+
+		util.ScaleTimeouts(tcgTimeoutScale)
+	}
+
+	qOpts, err := newQemuOpts(opts...)
+	if err != nil {
+		return err
+	}
+
+	err = i.startConsoles()
+	if err != nil {
+		i.Loggers.Base.Criticalf("cannot start, failed starting console multiplexers: %s", err)
+
+		i.stopConsoles()
+
+		return err
 	}
 
 	if qOpts.launchModifier != nil {
 		qOpts.launchModifier(i.LaunchCmd)
 	}
 
+	err = i.caps.Validate(i.LaunchCmd)
+	if err != nil {
+		i.Loggers.Base.Criticalf("cannot start, launch command not supported by qemu: %s", err)
+
+		i.stopConsoles()
+
+		return err
+	}
+
 	launchCmd, err := i.LaunchCmd.Render()
 	if err != nil {
 		i.Loggers.Base.Critical("failure rendering launch command, cannot continue")
 
+		i.stopConsoles()
+
 		return err
 	}
 
@@ -203,27 +335,81 @@ func (i *Qemu) Start(opts ...Option) error {
 	if err != nil {
 		i.Loggers.Base.Criticalf("error executing launch command: %s\n", err)
 
+		i.stopConsoles()
+
 		return err
 	}
 
+	i.Proc = r.Proc
+	i.PID = i.Proc.Process.Pid
+
 	err = r.CheckStdErr(command.WithIgnore([][]byte{[]byte("CPUID")}))
 	if err != nil {
 		i.Loggers.Base.Critical("unknown stderr on instance launch, cannot continue")
+
+		i.abortStart()
+
 		return err
 	}
 
-	i.Proc = r.Proc
-	i.PID = i.Proc.Process.Pid
+	err = i.pinCPUs()
+	if err != nil {
+		i.Loggers.Base.Criticalf("error pinning instance vCPUs: %s", err)
+
+		i.abortStart()
+
+		return err
+	}
+
+	err = i.setVNCPassword()
+	if err != nil {
+		i.Loggers.Base.Criticalf("error setting vnc password: %s", err)
+
+		i.abortStart()
+
+		return err
+	}
 
 	i.Loggers.Base.Info("qemu instance start complete")
 
 	return nil
 }
 
+// abortStart tears down an instance that was launched but failed to start completely: qemu is
+// killed and the console multiplexers and cpuset are removed, so the instance can be started again.
+func (i *Qemu) abortStart() {
+	if i.PID > 0 {
+		_, err := command.Execute(
+			"kill",
+			command.WithArgs([]string{strconv.Itoa(i.PID)}),
+			command.WithWait(true),
+			command.WithSudo(true),
+		)
+		if err != nil {
+			i.Loggers.Base.Criticalf("error killing failed instance: %s", err)
+		}
+	}
+
+	i.PID = 0
+	i.Proc = nil
+
+	i.stopConsoles()
+	i.removeCpuset()
+}
+
 func (i *Qemu) validatePid() bool {
+	return ProcessRunning(i.PID, i.Name)
+}
+
+// ProcessRunning returns true if the process pid is running and belongs to the instance name.
+func ProcessRunning(pid int, name string) bool {
+	if pid < 1 {
+		return false
+	}
+
 	r, err := command.Execute(
 		"ps",
-		command.WithArgs([]string{"-axf", strconv.Itoa(i.PID)}),
+		command.WithArgs([]string{"-axf", strconv.Itoa(pid)}),
 		command.WithWait(true),
 	)
 	if err != nil {
@@ -232,7 +418,7 @@ func (i *Qemu) validatePid() bool {
 
 	stdoutOutput, _ := r.ReadStdout()
 
-	return bytes.Contains(stdoutOutput, []byte(i.Name))
+	return bytes.Contains(stdoutOutput, []byte(name))
 }
 
 // Stop stops the qemu virtual machine.
@@ -259,21 +445,12 @@ func (i *Qemu) Stop(opts ...Option) error {
 		return fmt.Errorf("%w: %s", util.ErrInstanceError, msg)
 	}
 
-	qOpts := &qemuOpts{}
-
-	for _, option := range opts {
-		err := option(qOpts)
-
-		if err != nil {
-			if errors.Is(err, util.ErrIgnoredOption) {
-				continue
-			} else {
-				return err
-			}
-		}
+	qOpts, err := newQemuOpts(opts...)
+	if err != nil {
+		return err
 	}
 
-	_, err := command.Execute(
+	_, err = command.Execute(
 		"kill",
 		command.WithArgs([]string{strconv.Itoa(i.PID)}),
 		command.WithWait(true),
@@ -288,6 +465,9 @@ func (i *Qemu) Stop(opts ...Option) error {
 	i.PID = 0
 	i.Proc = nil
 
+	i.stopConsoles()
+	i.removeCpuset()
+
 	// eventually delete instance dir if instance mode is not persist
 	i.Loggers.Base.Info("qemu instance stop complete")
 
@@ -0,0 +1,241 @@
+package instance_test
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/carlmontanari/boxen/boxen/config"
+	"github.com/carlmontanari/boxen/boxen/instance"
+	"github.com/carlmontanari/boxen/boxen/logging"
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+func testLoggers(t *testing.T) *instance.Loggers {
+	t.Helper()
+
+	l, err := logging.NewInstance(func(...interface{}) {})
+	if err != nil {
+		t.Fatalf("failed creating logger: %s", err)
+	}
+
+	return &instance.Loggers{Base: l}
+}
+
+func TestNewQemuUnknownProfile(t *testing.T) {
+	tests := []struct {
+		desc      string
+		platforms map[string]*config.Platform
+	}{
+		{
+			desc:      "unknown platform type",
+			platforms: map[string]*config.Platform{},
+		},
+		{
+			desc: "unknown profile",
+			platforms: map[string]*config.Platform{
+				"arista_veos": {Profiles: map[string]*config.Profile{}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c := &config.Config{
+				Options: &config.GlobalOptions{Qemu: &config.Qemu{}},
+				Instances: map[string]*config.Instance{
+					"veos1": {
+						Name:         "veos1",
+						PlatformType: "arista_veos",
+						Profile:      "nope",
+						Hardware:     &config.Hardware{},
+					},
+				},
+				Platforms: tt.platforms,
+			}
+
+			_, err := instance.NewQemu("veos1", c, testLoggers(t))
+			if !errors.Is(err, util.ErrValidationError) {
+				t.Fatalf("%s: expected validation error, got: %v", tt.desc, err)
+			}
+		})
+	}
+}
+
+func TestQemuStartMissingDisk(t *testing.T) {
+	q := &instance.Qemu{
+		Name:     "veos1",
+		Disk:     filepath.Join(t.TempDir(), "disk.qcow2"),
+		Qemu:     &config.Qemu{},
+		Hardware: &config.Hardware{},
+		Loggers:  testLoggers(t),
+	}
+
+	err := q.Start()
+	if !errors.Is(err, util.ErrInstanceError) {
+		t.Fatalf("expected instance error, got: %v", err)
+	}
+}
+
+func TestLaunchCmdMgmtNicNoNatOrBridge(t *testing.T) {
+	tests := []struct {
+		desc     string
+		mgmtIntf *config.MgmtIntf
+	}{
+		{
+			desc:     "no mgmt interface",
+			mgmtIntf: nil,
+		},
+		{
+			desc:     "neither nat nor bridge",
+			mgmtIntf: &config.MgmtIntf{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			q := &instance.Qemu{
+				Name: "veos1",
+				Disk: filepath.Join(t.TempDir(), "disk.qcow2"),
+				Qemu: &config.Qemu{Acceleration: []string{instance.AccelNone}},
+				Hardware: &config.Hardware{
+					Acceleration: []string{instance.AccelNone},
+					NicType:      instance.NicE1000,
+					NicPerBus:    1,
+				},
+				MgmtIntf: tt.mgmtIntf,
+				Loggers:  testLoggers(t),
+			}
+
+			// the dry run builds the launch command exactly like start does
+			_, err := q.DryRun()
+			if !errors.Is(err, util.ErrValidationError) {
+				t.Fatalf("%s: expected validation error, got: %v", tt.desc, err)
+			}
+		})
+	}
+}
+
+func TestNewQemuResolvesCredentialsLazily(t *testing.T) {
+	src := &config.CredentialSource{Env: "BOXEN_TEST_LAZY_PASSWORD"}
+
+	c := &config.Config{
+		Options: &config.GlobalOptions{
+			Qemu:        &config.Qemu{},
+			Credentials: &config.Credentials{Username: "boxen", PasswordFrom: src},
+		},
+		Instances: map[string]*config.Instance{
+			"veos1": {
+				Name:         "veos1",
+				PlatformType: "arista_veos",
+				Hardware:     &config.Hardware{},
+			},
+		},
+	}
+
+	// the source can't be resolved, but building the instance doesn't need the credentials
+	q, err := instance.NewQemu("veos1", c, testLoggers(t))
+	if err != nil {
+		t.Fatalf("failed building instance: %s", err)
+	}
+
+	_, err = q.ResolveCredentials()
+	if !errors.Is(err, util.ErrValidationError) {
+		t.Fatalf("expected validation error resolving unset env var, got: %v", err)
+	}
+
+	t.Setenv("BOXEN_TEST_LAZY_PASSWORD", "s3cr3t")
+
+	creds, err := q.ResolveCredentials()
+	if err != nil {
+		t.Fatalf("failed resolving credentials: %s", err)
+	}
+
+	if creds.Username != "boxen" || creds.Password != "s3cr3t" || q.Credentials != creds {
+		t.Fatalf("unexpected resolved credentials: %+v", creds)
+	}
+
+	if c.Options.Credentials.Password != "" || c.Options.Credentials.PasswordFrom != src {
+		t.Fatalf("config credentials modified: %+v", c.Options.Credentials)
+	}
+}
+
+func TestPciAddr(t *testing.T) {
+	tests := []struct {
+		desc        string
+		slot        int
+		wantBusID   int
+		wantBusAddr int
+		wantBridges int
+	}{
+		{
+			desc:        "first slot",
+			slot:        1,
+			wantBusID:   1,
+			wantBusAddr: 2,
+			wantBridges: 1,
+		},
+		{
+			desc:        "last slot on the first bridge",
+			slot:        25,
+			wantBusID:   1,
+			wantBusAddr: 26,
+			wantBridges: 1,
+		},
+		{
+			desc:        "first slot on the second bridge",
+			slot:        26,
+			wantBusID:   2,
+			wantBusAddr: 1,
+			wantBridges: 2,
+		},
+		{
+			desc:        "second slot on the second bridge",
+			slot:        27,
+			wantBusID:   2,
+			wantBusAddr: 2,
+			wantBridges: 2,
+		},
+		{
+			desc:        "first slot on the third bridge",
+			slot:        52,
+			wantBusID:   3,
+			wantBusAddr: 1,
+			wantBridges: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			q := &instance.Qemu{Hardware: &config.Hardware{NicPerBus: 26}}
+
+			busID, busAddr := q.PciAddr(tt.slot)
+			if busID != tt.wantBusID || busAddr != tt.wantBusAddr {
+				t.Fatalf(
+					"%s: got bus %d addr %d, want bus %d addr %d",
+					tt.desc,
+					busID,
+					busAddr,
+					tt.wantBusID,
+					tt.wantBusAddr,
+				)
+			}
+
+			bridges := q.BuildPci(tt.slot)
+			if len(bridges) != tt.wantBridges {
+				t.Fatalf("%s: got %d pci bridges, want %d", tt.desc, len(bridges), tt.wantBridges)
+			}
+
+			// the slot's bridge must be one of the bridges built
+			if bridges[len(bridges)-1].ID != fmt.Sprintf("pci.%d", busID) {
+				t.Fatalf(
+					"%s: last bridge '%s' does not hold bus %d",
+					tt.desc,
+					bridges[len(bridges)-1].ID,
+					busID,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,359 @@
+package instance
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+const (
+	// QemuCapabilitiesCacheDir is the directory probed qemu capabilities are cached in, one file per
+	// qemu binary.
+	QemuCapabilitiesCacheDir = "~/boxen/cache/qemu"
+
+	// minQemuMajor and minQemuMinor are the oldest qemu version boxen launches instances with, the
+	// version the packaging image pins.
+	minQemuMajor = 4
+	minQemuMinor = 2
+)
+
+var (
+	qemuVersionPattern = regexp.MustCompile(`version (\d+)\.(\d+)\.(\d+)`) //nolint:gochecknoglobals
+	qemuDevicePattern  = regexp.MustCompile(`(?:name|alias) "([^"]+)"`)    //nolint:gochecknoglobals
+
+	qemuCapabilitiesCache     = map[string]*QemuCapabilities{} //nolint:gochecknoglobals
+	qemuCapabilitiesCacheLock = &sync.Mutex{}                  //nolint:gochecknoglobals
+)
+
+// QemuCapabilities is what a qemu binary supports, as probed from its help output.
+type QemuCapabilities struct {
+	Binary string `json:"binary"`
+	// Size and ModTime identify the binary the capabilities were probed from, the cache entry is
+	// stale once either changes (i.e. qemu was upgraded).
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mod_time"`
+	Version string `json:"version"`
+	Major   int    `json:"major"`
+	Minor   int    `json:"minor"`
+	Micro   int    `json:"micro"`
+	// Machines are the machine types (and aliases, i.e. "pc" and "q35"), Devices the device drivers
+	// and Accels the accelerators of the binary.
+	Machines []string `json:"machines"`
+	Devices  []string `json:"devices"`
+	Accels   []string `json:"accels"`
+}
+
+// AtLeast returns true if the qemu version is at least major.minor.
+func (c *QemuCapabilities) AtLeast(major, minor int) bool {
+	return c.Major > major || (c.Major == major && c.Minor >= minor)
+}
+
+func qemuCapabilitiesCacheFile(binary string) string {
+	h := sha256.Sum256([]byte(binary))
+
+	return filepath.Join(
+		util.ExpandPath(QemuCapabilitiesCacheDir),
+		hex.EncodeToString(h[:8])+".json",
+	)
+}
+
+// loadQemuCapabilities returns the cached capabilities of the binary, or nil if there are none or
+// the binary changed since they were probed.
+func loadQemuCapabilities(binary string, fi os.FileInfo) *QemuCapabilities {
+	c, ok := qemuCapabilitiesCache[binary]
+
+	if !ok {
+		raw, err := os.ReadFile(qemuCapabilitiesCacheFile(binary))
+		if err != nil {
+			return nil
+		}
+
+		c = &QemuCapabilities{}
+
+		err = json.Unmarshal(raw, c)
+		if err != nil {
+			return nil
+		}
+	}
+
+	if c.Binary != binary || c.Size != fi.Size() || c.ModTime != fi.ModTime().UnixNano() {
+		return nil
+	}
+
+	return c
+}
+
+// storeQemuCapabilities caches the capabilities in memory and on disk, failing to write the cache
+// file only means the binary is probed again next time.
+func storeQemuCapabilities(c *QemuCapabilities) {
+	qemuCapabilitiesCache[c.Binary] = c
+
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return
+	}
+
+	f := qemuCapabilitiesCacheFile(c.Binary)
+
+	err = os.MkdirAll(filepath.Dir(f), os.ModePerm)
+	if err != nil {
+		return
+	}
+
+	_ = os.WriteFile(f, raw, util.FilePerms)
+}
+
+// ProbeQemuCapabilities returns the capabilities of the qemu binary, probed from "-version",
+// "-machine help", "-device help" and "-accel help". Capabilities are cached per binary.
+func ProbeQemuCapabilities(binary string) (*QemuCapabilities, error) {
+	if binary == "" {
+		return nil, fmt.Errorf(
+			"%w: no qemu binary configured and qemu-system-x86_64 not found in path",
+			util.ErrInstanceError,
+		)
+	}
+
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return nil, fmt.Errorf("%w: qemu binary '%s' not found", util.ErrInstanceError, binary)
+	}
+
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	qemuCapabilitiesCacheLock.Lock()
+	defer qemuCapabilitiesCacheLock.Unlock()
+
+	if c := loadQemuCapabilities(path, fi); c != nil {
+		return c, nil
+	}
+
+	c := &QemuCapabilities{
+		Binary:  path,
+		Size:    fi.Size(),
+		ModTime: fi.ModTime().UnixNano(),
+	}
+
+	out, err := probeQemu(path, "-version")
+	if err != nil {
+		return nil, err
+	}
+
+	m := qemuVersionPattern.FindStringSubmatch(out)
+	if m == nil {
+		return nil, fmt.Errorf(
+			"%w: failed parsing version of qemu binary '%s' from '%s'",
+			util.ErrInstanceError,
+			path,
+			strings.TrimSpace(out),
+		)
+	}
+
+	c.Major, _ = strconv.Atoi(m[1])
+	c.Minor, _ = strconv.Atoi(m[2])
+	c.Micro, _ = strconv.Atoi(m[3])
+	c.Version = fmt.Sprintf("%d.%d.%d", c.Major, c.Minor, c.Micro)
+
+	out, err = probeQemu(path, "-machine", "help")
+	if err != nil {
+		return nil, err
+	}
+
+	c.Machines = parseQemuMachines(out)
+
+	out, err = probeQemu(path, "-device", "help")
+	if err != nil {
+		return nil, err
+	}
+
+	c.Devices = parseQemuDevices(out)
+
+	out, err = probeQemu(path, "-accel", "help")
+	if err != nil {
+		return nil, err
+	}
+
+	c.Accels = parseQemuAccels(out)
+
+	storeQemuCapabilities(c)
+
+	return c, nil
+}
+
+func probeQemu(binary string, args ...string) (string, error) {
+	out, err := exec.Command(binary, args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf(
+			"%w: failed probing qemu binary '%s' with '%s': %s (%s)",
+			util.ErrInstanceError,
+			binary,
+			strings.Join(args, " "),
+			err,
+			bytes.TrimSpace(out),
+		)
+	}
+
+	return string(out), nil
+}
+
+// parseQemuMachines parses "-machine help" output, the first word of every line after the header
+// is a machine type, aliases are listed as their own machine type.
+func parseQemuMachines(out string) []string {
+	var machines []string
+
+	s := bufio.NewScanner(strings.NewReader(out))
+
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+
+		if len(fields) == 0 || strings.HasSuffix(s.Text(), ":") {
+			continue
+		}
+
+		machines = append(machines, fields[0])
+	}
+
+	return machines
+}
+
+// parseQemuDevices parses "-device help" output, every device driver is listed by name and
+// optionally an alias, both are valid drivers.
+func parseQemuDevices(out string) []string {
+	var devices []string
+
+	for _, m := range qemuDevicePattern.FindAllStringSubmatch(out, -1) {
+		devices = append(devices, m[1])
+	}
+
+	return devices
+}
+
+// parseQemuAccels parses "-accel help" output, newer versions list one accelerator per line after
+// the header, older versions list them comma separated after the header on the same line.
+func parseQemuAccels(out string) []string {
+	var accels []string
+
+	s := bufio.NewScanner(strings.NewReader(out))
+
+	for s.Scan() {
+		line := s.Text()
+
+		if idx := strings.Index(line, ":"); idx >= 0 {
+			line = line[idx+1:]
+		}
+
+		for _, a := range strings.Split(line, ",") {
+			a = strings.TrimSpace(a)
+
+			if a != "" {
+				accels = append(accels, a)
+			}
+		}
+	}
+
+	return accels
+}
+
+// launchCmdDevices returns all devices of the launch command.
+func launchCmdDevices(cmd *QemuLaunchCmd) []*Device {
+	devices := append([]*Device{}, cmd.DiskController...)
+
+	for _, d := range cmd.Disk {
+		devices = append(devices, d.Device)
+	}
+
+	devices = append(devices, cmd.Pci...)
+
+	nics := append([]*Nic{cmd.MgmtNic}, cmd.AuxNic...)
+	nics = append(nics, cmd.DataNic...)
+
+	for _, n := range nics {
+		if n != nil {
+			devices = append(devices, n.Device)
+		}
+	}
+
+	for _, e := range cmd.Extra {
+		if d, ok := e.(*Device); ok {
+			devices = append(devices, d)
+		}
+	}
+
+	return devices
+}
+
+// Validate checks the launch command only uses the version, machine type, accelerator and devices
+// the qemu binary supports.
+func (c *QemuCapabilities) Validate(cmd *QemuLaunchCmd) error {
+	if !c.AtLeast(minQemuMajor, minQemuMinor) {
+		return fmt.Errorf(
+			"%w: qemu binary '%s' is version %s, at least %d.%d is required",
+			util.ErrValidationError,
+			c.Binary,
+			c.Version,
+			minQemuMajor,
+			minQemuMinor,
+		)
+	}
+
+	if cmd.Accel != "" {
+		accel := strings.Split(cmd.Accel, ",")[0]
+
+		if !util.StringSliceContains(accel, c.Accels) {
+			return fmt.Errorf(
+				"%w: qemu binary '%s' (%s) does not support accelerator '%s', supported: %s",
+				util.ErrValidationError,
+				c.Binary,
+				c.Version,
+				accel,
+				strings.Join(c.Accels, ", "),
+			)
+		}
+	}
+
+	if cmd.Machine != nil && !util.StringSliceContains(cmd.Machine.Type, c.Machines) {
+		return fmt.Errorf(
+			"%w: qemu binary '%s' (%s) does not support machine type '%s'",
+			util.ErrValidationError,
+			c.Binary,
+			c.Version,
+			cmd.Machine.Type,
+		)
+	}
+
+	for _, d := range launchCmdDevices(cmd) {
+		if d == nil || util.StringSliceContains(d.Driver, c.Devices) {
+			continue
+		}
+
+		return fmt.Errorf(
+			"%w: qemu binary '%s' (%s) does not support device '%s' (id '%s')",
+			util.ErrValidationError,
+			c.Binary,
+			c.Version,
+			d.Driver,
+			d.ID,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,342 @@
+package instance
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/carlmontanari/boxen/boxen/util"
+	"github.com/google/go-cmp/cmp"
+)
+
+// readQemuHelp returns the captured output of "qemu-system-x86_64 <probe>" of the given version.
+func readQemuHelp(t *testing.T, version, probe string) string {
+	t.Helper()
+
+	b, err := os.ReadFile(filepath.Join("testdata", "qemu-"+version+"-"+probe+".txt"))
+	if err != nil {
+		t.Fatalf("failed reading captured qemu output: %s", err)
+	}
+
+	return string(b)
+}
+
+// testQemuCapabilities returns the capabilities parsed from the captured output of the version.
+func testQemuCapabilities(t *testing.T, version string) *QemuCapabilities {
+	t.Helper()
+
+	m := qemuVersionPattern.FindStringSubmatch(readQemuHelp(t, version, "version"))
+	if m == nil {
+		t.Fatalf("failed parsing captured qemu %s version", version)
+	}
+
+	c := &QemuCapabilities{
+		Binary:   "qemu-system-x86_64",
+		Version:  m[1] + "." + m[2] + "." + m[3],
+		Machines: parseQemuMachines(readQemuHelp(t, version, "machine-help")),
+		Devices:  parseQemuDevices(readQemuHelp(t, version, "device-help")),
+		Accels:   parseQemuAccels(readQemuHelp(t, version, "accel-help")),
+	}
+
+	c.Major, _ = strconv.Atoi(m[1])
+	c.Minor, _ = strconv.Atoi(m[2])
+	c.Micro, _ = strconv.Atoi(m[3])
+
+	return c
+}
+
+func TestQemuVersionPattern(t *testing.T) {
+	tests := []struct {
+		desc string
+		out  string
+		want []string
+	}{
+		{
+			desc: "qemu 4.2",
+			out:  readQemuHelp(t, "4.2", "version"),
+			want: []string{"version 4.2.1", "4", "2", "1"},
+		},
+		{
+			desc: "qemu 8.2",
+			out:  readQemuHelp(t, "8.2", "version"),
+			want: []string{"version 8.2.2", "8", "2", "2"},
+		},
+		{
+			desc: "no distribution suffix",
+			out:  "QEMU emulator version 10.0.0\n",
+			want: []string{"version 10.0.0", "10", "0", "0"},
+		},
+		{
+			desc: "not qemu",
+			out:  "qemu-system-x86_64: unknown option '-version'\n",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := qemuVersionPattern.FindStringSubmatch(tt.out)
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("%s: version does not match (-want +got):\n%s", tt.desc, diff)
+			}
+		})
+	}
+}
+
+func TestParseQemuMachines(t *testing.T) {
+	tests := []struct {
+		desc    string
+		version string
+		count   int
+		want    []string
+		notWant []string
+	}{
+		{
+			desc:    "qemu 4.2",
+			version: "4.2",
+			count:   56,
+			want:    []string{"pc", "pc-i440fx-4.2", "q35", "pc-q35-4.2", "isapc", "ubuntu", "none"},
+			notWant: []string{"Supported", "pc-q35-8.2", "Standard"},
+		},
+		{
+			desc:    "qemu 8.2",
+			version: "8.2",
+			count:   71,
+			want:    []string{"pc", "pc-i440fx-8.2", "q35", "pc-q35-4.2", "microvm", "xenfv-3.1"},
+			notWant: []string{"Supported", "pc-1.0", "Standard"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := parseQemuMachines(readQemuHelp(t, tt.version, "machine-help"))
+
+			if len(got) != tt.count {
+				t.Fatalf("%s: got %d machine types, want %d", tt.desc, len(got), tt.count)
+			}
+
+			for _, m := range tt.want {
+				if !util.StringSliceContains(m, got) {
+					t.Fatalf("%s: machine type '%s' missing", tt.desc, m)
+				}
+			}
+
+			for _, m := range tt.notWant {
+				if util.StringSliceContains(m, got) {
+					t.Fatalf("%s: unexpected machine type '%s'", tt.desc, m)
+				}
+			}
+		})
+	}
+}
+
+func TestParseQemuAccels(t *testing.T) {
+	tests := []struct {
+		desc string
+		out  string
+		want []string
+	}{
+		{
+			desc: "qemu 4.2, comma separated on the header line",
+			out:  readQemuHelp(t, "4.2", "accel-help"),
+			want: []string{"kvm", "xen", "hax", "tcg"},
+		},
+		{
+			desc: "qemu 8.2, one per line",
+			out:  readQemuHelp(t, "8.2", "accel-help"),
+			want: []string{"tcg", "kvm", "xen"},
+		},
+		{
+			desc: "no accelerators",
+			out:  "Accelerators supported in QEMU binary:\n",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := parseQemuAccels(tt.out)
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("%s: accelerators do not match (-want +got):\n%s", tt.desc, diff)
+			}
+		})
+	}
+}
+
+func TestParseQemuDevices(t *testing.T) {
+	tests := []struct {
+		desc    string
+		version string
+		want    []string
+		notWant []string
+	}{
+		{
+			desc:    "qemu 4.2",
+			version: "4.2",
+			want: []string{
+				"pci-bridge", "ich9-ahci", "ahci", "ide-hd", "ide-drive", "e1000",
+				"e1000-82540em", "virtio-net-pci", "virtio-net", "vmxnet3",
+			},
+			notWant: []string{"igb", "Intel Gigabit Ethernet", "PCI"},
+		},
+		{
+			desc:    "qemu 8.2",
+			version: "8.2",
+			want: []string{
+				"pci-bridge", "ich9-ahci", "ahci", "ide-hd", "e1000", "igb", "virtio-net",
+			},
+			notWant: []string{"ide-drive", "Standard PCI Bridge", "PCI"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := parseQemuDevices(readQemuHelp(t, tt.version, "device-help"))
+
+			for _, d := range tt.want {
+				if !util.StringSliceContains(d, got) {
+					t.Fatalf("%s: device '%s' missing", tt.desc, d)
+				}
+			}
+
+			for _, d := range tt.notWant {
+				if util.StringSliceContains(d, got) {
+					t.Fatalf("%s: unexpected device '%s'", tt.desc, d)
+				}
+			}
+		})
+	}
+}
+
+func TestQemuCapabilitiesValidate(t *testing.T) {
+	validCmd := func() *QemuLaunchCmd {
+		return &QemuLaunchCmd{
+			Accel:   "kvm",
+			Machine: &Machine{Type: "pc"},
+			Disk: []*Disk{
+				{Device: &Device{Driver: "ide-hd", ID: "disk0"}},
+			},
+			Pci:     []*Device{{Driver: "pci-bridge", ID: "pci.1"}},
+			MgmtNic: &Nic{Device: &Device{Driver: "e1000", ID: "nic-mgmt"}},
+			DataNic: []*Nic{{Device: &Device{Driver: "virtio-net-pci", ID: "nic-p001"}}},
+		}
+	}
+
+	tests := []struct {
+		desc    string
+		version string
+		modify  func(c *QemuCapabilities, cmd *QemuLaunchCmd)
+		wantErr bool
+	}{
+		{
+			desc:    "qemu 4.2 valid",
+			version: "4.2",
+		},
+		{
+			desc:    "qemu 8.2 valid",
+			version: "8.2",
+		},
+		{
+			desc:    "accelerator properties are ignored",
+			version: "8.2",
+			modify: func(_ *QemuCapabilities, cmd *QemuLaunchCmd) {
+				cmd.Accel = "tcg,thread=multi"
+			},
+		},
+		{
+			desc:    "machine options are not part of the machine type",
+			version: "4.2",
+			modify: func(_ *QemuCapabilities, cmd *QemuLaunchCmd) {
+				cmd.Machine = &Machine{Type: "q35", Props: ParseProps("kernel-irqchip=split")}
+			},
+		},
+		{
+			desc:    "no accelerator",
+			version: "8.2",
+			modify: func(_ *QemuCapabilities, cmd *QemuLaunchCmd) {
+				cmd.Accel = ""
+			},
+		},
+		{
+			desc:    "version too old",
+			version: "4.2",
+			modify: func(c *QemuCapabilities, _ *QemuLaunchCmd) {
+				c.Major, c.Minor, c.Version = 4, 1, "4.1.0"
+			},
+			wantErr: true,
+		},
+		{
+			desc:    "unsupported accelerator",
+			version: "8.2",
+			modify: func(_ *QemuCapabilities, cmd *QemuLaunchCmd) {
+				cmd.Accel = "hvf"
+			},
+			wantErr: true,
+		},
+		{
+			desc:    "accelerator removed in newer versions",
+			version: "8.2",
+			modify: func(_ *QemuCapabilities, cmd *QemuLaunchCmd) {
+				cmd.Accel = "hax"
+			},
+			wantErr: true,
+		},
+		{
+			desc:    "unsupported machine type",
+			version: "4.2",
+			modify: func(_ *QemuCapabilities, cmd *QemuLaunchCmd) {
+				cmd.Machine.Type = "pc-q35-8.2"
+			},
+			wantErr: true,
+		},
+		{
+			desc:    "unsupported data nic",
+			version: "4.2",
+			modify: func(_ *QemuCapabilities, cmd *QemuLaunchCmd) {
+				cmd.DataNic[0].Device.Driver = "igb"
+			},
+			wantErr: true,
+		},
+		{
+			desc:    "removed disk device",
+			version: "8.2",
+			modify: func(_ *QemuCapabilities, cmd *QemuLaunchCmd) {
+				cmd.Disk[0].Device.Driver = "ide-drive"
+			},
+			wantErr: true,
+		},
+		{
+			desc:    "unsupported extra device",
+			version: "8.2",
+			modify: func(_ *QemuCapabilities, cmd *QemuLaunchCmd) {
+				cmd.Extra = append(cmd.Extra, &Device{Driver: "boxen-nic", ID: "extra"})
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c := testQemuCapabilities(t, tt.version)
+			cmd := validCmd()
+
+			if tt.modify != nil {
+				tt.modify(c, cmd)
+			}
+
+			err := c.Validate(cmd)
+
+			if tt.wantErr && !errors.Is(err, util.ErrValidationError) {
+				t.Fatalf("%s: expected validation error, got: %v", tt.desc, err)
+			}
+
+			if !tt.wantErr && err != nil {
+				t.Fatalf("%s: expected no error, got: %s", tt.desc, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package instance
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/carlmontanari/boxen/boxen/command"
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+const (
+	// ConsoleMuxCommand is the (hidden) boxen command running a serial console multiplexer.
+	ConsoleMuxCommand = "console-mux"
+	// ConsoleMuxBinary is the name of the binary providing ConsoleMuxCommand.
+	ConsoleMuxBinary = "boxen"
+
+	consoleStartTimeout  = 10
+	consoleStartInterval = 100
+	maxUnixSocketPath    = 107
+)
+
+// consoleFile returns the path of the file of serial port idx with the extension ext, these live
+// next to the instance disk.
+func (i *Qemu) consoleFile(idx int, ext string) string {
+	return filepath.Join(filepath.Dir(i.Disk), fmt.Sprintf("serial%d.%s", idx, ext))
+}
+
+// ConsoleSocket returns the path of the unix socket qemu connects serial port idx to.
+func (i *Qemu) ConsoleSocket(idx int) string {
+	return i.consoleFile(idx, "sock")
+}
+
+// ConsoleLog returns the path of the file all output of serial port idx is logged to.
+func (i *Qemu) ConsoleLog(idx int) string {
+	return i.consoleFile(idx, "log")
+}
+
+// consoleDialAddress returns the address boxen reaches the serial port (or monitor) port on.
+func (i *Qemu) consoleDialAddress(port int) string {
+	addr := i.Console.Address()
+
+	if ip := net.ParseIP(addr); ip != nil && ip.IsUnspecified() {
+		addr = "127.0.0.1"
+	}
+
+	return net.JoinHostPort(addr, strconv.Itoa(port))
+}
+
+// consoleMuxBinary returns the boxen binary to run the console multiplexers with -- the binary set
+// in the console options, the running binary if that is boxen itself, or boxen from the path. The
+// running binary may be some other program embedding boxen, so it is not used blindly.
+func (i *Qemu) consoleMuxBinary() (string, error) {
+	if i.Console != nil && i.Console.Binary != "" {
+		bin, err := exec.LookPath(i.Console.Binary)
+		if err != nil {
+			return "", fmt.Errorf(
+				"%w: console binary '%s' not found",
+				util.ErrConsoleError,
+				i.Console.Binary,
+			)
+		}
+
+		return bin, nil
+	}
+
+	bin, err := os.Executable()
+	if err == nil && filepath.Base(bin) == ConsoleMuxBinary {
+		return bin, nil
+	}
+
+	bin, err = exec.LookPath(ConsoleMuxBinary)
+	if err != nil {
+		return "", fmt.Errorf(
+			"%w: %s binary not found in path, set the binary in the console options",
+			util.ErrConsoleError,
+			ConsoleMuxBinary,
+		)
+	}
+
+	return bin, nil
+}
+
+// startConsoles launches a console multiplexer for each serial port of the instance, qemu connects
+// to the unix socket of the multiplexer and clients (including boxen itself) attach to the serial
+// port. The multiplexers are separate processes so they outlive boxen just like qemu does.
+func (i *Qemu) startConsoles() error {
+	i.stopConsoles()
+
+	bin, err := i.consoleMuxBinary()
+	if err != nil {
+		return err
+	}
+
+	for idx, port := range i.Hardware.SerialPorts {
+		socket := i.ConsoleSocket(idx)
+
+		if len(socket) > maxUnixSocketPath {
+			return fmt.Errorf(
+				"%w: console socket path '%s' is too long for a unix socket",
+				util.ErrConsoleError,
+				socket,
+			)
+		}
+
+		args := []string{
+			ConsoleMuxCommand,
+			"--socket", socket,
+			"--listen", net.JoinHostPort(i.Console.Address(), strconv.Itoa(port)),
+			"--log", i.ConsoleLog(idx),
+		}
+
+		if i.Console != nil && i.Console.RingSize > 0 {
+			args = append(args, "--ring-size", strconv.Itoa(i.Console.RingSize))
+		}
+
+		if i.Console != nil && i.Console.WriteLease > 0 {
+			args = append(args, "--write-lease", strconv.Itoa(i.Console.WriteLease))
+		}
+
+		i.Loggers.Base.Debugf("launching console multiplexer for serial port %d", port)
+
+		r, err := command.Execute(bin, command.WithArgs(args))
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(
+			i.consoleFile(idx, "pid"),
+			[]byte(strconv.Itoa(r.Proc.Process.Pid)),
+			util.FilePerms,
+		)
+		if err != nil {
+			return err
+		}
+
+		err = i.waitConsole(port, r)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// waitConsole waits until the multiplexer of the serial port port accepts clients, the
+// multiplexer listens on the unix socket before the serial port, so qemu can connect after this.
+func (i *Qemu) waitConsole(port int, r *command.Result) error {
+	deadline := time.Now().Add(consoleStartTimeout * time.Second)
+
+	for time.Now().Before(deadline) {
+		conn, err := net.Dial("tcp", i.consoleDialAddress(port))
+		if err == nil {
+			_ = conn.Close()
+
+			return nil
+		}
+
+		time.Sleep(consoleStartInterval * time.Millisecond)
+	}
+
+	out, _ := r.ReadStdout()
+
+	return fmt.Errorf(
+		"%w: console multiplexer for serial port %d did not start: %s",
+		util.ErrConsoleError,
+		port,
+		bytes.TrimSpace(out),
+	)
+}
+
+// stopConsoles stops the console multiplexers of the instance, if any are running.
+func (i *Qemu) stopConsoles() {
+	for idx := range i.Hardware.SerialPorts {
+		pidFile := i.consoleFile(idx, "pid")
+
+		pid, err := readIntFile(pidFile)
+		if err != nil {
+			continue
+		}
+
+		_ = os.Remove(pidFile)
+
+		// the pid may have been reused since, only kill it if it is still this multiplexer
+		cmdline, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+		if err != nil ||
+			!strings.Contains(string(cmdline), ConsoleMuxCommand) ||
+			!strings.Contains(string(cmdline), i.ConsoleSocket(idx)) {
+			continue
+		}
+
+		i.Loggers.Base.Debugf("stopping console multiplexer %d", pid)
+
+		_, _ = command.Execute(
+			"kill",
+			command.WithArgs([]string{strconv.Itoa(pid)}),
+			command.WithWait(true),
+		)
+	}
+}
+
+func (i *Qemu) launchCmdSerial() []*Serial {
+	var serials []*Serial
+
+	// qemu 9.2 replaced the (seconds) reconnect property with reconnect-ms
+	reconnect := Prop{Key: "reconnect", Value: "1"}
+	if i.caps != nil && i.caps.AtLeast(9, 2) { //nolint:gomnd
+		reconnect = Prop{Key: "reconnect-ms", Value: "1000"}
+	}
+
+	for idx := range i.Hardware.SerialPorts {
+		serials = append(
+			serials,
+			&Serial{Chardev: &Chardev{
+				Backend: "socket",
+				ID:      fmt.Sprintf("serial%d", idx),
+				Props: Props{
+					{Key: "path", Value: i.ConsoleSocket(idx)},
+					reconnect,
+				},
+			}},
+		)
+	}
+
+	return serials
+}
@@ -0,0 +1,470 @@
+package instance
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LaunchArg is an element of the qemu launch command that renders to one or more arguments.
+type LaunchArg interface {
+	Args() []string
+}
+
+// Prop is a single property of a qemu option, i.e. "bus=pci.1", a Prop without a Key is rendered
+// as just the Value (i.e. the "pci-bridge" driver of a device).
+type Prop struct {
+	Key   string
+	Value string
+}
+
+// Props is an ordered list of qemu option properties.
+type Props []Prop
+
+// Get returns the value of the property k.
+func (p Props) Get(k string) (string, bool) {
+	for _, prop := range p {
+		if prop.Key == k {
+			return prop.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// Set sets the property k to v, replacing the property if it exists and appending it otherwise.
+func (p *Props) Set(k, v string) {
+	for idx, prop := range *p {
+		if prop.Key == k {
+			(*p)[idx].Value = v
+
+			return
+		}
+	}
+
+	*p = append(*p, Prop{Key: k, Value: v})
+}
+
+// Del removes the property k.
+func (p *Props) Del(k string) {
+	props := (*p)[:0]
+
+	for _, prop := range *p {
+		if prop.Key != k {
+			props = append(props, prop)
+		}
+	}
+
+	*p = props
+}
+
+// String renders the properties as a qemu option value, i.e. "e1000,netdev=mgmt,bus=pci.1". Commas
+// in values are escaped as ",,", so that qemu doesn't split i.e. a file name into properties.
+func (p Props) String() string {
+	parts := make([]string, len(p))
+
+	for idx, prop := range p {
+		v := strings.ReplaceAll(prop.Value, ",", ",,")
+
+		if prop.Key == "" {
+			parts[idx] = v
+		} else {
+			parts[idx] = prop.Key + "=" + v
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// ParseProps parses a qemu option value as rendered by String, i.e. "q35,kernel-irqchip=split",
+// into properties -- parts without a "=" are bare values, ",," is an escaped comma.
+func ParseProps(s string) Props {
+	var props Props
+
+	if s == "" {
+		return props
+	}
+
+	var parts []string
+
+	var cur strings.Builder
+
+	for idx := 0; idx < len(s); idx++ {
+		if s[idx] != ',' {
+			cur.WriteByte(s[idx])
+
+			continue
+		}
+
+		if idx+1 < len(s) && s[idx+1] == ',' {
+			cur.WriteByte(',')
+			idx++
+
+			continue
+		}
+
+		parts = append(parts, cur.String())
+		cur.Reset()
+	}
+
+	parts = append(parts, cur.String())
+
+	for _, part := range parts {
+		kv := strings.SplitN(part, "=", 2) //nolint:gomnd
+
+		if len(kv) == 1 {
+			props = append(props, Prop{Value: part})
+
+			continue
+		}
+
+		props = append(props, Prop{Key: kv[0], Value: kv[1]})
+	}
+
+	return props
+}
+
+// withHead returns the properties head (bare value, if not empty), "id=ID" (if not empty) followed
+// by the properties p.
+func (p Props) withHead(head, id string) Props {
+	var props Props
+
+	if head != "" {
+		props = append(props, Prop{Value: head})
+	}
+
+	if id != "" {
+		props = append(props, Prop{Key: "id", Value: id})
+	}
+
+	return append(props, p...)
+}
+
+// Opt is a plain qemu option with an optional value, i.e. "-boot c".
+type Opt struct {
+	Name  string
+	Value string
+}
+
+// NewOpt returns a new Opt, the name is given without the leading dash.
+func NewOpt(name, value string) *Opt {
+	return &Opt{Name: name, Value: value}
+}
+
+func (o *Opt) Args() []string {
+	if o == nil {
+		return nil
+	}
+
+	if o.Value == "" {
+		return []string{"-" + o.Name}
+	}
+
+	return []string{"-" + o.Name, o.Value}
+}
+
+// Machine is the qemu "-machine" option.
+type Machine struct {
+	Type  string
+	Props Props
+}
+
+func (m *Machine) Args() []string {
+	if m == nil {
+		return nil
+	}
+
+	return []string{"-machine", m.Props.withHead(m.Type, "").String()}
+}
+
+// CPU is the qemu "-cpu" and "-smp" options, either may be empty.
+type CPU struct {
+	// Model is the cpu model including any features, i.e. "qemu64,+ssse3".
+	Model string
+	SMP   Props
+}
+
+func (c *CPU) Args() []string {
+	if c == nil {
+		return nil
+	}
+
+	var args []string
+
+	if c.Model != "" {
+		args = append(args, "-cpu", c.Model)
+	}
+
+	if len(c.SMP) > 0 {
+		args = append(args, "-smp", c.SMP.String())
+	}
+
+	return args
+}
+
+// Object is a qemu "-object", i.e. a memory backend.
+type Object struct {
+	Type  string
+	ID    string
+	Props Props
+}
+
+func (o *Object) Args() []string {
+	if o == nil {
+		return nil
+	}
+
+	return []string{"-object", o.Props.withHead(o.Type, o.ID).String()}
+}
+
+// Memory is the qemu "-m" option along with the options placing the memory.
+type Memory struct {
+	// SizeMB is the memory size in MB.
+	SizeMB int
+	// MemPath backs the memory with files in the (hugetlbfs) path.
+	MemPath  string
+	Prealloc bool
+	// Backend is the memory backend object of the (single) numa node.
+	Backend *Object
+}
+
+func (m *Memory) Args() []string {
+	if m == nil {
+		return nil
+	}
+
+	args := []string{"-m", strconv.Itoa(m.SizeMB)}
+
+	if m.MemPath != "" {
+		args = append(args, "-mem-path", m.MemPath)
+	}
+
+	if m.Prealloc {
+		args = append(args, "-mem-prealloc")
+	}
+
+	if m.Backend != nil {
+		args = append(args, m.Backend.Args()...)
+		args = append(args, "-numa", fmt.Sprintf("node,memdev=%s", m.Backend.ID))
+	}
+
+	return args
+}
+
+// Chardev is a qemu character device backend, i.e. for serial ports or the monitor.
+type Chardev struct {
+	Backend string
+	ID      string
+	Props   Props
+}
+
+func (c *Chardev) Args() []string {
+	if c == nil {
+		return nil
+	}
+
+	return []string{"-chardev", c.Props.withHead(c.Backend, c.ID).String()}
+}
+
+// Serial is a serial port of the instance backed by a chardev.
+type Serial struct {
+	Chardev *Chardev
+}
+
+func (s *Serial) Args() []string {
+	if s == nil {
+		return nil
+	}
+
+	return append(s.Chardev.Args(), "-serial", "chardev:"+s.Chardev.ID)
+}
+
+// Monitor is the (human) qemu monitor backed by a chardev.
+type Monitor struct {
+	Chardev *Chardev
+}
+
+func (m *Monitor) Args() []string {
+	if m == nil {
+		return nil
+	}
+
+	return append(
+		m.Chardev.Args(),
+		"-mon",
+		fmt.Sprintf("chardev=%s,mode=readline", m.Chardev.ID),
+	)
+}
+
+// Drive is a qemu "-drive".
+type Drive struct {
+	ID    string
+	Props Props
+}
+
+func (d *Drive) Args() []string {
+	if d == nil {
+		return nil
+	}
+
+	return []string{"-drive", d.Props.withHead("", d.ID).String()}
+}
+
+// Device is a qemu "-device".
+type Device struct {
+	Driver string
+	ID     string
+	Props  Props
+}
+
+func (d *Device) Args() []string {
+	if d == nil {
+		return nil
+	}
+
+	return []string{"-device", d.Props.withHead(d.Driver, d.ID).String()}
+}
+
+// SetPciAddr places the device at address addr on the pci bridge (bus) busID.
+func (d *Device) SetPciAddr(busID, addr int) {
+	d.Props.Set("bus", fmt.Sprintf("pci.%d", busID))
+	d.Props.Set("addr", fmt.Sprintf("0x%x", addr))
+}
+
+// Disk is a drive and the device it is attached as, the Device is nil for drives that attach
+// themselves (i.e. "if=ide" drives).
+type Disk struct {
+	Drive  *Drive
+	Device *Device
+}
+
+func (d *Disk) Args() []string {
+	if d == nil {
+		return nil
+	}
+
+	return append(d.Drive.Args(), d.Device.Args()...)
+}
+
+// Netdev is a qemu "-netdev" network backend.
+type Netdev struct {
+	Type  string
+	ID    string
+	Props Props
+}
+
+func (n *Netdev) Args() []string {
+	if n == nil {
+		return nil
+	}
+
+	return []string{"-netdev", n.Props.withHead(n.Type, n.ID).String()}
+}
+
+// Nic is a nic device and its network backend.
+type Nic struct {
+	Device *Device
+	Netdev *Netdev
+}
+
+// NewNic returns a nic of model model with the netdev netdev, the device id is derived from the
+// netdev id so it is stable across launches.
+func NewNic(model string, netdev *Netdev) *Nic {
+	return &Nic{
+		Device: &Device{
+			Driver: model,
+			ID:     "nic-" + netdev.ID,
+			Props:  Props{{Key: "netdev", Value: netdev.ID}},
+		},
+		Netdev: netdev,
+	}
+}
+
+func (n *Nic) Args() []string {
+	if n == nil {
+		return nil
+	}
+
+	return append(n.Device.Args(), n.Netdev.Args()...)
+}
+
+// Global is a qemu "-global" default device property.
+type Global struct {
+	Driver   string
+	Property string
+	Value    string
+}
+
+func (g *Global) Args() []string {
+	if g == nil {
+		return nil
+	}
+
+	return []string{
+		"-global",
+		fmt.Sprintf("driver=%s,property=%s,value=%s", g.Driver, g.Property, g.Value),
+	}
+}
+
+// SMBIOS is a qemu "-smbios" table entry of type Type.
+type SMBIOS struct {
+	Type  int
+	Props Props
+}
+
+func (s *SMBIOS) Args() []string {
+	if s == nil {
+		return nil
+	}
+
+	return []string{
+		"-smbios",
+		append(Props{{Key: "type", Value: strconv.Itoa(s.Type)}}, s.Props...).String(),
+	}
+}
+
+// FwCfg is a qemu "-fw_cfg" item, with either a File or a String value.
+type FwCfg struct {
+	Name   string
+	File   string
+	String string
+}
+
+func (f *FwCfg) Args() []string {
+	if f == nil {
+		return nil
+	}
+
+	props := Props{{Key: "name", Value: f.Name}}
+
+	if f.File != "" {
+		props = append(props, Prop{Key: "file", Value: f.File})
+	} else {
+		props = append(props, Prop{Key: "string", Value: f.String})
+	}
+
+	return []string{"-fw_cfg", props.String()}
+}
+
+// VNC is the qemu "-vnc" graphical console served on Host, display Display (port 5900+Display).
+type VNC struct {
+	Host    string
+	Display int
+	Props   Props
+}
+
+func (v *VNC) Args() []string {
+	if v == nil {
+		return nil
+	}
+
+	host := v.Host
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+
+	return []string{
+		"-vnc",
+		append(Props{{Value: fmt.Sprintf("%s:%d", host, v.Display)}}, v.Props...).String(),
+	}
+}
@@ -0,0 +1,200 @@
+package instance
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/carlmontanari/boxen/boxen/config"
+)
+
+// LaunchCmdArg is a single argument of a launch command along with where it came from.
+type LaunchCmdArg struct {
+	// Arg is the argument itself.
+	Arg string `json:"arg"`
+	// Field is the QemuLaunchCmd field the argument is part of.
+	Field string `json:"field"`
+	// Hook is the platform launch modifier that added or changed the argument, empty if the
+	// argument is what boxen builds for every platform.
+	Hook string `json:"hook,omitempty"`
+}
+
+// LaunchCmdExplain is a (not launched) launch command with each argument annotated with the field
+// and platform hook that produced it.
+type LaunchCmdExplain struct {
+	Instance string          `json:"instance"`
+	Binary   string          `json:"binary"`
+	Args     []*LaunchCmdArg `json:"args"`
+}
+
+// shellQuote quotes s for a posix shell, if required.
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !strings.ContainsRune(
+			"abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_=+,./:@%",
+			r,
+		)
+	}) == -1 {
+		return s
+	}
+
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
+// Shell returns the launch command as a shell command line.
+func (e *LaunchCmdExplain) Shell() string {
+	parts := []string{shellQuote(e.Binary)}
+
+	for _, a := range e.Args {
+		parts = append(parts, shellQuote(a.Arg))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// Explain returns the launch command for reading rather than running, one qemu option (and its
+// value) per line, each followed by a comment naming the field and the hook that produced it.
+func (e *LaunchCmdExplain) Explain() string {
+	lines := []string{shellQuote(e.Binary)}
+
+	var opt []string
+
+	var field, hook string
+
+	flush := func() {
+		if len(opt) == 0 {
+			return
+		}
+
+		source := "boxen"
+		if hook != "" {
+			source = hook
+		}
+
+		lines = append(lines, fmt.Sprintf("  %s  # %s (%s)", strings.Join(opt, " "), field, source))
+		opt = nil
+		hook = ""
+	}
+
+	for _, a := range e.Args {
+		if strings.HasPrefix(a.Arg, "-") || a.Field != field {
+			flush()
+
+			field = a.Field
+		}
+
+		if a.Hook != "" {
+			hook = a.Hook
+		}
+
+		opt = append(opt, shellQuote(a.Arg))
+	}
+
+	flush()
+
+	return strings.Join(lines, "\n")
+}
+
+// hookName returns the name of the launch modifier function f, i.e. "(*AristaVeos).modifyStartCmd".
+func hookName(f func(c *QemuLaunchCmd)) string {
+	name := runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		name = name[idx+1:]
+	}
+
+	if idx := strings.Index(name, "."); idx >= 0 {
+		name = name[idx+1:]
+	}
+
+	return strings.TrimSuffix(name, "-fm")
+}
+
+// DryRun builds the launch command of the instance exactly like Start does -- including the launch
+// modifier of the options -- but does not launch the instance or touch any files. The returned
+// command has every argument annotated with the field and hook it came from; arguments of a field
+// that differ from what boxen builds for every platform are attributed to the launch modifier.
+func (i *Qemu) DryRun(opts ...Option) (*LaunchCmdExplain, error) {
+	err := config.ValidateDisks(i.Hardware.Disks)
+	if err != nil {
+		return nil, err
+	}
+
+	if i.Advanced != nil {
+		err = i.Advanced.Firmware.Validate(i.Advanced.Machine)
+		if err != nil {
+			return nil, err
+		}
+
+		err = i.Advanced.Memory.Validate()
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = i.Advanced.CPU.PinnedCPUs()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	qOpts, err := newQemuOpts(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// the qemu binary is probed if there is one, dry runs also work on hosts without qemu
+	i.caps, err = ProbeQemuCapabilities(i.Qemu.Binary)
+	if err != nil {
+		i.Loggers.Base.Debugf("not validating launch command, failed probing qemu binary: %s", err)
+
+		i.caps = nil
+	}
+
+	err = i.buildLaunchCmd()
+	if err != nil {
+		return nil, err
+	}
+
+	// the typed elements are modified in place, render the unmodified command up front
+	baseFields := i.LaunchCmd.fields()
+
+	hook := ""
+
+	if qOpts.launchModifier != nil {
+		qOpts.launchModifier(i.LaunchCmd)
+
+		hook = qOpts.launchModifierName
+		if hook == "" {
+			hook = hookName(qOpts.launchModifier)
+		}
+	}
+
+	if i.caps != nil {
+		err = i.caps.Validate(i.LaunchCmd)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	e := &LaunchCmdExplain{
+		Instance: i.Name,
+		Binary:   i.Qemu.Binary,
+	}
+
+	for idx, f := range i.LaunchCmd.fields() {
+		baseArgs := baseFields[idx].args
+
+		for argIdx, arg := range f.args {
+			a := &LaunchCmdArg{Arg: arg, Field: f.name}
+
+			if argIdx >= len(baseArgs) || baseArgs[argIdx] != arg {
+				a.Hook = hook
+			}
+
+			e.Args = append(e.Args, a)
+		}
+	}
+
+	return e, nil
+}
@@ -0,0 +1,209 @@
+package instance_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/carlmontanari/boxen/boxen/config"
+	"github.com/carlmontanari/boxen/boxen/instance"
+	"github.com/google/go-cmp/cmp"
+)
+
+var update = flag.Bool("update", false, "update the golden files") //nolint:gochecknoglobals
+
+var (
+	// uuidPattern matches the (random) instance uuid of the launch command.
+	uuidPattern = regexp.MustCompile( //nolint:gochecknoglobals
+		`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`,
+	)
+	// macPattern matches the generated mac addresses, only the last octet is not random.
+	macPattern = regexp.MustCompile(`52:54:00:[0-9a-f]{2}:[0-9a-f]{2}:`) //nolint:gochecknoglobals
+)
+
+func TestDryRunGolden(t *testing.T) {
+	tests := []struct {
+		desc     string
+		golden   string
+		advanced *config.Advanced
+		console  *config.Console
+	}{
+		{
+			desc:   "default launch command",
+			golden: "default",
+		},
+		{
+			desc:     "machine with options",
+			golden:   "machine_options",
+			advanced: &config.Advanced{Machine: "q35,kernel-irqchip=split"},
+		},
+		{
+			desc:     "machine type as property",
+			golden:   "machine_type_property",
+			advanced: &config.Advanced{Machine: "type=q35,accel=kvm"},
+		},
+		{
+			desc:    "packaged console listen address",
+			golden:  "console_listen_address",
+			console: &config.Console{ListenAddress: config.PackageConsoleListenAddress},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			q := &instance.Qemu{
+				Name: "vm1",
+				ID:   1,
+				Disk: "/boxen/instances/vm1/disk.qcow2",
+				// the binary doesn't exist, so the launch command isn't validated against whatever
+				// qemu the host running the tests has
+				Qemu: &config.Qemu{Binary: "qemu-golden", Acceleration: []string{"kvm"}},
+				Hardware: &config.Hardware{
+					Memory:       2048,
+					Acceleration: []string{"kvm"},
+					MonitorPort:  4001,
+					SerialPorts:  []int{5001},
+					NicType:      instance.NicE1000,
+					NicCount:     2,
+					NicPerBus:    26,
+				},
+				Advanced: tt.advanced,
+				Console:  tt.console,
+				MgmtIntf: &config.MgmtIntf{
+					Nat: &config.Nat{
+						TCP: []*config.NatPortPair{{InstanceSide: 22, HostSide: 48000}},
+						UDP: []*config.NatPortPair{{InstanceSide: 161, HostSide: 48001}},
+					},
+				},
+				DataPlaneIntf: &config.DataPlaneIntf{
+					SocketConnectMap: map[int]*config.SocketConnectPair{
+						1: {Connect: 10002, Listen: 10001},
+					},
+				},
+				Loggers: testLoggers(t),
+			}
+
+			e, err := q.DryRun()
+			if err != nil {
+				t.Fatalf("%s: dry run failed: %s", tt.desc, err)
+			}
+
+			got := uuidPattern.ReplaceAllString(e.Explain(), "UUID")
+			got = macPattern.ReplaceAllString(got, "52:54:00:xx:xx:") + "\n"
+
+			golden := filepath.Join("testdata", tt.golden+".golden")
+
+			if *update {
+				err = os.WriteFile(golden, []byte(got), 0o644) //nolint:gosec,gomnd
+				if err != nil {
+					t.Fatalf("%s: failed updating golden file: %s", tt.desc, err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%s: failed reading golden file: %s", tt.desc, err)
+			}
+
+			if diff := cmp.Diff(string(want), got); diff != "" {
+				t.Fatalf(
+					"%s: launch command does not match golden file (-want +got):\n%s",
+					tt.desc,
+					diff,
+				)
+			}
+		})
+	}
+}
+
+func TestParseProps(t *testing.T) {
+	tests := []struct {
+		desc string
+		s    string
+		want instance.Props
+	}{
+		{
+			desc: "empty",
+			s:    "",
+			want: nil,
+		},
+		{
+			desc: "bare value",
+			s:    "q35",
+			want: instance.Props{{Value: "q35"}},
+		},
+		{
+			desc: "bare value and properties",
+			s:    "q35,kernel-irqchip=split,smm=on",
+			want: instance.Props{
+				{Value: "q35"},
+				{Key: "kernel-irqchip", Value: "split"},
+				{Key: "smm", Value: "on"},
+			},
+		},
+		{
+			desc: "escaped commas",
+			s:    "file=/disks/a,,b.qcow2,format=qcow2",
+			want: instance.Props{
+				{Key: "file", Value: "/disks/a,b.qcow2"},
+				{Key: "format", Value: "qcow2"},
+			},
+		},
+		{
+			desc: "equals in value",
+			s:    "string=a=b",
+			want: instance.Props{{Key: "string", Value: "a=b"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := instance.ParseProps(tt.s)
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("%s: props do not match (-want +got):\n%s", tt.desc, diff)
+			}
+
+			if got.String() != tt.s {
+				t.Fatalf("%s: props render as %q, want %q", tt.desc, got.String(), tt.s)
+			}
+		})
+	}
+}
+
+func TestPropsString(t *testing.T) {
+	tests := []struct {
+		desc  string
+		props instance.Props
+		want  string
+	}{
+		{
+			desc:  "bare value and properties",
+			props: instance.Props{{Value: "e1000"}, {Key: "netdev", Value: "mgmt"}},
+			want:  "e1000,netdev=mgmt",
+		},
+		{
+			desc:  "commas in values are escaped",
+			props: instance.Props{{Key: "file", Value: "/disks/a,b.qcow2"}},
+			want:  "file=/disks/a,,b.qcow2",
+		},
+		{
+			desc: "commas in strings are escaped",
+			props: instance.Props{
+				{Key: "name", Value: "opt/com.boxen/config"},
+				{Key: "string", Value: "a,b,,c"},
+			},
+			want: "name=opt/com.boxen/config,string=a,,b,,,,c",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := tt.props.String(); got != tt.want {
+				t.Fatalf("%s: got %q, want %q", tt.desc, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package instance
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/carlmontanari/boxen/boxen/config"
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+func (i *Qemu) uefi() bool {
+	return i.Advanced != nil && i.Advanced.Firmware.IsUEFI()
+}
+
+func (i *Qemu) secureBoot() bool {
+	return i.uefi() && i.Advanced.Firmware.SecureBoot
+}
+
+// FirmwareVarsFile returns the path of the instance copy of the OVMF vars (nvram), this lives next
+// to the instance disk.
+func (i *Qemu) FirmwareVarsFile() string {
+	return filepath.Join(filepath.Dir(i.Disk), config.FirmwareVarsFile)
+}
+
+// prepareFirmware validates the firmware settings and, for uefi instances, copies the OVMF vars
+// template next to the instance disk -- unless the instance already has its own vars, i.e. from a
+// previous start or carried over from the installed source disk.
+func (i *Qemu) prepareFirmware() error {
+	if i.Advanced == nil {
+		return nil
+	}
+
+	err := i.Advanced.Firmware.Validate(i.Advanced.Machine)
+	if err != nil || !i.uefi() {
+		return err
+	}
+
+	f := i.Advanced.Firmware
+
+	if !util.FileExists(f.CodeFile()) {
+		return fmt.Errorf(
+			"%w: firmware code file '%s' does not exist",
+			util.ErrValidationError,
+			f.CodeFile(),
+		)
+	}
+
+	varsFile := i.FirmwareVarsFile()
+
+	if util.FileExists(varsFile) {
+		i.Loggers.Base.Debugf("using existing firmware vars file '%s'", varsFile)
+
+		return nil
+	}
+
+	if !util.FileExists(f.VarsTemplate()) {
+		return fmt.Errorf(
+			"%w: firmware vars template file '%s' does not exist",
+			util.ErrValidationError,
+			f.VarsTemplate(),
+		)
+	}
+
+	i.Loggers.Base.Debugf(
+		"copying firmware vars template '%s' to '%s'",
+		f.VarsTemplate(),
+		varsFile,
+	)
+
+	return util.CopyFile(f.VarsTemplate(), varsFile)
+}
+
+func (i *Qemu) launchCmdFirmware() (drives []*Drive, globals []*Global) {
+	if !i.uefi() {
+		return nil, nil
+	}
+
+	drives = []*Drive{
+		{Props: Props{
+			{Key: "if", Value: "pflash"},
+			{Key: "format", Value: "raw"},
+			{Key: "unit", Value: "0"},
+			{Key: "readonly", Value: "on"},
+			{Key: "file", Value: i.Advanced.Firmware.CodeFile()},
+		}},
+		{Props: Props{
+			{Key: "if", Value: "pflash"},
+			{Key: "format", Value: "raw"},
+			{Key: "unit", Value: "1"},
+			{Key: "file", Value: i.FirmwareVarsFile()},
+		}},
+	}
+
+	if i.secureBoot() {
+		globals = append(
+			globals,
+			&Global{Driver: "cfi.pflash01", Property: "secure", Value: "on"},
+		)
+	}
+
+	return drives, globals
+}
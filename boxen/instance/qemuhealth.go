@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -15,6 +17,15 @@ const (
 	HealthOK  = 200
 	HealthBad = 500
 
+	// HealthPathLive is the liveness endpoint of the health server, it is healthy until the qemu
+	// process exits, including while the instance is still booting.
+	HealthPathLive = "/healthz"
+	// HealthPathReady is the readiness endpoint of the health server, it is healthy once the
+	// instance is started, configured and running. The root path is an alias of this.
+	HealthPathReady = "/readyz"
+
+	healthPort = 7777
+
 	watchCount = 12
 	watchSleep = 5
 )
@@ -57,17 +68,25 @@ func (i *Qemu) WatchMainProc(c chan error, stop chan bool) {
 	}
 }
 
+// qemuHealth holds the health state of a qemu instance, it is read from the health server
+// goroutine.
+type qemuHealth struct {
+	serverOnce sync.Once
+	ready      int32
+	exited     int32
+}
+
 func (i *Qemu) waitMainProc() {
 	_ = i.Proc.Wait()
 
+	atomic.StoreInt32(&i.health.exited, 1)
+
 	i.Proc = nil
 	i.PID = 0
 }
 
-func (i *Qemu) healthEndpoint(w http.ResponseWriter, r *http.Request) {
-	_ = r
-
-	if i.Proc != nil && i.PID > 0 {
+func writeHealth(w http.ResponseWriter, ok bool) {
+	if ok {
 		w.WriteHeader(HealthOK)
 
 		return
@@ -76,16 +95,47 @@ func (i *Qemu) healthEndpoint(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(HealthBad)
 }
 
+func (i *Qemu) liveEndpoint(w http.ResponseWriter, r *http.Request) {
+	_ = r
+
+	writeHealth(w, atomic.LoadInt32(&i.health.exited) == 0)
+}
+
+func (i *Qemu) readyEndpoint(w http.ResponseWriter, r *http.Request) {
+	_ = r
+
+	writeHealth(
+		w,
+		atomic.LoadInt32(&i.health.ready) == 1 && atomic.LoadInt32(&i.health.exited) == 0,
+	)
+}
+
 func (i *Qemu) healthServer() {
-	http.HandleFunc("/", i.healthEndpoint)
+	mux := http.NewServeMux()
 
-	_ = http.ListenAndServe(":7777", nil)
+	mux.HandleFunc("/", i.readyEndpoint)
+	mux.HandleFunc(HealthPathReady, i.readyEndpoint)
+	mux.HandleFunc(HealthPathLive, i.liveEndpoint)
+
+	_ = http.ListenAndServe(fmt.Sprintf(":%d", healthPort), mux)
+}
+
+// StartHealthServer starts the health server in the background if it is not already running. The
+// instance is not ready until RunUntilSigInt is called, starting the health server early allows
+// for liveness checks to pass while the instance is still booting.
+func (i *Qemu) StartHealthServer() {
+	i.health.serverOnce.Do(func() {
+		go i.healthServer()
+	})
 }
 
 func (i *Qemu) RunUntilSigInt() {
-	go i.healthServer()
+	i.StartHealthServer()
+
 	go i.waitMainProc()
 
+	atomic.StoreInt32(&i.health.ready, 1)
+
 	sigs := make(chan os.Signal, 1)
 	done := make(chan bool, 1)
 
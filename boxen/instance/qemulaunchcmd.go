@@ -3,7 +3,8 @@ package instance
 import (
 	"crypto/rand"
 	"fmt"
-	"math"
+	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/google/uuid"
@@ -12,56 +13,176 @@ import (
 	"github.com/carlmontanari/boxen/boxen/util"
 )
 
-const defaultSocketPad = 10000
+const (
+	defaultSocketPad = 10000
 
-// QemuLaunchCmd represents all the elements of a qemu launch command as slices of strings.
+	// MgmtNicID is the (netdev) id of the management nic, the nic device id is "nic-mgmt".
+	MgmtNicID = "mgmt"
+)
+
+// QemuLaunchCmd represents all the elements of a qemu launch command as a typed model of the
+// instance devices. Every element renders its own arguments, platforms modify the elements (i.e.
+// set the pci address of a nic) rather than the rendered strings. Devices have stable ids: the mgmt
+// nic netdev is "mgmt", data plane nic netdevs are "p001" onwards (see DataNicID), nic devices are
+// "nic-" followed by the netdev id, disk drives are "disk0" onwards, pci bridges are "pci.1"
+// onwards and serial port chardevs are "serial0" onwards (connected to the console multiplexer
+// sockets).
 type QemuLaunchCmd struct {
-	Name    []string
-	UUID    []string
-	Accel   []string
-	Display []string
-	Machine []string
-	Memory  []string
-	CPU     []string
-	Monitor []string
-	Serial  []string
-	Disk    []string
-	Pci     []string
-	MgmtNic []string
-	DataNic []string
-	Extra   []string
+	Name     string
+	UUID     string
+	Accel    string
+	Display  string
+	VNC      *VNC
+	Machine  *Machine
+	Firmware []*Drive
+	Global   []*Global
+	Memory   *Memory
+	CPU      *CPU
+	Monitor  *Monitor
+	Serial   []*Serial
+	// DiskController holds the controller devices (i.e. ahci) the Disk devices attach to.
+	DiskController []*Device
+	Disk           []*Disk
+	Pci            []*Device
+	MgmtNic        *Nic
+	// AuxNic holds additional non data plane nics, rendered right after the mgmt nic.
+	AuxNic  []*Nic
+	DataNic []*Nic
+	SMBIOS  []*SMBIOS
+	FwCfg   []*FwCfg
+	Extra   []LaunchArg
+}
+
+// launchCmdField is a named element of the launch command and its rendered arguments.
+type launchCmdField struct {
+	name string
+	args []string
+}
+
+func optArgs(name, value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	return []string{"-" + name, value}
+}
+
+// fields returns the rendered elements of the launch command in the order they are rendered.
+func (c *QemuLaunchCmd) fields() []launchCmdField {
+	var firmware, serial, disk, pci, mgmtNic, dataNic, smbios, fwCfg, extra []string
+
+	for _, d := range c.Firmware {
+		firmware = append(firmware, d.Args()...)
+	}
+
+	for _, g := range c.Global {
+		firmware = append(firmware, g.Args()...)
+	}
+
+	for _, s := range c.Serial {
+		serial = append(serial, s.Args()...)
+	}
+
+	for _, d := range c.DiskController {
+		disk = append(disk, d.Args()...)
+	}
+
+	for _, d := range c.Disk {
+		disk = append(disk, d.Args()...)
+	}
+
+	for _, d := range c.Pci {
+		pci = append(pci, d.Args()...)
+	}
+
+	mgmtNic = c.MgmtNic.Args()
+
+	for _, n := range c.AuxNic {
+		mgmtNic = append(mgmtNic, n.Args()...)
+	}
+
+	for _, n := range c.DataNic {
+		dataNic = append(dataNic, n.Args()...)
+	}
+
+	for _, s := range c.SMBIOS {
+		smbios = append(smbios, s.Args()...)
+	}
+
+	for _, f := range c.FwCfg {
+		fwCfg = append(fwCfg, f.Args()...)
+	}
+
+	for _, e := range c.Extra {
+		extra = append(extra, e.Args()...)
+	}
+
+	return []launchCmdField{
+		{"Name", optArgs("name", c.Name)},
+		{"UUID", optArgs("uuid", c.UUID)},
+		{"Accel", optArgs("accel", c.Accel)},
+		{"Display", optArgs("display", c.Display)},
+		{"VNC", c.VNC.Args()},
+		{"Machine", c.Machine.Args()},
+		{"Firmware", firmware},
+		{"Memory", c.Memory.Args()},
+		{"CPU", c.CPU.Args()},
+		{"Monitor", c.Monitor.Args()},
+		{"Serial", serial},
+		{"Disk", disk},
+		{"Pci", pci},
+		{"MgmtNic", mgmtNic},
+		{"DataNic", dataNic},
+		{"SMBIOS", smbios},
+		{"FwCfg", fwCfg},
+		{"Extra", extra},
+	}
 }
 
-// Render creates all elements of the launch command and returns it as a slice of string.
+// Render renders all elements of the launch command and returns it as a slice of string.
 func (c *QemuLaunchCmd) Render() ([]string, error) {
-	if (c == &QemuLaunchCmd{}) {
+	if c == nil {
 		return nil, fmt.Errorf("%w: command has not been generated", util.ErrCommandError)
 	}
 
 	var launchCmd []string
-	launchCmd = append(launchCmd, c.Name...)
-	launchCmd = append(launchCmd, c.UUID...)
-	launchCmd = append(launchCmd, c.Accel...)
-	launchCmd = append(launchCmd, c.Display...)
-	launchCmd = append(launchCmd, c.Machine...)
-	launchCmd = append(launchCmd, c.Memory...)
-	launchCmd = append(launchCmd, c.CPU...)
-	launchCmd = append(launchCmd, c.Monitor...)
-	launchCmd = append(launchCmd, c.Serial...)
-	launchCmd = append(launchCmd, c.Disk...)
-	launchCmd = append(launchCmd, c.Pci...)
-	launchCmd = append(launchCmd, c.MgmtNic...)
-	launchCmd = append(launchCmd, c.DataNic...)
-	launchCmd = append(launchCmd, c.Extra...)
+
+	for _, f := range c.fields() {
+		launchCmd = append(launchCmd, f.args...)
+	}
+
+	if len(launchCmd) == 0 {
+		return nil, fmt.Errorf("%w: command has not been generated", util.ErrCommandError)
+	}
 
 	return launchCmd, nil
 }
 
-func (i *Qemu) launchCmdUUID() []string {
-	return []string{"-uuid", uuid.NewString()}
+// DataNicID returns the (netdev) id of the data plane nic nicID, i.e. "p001".
+func DataNicID(nicID int) string {
+	return fmt.Sprintf("p%03d", nicID)
+}
+
+// Nic returns the nic with the (netdev) id id -- the mgmt nic, an aux nic or a data plane nic -- or
+// nil if the command has no such nic.
+func (c *QemuLaunchCmd) Nic(id string) *Nic {
+	nics := append([]*Nic{c.MgmtNic}, c.AuxNic...)
+	nics = append(nics, c.DataNic...)
+
+	for _, n := range nics {
+		if n != nil && n.Netdev != nil && n.Netdev.ID == id {
+			return n
+		}
+	}
+
+	return nil
+}
+
+func (i *Qemu) launchCmdUUID() string {
+	return uuid.NewString()
 }
 
-func (i *Qemu) launchCmdAccel() []string {
+func (i *Qemu) launchCmdAccel() (string, error) {
 	selectedAccel := ""
 
 	for _, prefAccel := range i.Hardware.Acceleration {
@@ -72,169 +193,365 @@ func (i *Qemu) launchCmdAccel() []string {
 	}
 
 	if selectedAccel == "" {
-		panic("no preferred acceleration type(s) available")
+		if !i.Qemu.TCGFallback {
+			return "", fmt.Errorf(
+				"%w: none of the preferred acceleration type(s) %v are available (available: %v), "+
+					"set 'tcg_fallback' in the qemu options to run without hardware acceleration",
+				util.ErrInstanceError,
+				i.Hardware.Acceleration,
+				i.Qemu.Acceleration,
+			)
+		}
+
+		return AccelTCG + ",thread=multi", nil
 	}
 
 	if selectedAccel == AccelNone {
-		return []string{}
+		return "", nil
 	}
 
-	return []string{"-accel", selectedAccel}
+	return selectedAccel, nil
+}
+
+// usesTCG returns true if the launch command falls back to tcg.
+func (c *QemuLaunchCmd) usesTCG() bool {
+	return strings.Split(c.Accel, ",")[0] == AccelTCG
 }
 
-func (i *Qemu) launchCmdDisplay() []string {
+func (i *Qemu) launchCmdDisplay() string {
 	d := OptNone
 
 	if i.Advanced != nil && i.Advanced.Display != "" {
 		d = i.Advanced.Display
 	}
 
-	return []string{"-display", d}
+	return d
 }
 
-func (i *Qemu) launchCmdMachine() []string {
-	m := OptMachinePc
+func (i *Qemu) launchCmdVNC() *VNC {
+	if !i.Advanced.VNCEnabled() || i.Hardware.VNCPort == 0 {
+		return nil
+	}
 
-	if i.Advanced != nil && i.Advanced.Machine != "" {
-		m = i.Advanced.Machine
+	v := &VNC{
+		Host:    i.Advanced.VNC.Address(),
+		Display: config.VNCDisplay(i.Hardware.VNCPort),
 	}
 
-	return []string{"-machine", m}
+	// the password itself is set over the monitor after launch so it never shows up in the launch
+	// command (or the process list)
+	if i.Advanced.VNC.HasPassword() {
+		v.Props.Set("password", "on")
+	}
+
+	return v
 }
 
-func (i *Qemu) launchCmdMemory() []string {
-	return []string{"-m", fmt.Sprintf("%d", i.Hardware.Memory)}
+func (i *Qemu) launchCmdMachine() *Machine {
+	m := &Machine{Type: OptMachinePc}
+
+	if i.Advanced != nil && i.Advanced.Machine != "" {
+		// the configured machine may carry options, i.e. "q35,kernel-irqchip=split", the type is
+		// only the head so the options are rendered (and validated) as properties
+		props := ParseProps(i.Advanced.Machine)
+
+		if props[0].Key == "" {
+			m.Type, props = props[0].Value, props[1:]
+		}
+
+		if t, ok := props.Get("type"); ok {
+			m.Type = t
+			props.Del("type")
+		}
+
+		m.Props = props
+	} else if i.secureBoot() {
+		m.Type = OptMachineQ35
+	}
+
+	// secure boot requires system management mode so the guest can't just write the vars flash
+	if i.secureBoot() {
+		m.Props.Set("smm", "on")
+	}
+
+	return m
 }
 
-func (i *Qemu) launchCmdCPU() []string {
-	cpuCmd := []string{}
+func (i *Qemu) launchCmdMemory() *Memory {
+	mem := &Memory{SizeMB: i.Hardware.Memory}
 
-	if i.Advanced == nil {
-		return cpuCmd
+	if i.Advanced == nil || i.Advanced.Memory == nil {
+		return mem
 	}
 
-	c := i.Advanced.CPU
+	m := i.Advanced.Memory
 
-	if c == nil {
-		return cpuCmd
+	switch {
+	case m.NumaNode != nil:
+		backend := &Object{
+			Type:  "memory-backend-ram",
+			ID:    "mem0",
+			Props: Props{{Key: "size", Value: fmt.Sprintf("%dM", i.Hardware.Memory)}},
+		}
+
+		if m.Hugepages {
+			backend.Type = "memory-backend-file"
+			backend.Props = append(
+				backend.Props,
+				Prop{Key: "mem-path", Value: m.HugepagesDir()},
+				Prop{Key: "share", Value: "on"},
+				Prop{Key: "prealloc", Value: "on"},
+			)
+		}
+
+		backend.Props = append(
+			backend.Props,
+			Prop{Key: "host-nodes", Value: strconv.Itoa(*m.NumaNode)},
+			Prop{Key: "policy", Value: "bind"},
+		)
+
+		mem.Backend = backend
+	case m.Hugepages:
+		mem.MemPath = m.HugepagesDir()
+		mem.Prealloc = true
 	}
 
-	if c.Emulation != "" {
-		cpuCmd = append(cpuCmd, []string{"-cpu", c.Emulation}...)
+	return mem
+}
+
+func (i *Qemu) launchCmdCPU() *CPU {
+	if i.Advanced == nil || i.Advanced.CPU == nil {
+		return nil
 	}
 
+	c := i.Advanced.CPU
+
+	cpu := &CPU{Model: c.Emulation}
+
 	if c.Cores != 0 {
-		if len(cpuCmd) == 0 {
-			cpuCmd = append(cpuCmd, []string{"-cpu", "max"}...)
+		if cpu.Model == "" {
+			cpu.Model = "max"
 		}
 
 		if c.Threads != 0 && c.Sockets != 0 {
-			cpuCmd = append(
-				cpuCmd,
-				[]string{
-					"-smp",
-					fmt.Sprintf("cores=%d,threads=%d,sockets=%d", c.Cores, c.Threads, c.Sockets),
-				}...)
+			cpu.SMP = Props{
+				{Key: "cores", Value: strconv.Itoa(c.Cores)},
+				{Key: "threads", Value: strconv.Itoa(c.Threads)},
+				{Key: "sockets", Value: strconv.Itoa(c.Sockets)},
+			}
 		} else if c.Threads == 0 && c.Sockets == 0 {
-			cpuCmd = append(
-				cpuCmd,
-				[]string{"-smp", fmt.Sprint(c.Cores)}...)
+			cpu.SMP = Props{{Value: strconv.Itoa(c.Cores)}}
 		}
 	}
 
-	return cpuCmd
+	return cpu
+}
+
+func (i *Qemu) launchCmdMonitor() *Monitor {
+	return &Monitor{Chardev: &Chardev{
+		Backend: "socket",
+		ID:      "monitor",
+		Props: Props{
+			// served like the serial ports -- localhost unless the instance is packaged
+			{Key: "host", Value: i.Console.Address()},
+			{Key: "port", Value: strconv.Itoa(i.Hardware.MonitorPort)},
+			{Key: "server", Value: "on"},
+			{Key: "wait", Value: "off"},
+		},
+	}}
 }
 
-func (i *Qemu) launchCmdMonitor() []string {
-	return []string{
-		"-monitor",
-		fmt.Sprintf("tcp:0.0.0.0:%d,server,nowait", i.Hardware.MonitorPort),
+// diskFile returns the path of the disk d, the instance disk for disks without a file.
+func (i *Qemu) diskFile(d *config.Disk) string {
+	switch {
+	case d.File == "":
+		return i.Disk
+	case filepath.IsAbs(d.File):
+		return d.File
 	}
+
+	return filepath.Join(filepath.Dir(i.Disk), d.File)
 }
 
-func (i *Qemu) launchCmdSerial() []string {
-	var serialCmd []string
+// BuildDisks builds the controllers, drives and devices for the disks. If none of the disks is the
+// instance disk (has no file), the instance disk is attached first with the default settings. This
+// method is exported such that platforms may use it if they need to modify the disks.
+func (i *Qemu) BuildDisks(disks []*config.Disk) (controllers []*Device, built []*Disk) {
+	hasInstanceDisk := false
+
+	for _, d := range disks {
+		if d.File == "" {
+			hasInstanceDisk = true
+		}
+	}
 
-	for _, port := range i.Hardware.SerialPorts {
-		serialCmd = append(
-			serialCmd,
-			[]string{"-serial", fmt.Sprintf("telnet:0.0.0.0:%d,server,nowait", port)}...)
+	if !hasInstanceDisk {
+		disks = append([]*config.Disk{{}}, disks...)
 	}
 
-	return serialCmd
+	hasController := map[string]bool{}
+	sataPort := 0
+
+	for idx, d := range disks {
+		id := fmt.Sprintf("disk%d", idx)
+
+		format := d.Format
+		if format == "" {
+			format = config.DiskFormatQcow2
+		}
+
+		drive := &Drive{
+			ID: id,
+			Props: Props{
+				{Key: "if", Value: "none"},
+				{Key: "file", Value: i.diskFile(d)},
+				{Key: "format", Value: format},
+			},
+		}
+
+		if d.Cache != "" {
+			drive.Props.Set("cache", d.Cache)
+		}
+
+		if d.AIO != "" {
+			drive.Props.Set("aio", d.AIO)
+		}
+
+		if d.Discard {
+			drive.Props.Set("discard", "unmap")
+		}
+
+		if d.ReadOnly {
+			drive.Props.Set("readonly", "on")
+		}
+
+		device := &Device{Driver: "ide-hd", Props: Props{{Key: "drive", Value: id}}}
+
+		switch d.Bus {
+		case config.DiskBusVirtioBlk:
+			device.Driver = "virtio-blk-pci"
+		case config.DiskBusVirtioSCSI:
+			if !hasController[d.Bus] {
+				hasController[d.Bus] = true
+
+				controllers = append(controllers, &Device{Driver: "virtio-scsi-pci", ID: "scsi0"})
+			}
+
+			device.Driver = "scsi-hd"
+			device.Props.Set("bus", "scsi0.0")
+		case config.DiskBusSATA:
+			if !hasController[d.Bus] {
+				hasController[d.Bus] = true
+
+				controllers = append(controllers, &Device{Driver: "ahci", ID: "ahci0"})
+			}
+
+			device.Props.Set("bus", fmt.Sprintf("ahci0.%d", sataPort))
+			sataPort++
+		}
+
+		if d.BootIndex > 0 {
+			device.Props.Set("bootindex", strconv.Itoa(d.BootIndex))
+		}
+
+		built = append(built, &Disk{Drive: drive, Device: device})
+	}
+
+	return controllers, built
 }
 
-func (i *Qemu) launchCmdDisk() []string {
-	return []string{"-drive", fmt.Sprintf("if=ide,file=%s,format=qcow2", i.Disk)}
+func (i *Qemu) launchCmdDisk() (controllers []*Device, disks []*Disk) {
+	if len(i.Hardware.Disks) == 0 {
+		return nil, []*Disk{{
+			Drive: &Drive{Props: Props{
+				{Key: "if", Value: "ide"},
+				{Key: "file", Value: i.Disk},
+				{Key: "format", Value: config.DiskFormatQcow2},
+			}},
+		}}
+	}
+
+	return i.BuildDisks(i.Hardware.Disks)
 }
 
-func (i *Qemu) launchCmdPci() []string {
-	var pciCmd []string
+// PciAddr returns the pci bridge (bus) id and the address on that bridge of the pci slot. Slots
+// are numbered across all bridges, each bridge holding NicPerBus slots, the first slot on the first
+// bridge is left empty (data plane nics are numbered from one). Platforms that place other devices
+// (i.e. the mgmt nic) on the bridges should offset the slots of the data plane nics accordingly.
+func (i *Qemu) PciAddr(slot int) (busID, busAddr int) {
+	return slot/i.Hardware.NicPerBus + 1, slot%i.Hardware.NicPerBus + 1
+}
 
-	nicCount := float64(i.Hardware.NicCount)
-	nicPerBus := float64(i.Hardware.NicPerBus)
+// BuildPci builds the pci bridges required to hold all slots up to and including lastSlot (see
+// PciAddr). This method is exported such that platforms that offset their data plane nics may
+// build the matching bridges.
+func (i *Qemu) BuildPci(lastSlot int) []*Device {
+	var bridges []*Device
 
-	busRequired := int(math.Ceil(nicCount / nicPerBus))
+	busRequired, _ := i.PciAddr(lastSlot)
 
 	for busID := 1; busID < busRequired+1; busID++ {
-		pciCmd = append(
-			pciCmd,
-			[]string{"-device", fmt.Sprintf("pci-bridge,chassis_nr=%d,id=pci.%d", busID, busID)}...)
+		bridges = append(
+			bridges,
+			&Device{
+				Driver: "pci-bridge",
+				ID:     fmt.Sprintf("pci.%d", busID),
+				Props:  Props{{Key: "chassis_nr", Value: strconv.Itoa(busID)}},
+			},
+		)
 	}
 
-	return pciCmd
+	return bridges
 }
 
-func (i *Qemu) launchCmdMgmtNic() []string {
-	nicCmd := []string{"-device", fmt.Sprintf("%s,netdev=mgmt", i.Hardware.NicType), "-netdev"}
-
-	if i.MgmtIntf.Nat != nil {
-		mgmtIntf := "user,id=mgmt,net=10.0.0.0/24,tftp=/tftpboot"
-
-		if len(i.MgmtIntf.Nat.TCP) > 0 {
-			var tcpNats []string
+func (i *Qemu) launchCmdPci() []*Device {
+	return i.BuildPci(i.Hardware.NicCount)
+}
 
-			for _, natPair := range i.MgmtIntf.Nat.TCP {
-				tcpNats = append(
-					tcpNats,
-					fmt.Sprintf(
-						"hostfwd=tcp::%d-10.0.0.15:%d",
-						natPair.HostSide,
-						natPair.InstanceSide,
-					),
-				)
-			}
+func (i *Qemu) launchCmdMgmtNic() (*Nic, error) {
+	netdev := &Netdev{ID: MgmtNicID}
 
-			joinedTCPNats := strings.Join(tcpNats, ",")
-			mgmtIntf = mgmtIntf + "," + joinedTCPNats
+	switch {
+	case i.MgmtIntf == nil:
+	case i.MgmtIntf.Nat != nil:
+		netdev.Type = "user"
+		netdev.Props = Props{
+			{Key: "net", Value: "10.0.0.0/24"},
+			{Key: "tftp", Value: "/tftpboot"},
 		}
 
-		if len(i.MgmtIntf.Nat.UDP) > 0 {
-			var udpNats []string
-
-			for _, natPair := range i.MgmtIntf.Nat.UDP {
-				udpNats = append(
-					udpNats,
-					fmt.Sprintf(
-						"hostfwd=udp::%d-10.0.0.15:%d",
-						natPair.HostSide,
-						natPair.InstanceSide,
-					),
-				)
-			}
+		for _, natPair := range i.MgmtIntf.Nat.TCP {
+			netdev.Props = append(netdev.Props, Prop{
+				Key:   "hostfwd",
+				Value: fmt.Sprintf("tcp::%d-10.0.0.15:%d", natPair.HostSide, natPair.InstanceSide),
+			})
+		}
 
-			joinedUDPNats := strings.Join(udpNats, ",")
-			mgmtIntf = mgmtIntf + "," + joinedUDPNats
+		for _, natPair := range i.MgmtIntf.Nat.UDP {
+			netdev.Props = append(netdev.Props, Prop{
+				Key:   "hostfwd",
+				Value: fmt.Sprintf("udp::%d-10.0.0.15:%d", natPair.HostSide, natPair.InstanceSide),
+			})
 		}
+	case i.MgmtIntf.Bridge != nil:
+		netdev.Type = "tap"
+		netdev.Props = Props{
+			{Key: "ifname", Value: fmt.Sprintf("tap%d", i.ID)},
+			{Key: "script", Value: "no"},
+			{Key: "downscript", Value: "no"},
+		}
+	}
 
-		nicCmd = append(nicCmd, mgmtIntf)
-	} else if i.MgmtIntf.Bridge != nil {
-		nicCmd = append(nicCmd, fmt.Sprintf("tap,ifname=tap%d,script=no,downscript=no,id=mgmt", i.ID))
-	} else {
-		panic("one of nat or bridge must be set on instance...")
+	if netdev.Type == "" {
+		return nil, fmt.Errorf(
+			"%w: instance '%s' has neither a nat nor a bridge management interface, set one of "+
+				"them in the mgmt_interface of the instance",
+			util.ErrValidationError,
+			i.Name,
+		)
 	}
 
-	return nicCmd
+	return NewNic(i.Hardware.NicType, netdev), nil
 }
 
 // GenerateMac generates a mac address with a last octet of lastOctet.
@@ -251,51 +568,23 @@ func (i *Qemu) GenerateMac(lastOctet int) string {
 }
 
 func (i *Qemu) buildDataNicSocketConn(
-	paddedNicID string,
+	netdev *Netdev,
 	nicMap *config.SocketConnectPair,
-) []string {
-	nicCmd := make([]string, 0)
+) {
+	netdev.Type = "socket"
 
 	if nicMap.Connect != -1 {
-		nicCmd = append(
-			nicCmd,
-			fmt.Sprintf(
-				"socket,id=p%s,udp=127.0.0.1:%d,listen=:%d",
-				paddedNicID,
-				nicMap.Connect,
-				nicMap.Listen,
-			),
-		)
-	} else {
-		nicCmd = append(
-			nicCmd,
-			fmt.Sprintf(
-				"socket,id=p%s,listen=:%d",
-				paddedNicID,
-				nicMap.Listen,
-			),
-		)
+		netdev.Props.Set("udp", fmt.Sprintf("127.0.0.1:%d", nicMap.Connect))
 	}
 
-	return nicCmd
+	netdev.Props.Set("listen", fmt.Sprintf(":%d", nicMap.Listen))
 }
 
-// BuildDataNic builds a string representation of a "dataplane" nic for a qemu virtual machine. This
-// method is exported such that platforms may use it if they need to modify some behavior of the
-// qemu command creation.
-func (i *Qemu) BuildDataNic(nicID, busID, busAddr int, paddedNicID string) []string {
-	nicCmd := []string{
-		"-device",
-		fmt.Sprintf(
-			"%s,netdev=p%s,bus=pci.%d,addr=0x%x,mac=%s",
-			i.Hardware.NicType,
-			paddedNicID,
-			busID,
-			busAddr,
-			i.GenerateMac(nicID),
-		),
-		"-netdev",
-	}
+// BuildDataNic builds a "dataplane" nic for a qemu virtual machine at address busAddr on the pci
+// bridge busID. This method is exported such that platforms may use it if they need to modify some
+// behavior of the qemu command creation.
+func (i *Qemu) BuildDataNic(nicID, busID, busAddr int) *Nic {
+	netdev := &Netdev{ID: DataNicID(nicID)}
 
 	var nicMap *config.SocketConnectPair
 
@@ -305,37 +594,41 @@ func (i *Qemu) BuildDataNic(nicID, busID, busAddr int, paddedNicID string) []str
 		nicMap, ok = i.DataPlaneIntf.SocketConnectMap[nicID]
 	}
 
-	if util.DirectoryExists(fmt.Sprintf("/sys/class/net/eth%d", nicID)) {
-		nicCmd = append(
-			nicCmd,
-			fmt.Sprintf(
-				"tap,id=p%s,ifname=tap%d,script=/etc/tc-tap-ifup,downscript=no",
-				paddedNicID,
-				nicID,
-			),
-		)
+	hostIntf := fmt.Sprintf("eth%d", nicID)
+
+	if i.DataPlaneIntf != nil && i.DataPlaneIntf.HostIntfs[nicID] != "" {
+		hostIntf = i.DataPlaneIntf.HostIntfs[nicID]
+	}
+
+	if util.DirectoryExists(fmt.Sprintf("/sys/class/net/%s", hostIntf)) {
+		netdev.Type = "tap"
+		netdev.Props = Props{
+			{Key: "ifname", Value: fmt.Sprintf("tap%d", nicID)},
+			{Key: "script", Value: "/etc/tc-tap-ifup"},
+			{Key: "downscript", Value: "no"},
+		}
 	} else if ok && nicMap != nil {
-		nicCmd = append(nicCmd, i.buildDataNicSocketConn(paddedNicID, nicMap)...)
+		i.buildDataNicSocketConn(netdev, nicMap)
 	} else {
-		nicCmd = append(
-			nicCmd, fmt.Sprintf("socket,id=p%s,listen=:%d", paddedNicID, nicID+defaultSocketPad))
+		netdev.Type = "socket"
+		netdev.Props = Props{{Key: "listen", Value: fmt.Sprintf(":%d", nicID+defaultSocketPad)}}
 	}
 
-	return nicCmd
+	nic := NewNic(i.Hardware.NicType, netdev)
+	nic.Device.SetPciAddr(busID, busAddr)
+	nic.Device.Props.Set("mac", i.GenerateMac(nicID))
+
+	return nic
 }
 
-func (i *Qemu) launchCmdDataNic() []string {
-	var nicCmd []string
+func (i *Qemu) launchCmdDataNic() []*Nic {
+	var nics []*Nic
 
 	for nicID := 1; nicID < i.Hardware.NicCount+1; nicID++ {
-		busID := int(math.Floor(float64(nicID)/float64(i.Hardware.NicPerBus))) + 1
-		busAddr := (nicID % i.Hardware.NicPerBus) + 1
-		paddedNicID := fmt.Sprintf("%03d", nicID)
+		busID, busAddr := i.PciAddr(nicID)
 
-		nicCmd = append(
-			nicCmd,
-			i.BuildDataNic(nicID, busID, busAddr, paddedNicID)...)
+		nics = append(nics, i.BuildDataNic(nicID, busID, busAddr))
 	}
 
-	return nicCmd
+	return nics
 }
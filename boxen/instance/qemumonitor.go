@@ -0,0 +1,110 @@
+package instance
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+const (
+	monitorPrompt  = "(qemu) "
+	monitorTimeout = 10
+
+	monitorRetries  = 10
+	monitorInterval = 1
+)
+
+func readMonitorPrompt(conn net.Conn) ([]byte, error) {
+	var out []byte
+
+	b := make([]byte, util.MaxBuffer)
+
+	for {
+		n, err := conn.Read(b)
+		if err != nil {
+			return out, err
+		}
+
+		out = append(out, b[:n]...)
+
+		if bytes.HasSuffix(out, []byte(monitorPrompt)) {
+			return bytes.TrimSuffix(out, []byte(monitorPrompt)), nil
+		}
+	}
+}
+
+// MonitorCommand sends a single command to the qemu (human) monitor of the instance and returns
+// any output of the command.
+func (i *Qemu) MonitorCommand(cmd string) (string, error) {
+	i.Loggers.Base.Debugf("sending monitor command '%s'", cmd)
+
+	return i.monitorCommand(cmd)
+}
+
+// monitorCommandRetry is MonitorCommand retried for a bit, the monitor is not available right away
+// after launch. Secret commands (i.e. setting a password) are not logged.
+func (i *Qemu) monitorCommandRetry(cmd string, secret bool) (string, error) {
+	if !secret {
+		i.Loggers.Base.Debugf("sending monitor command '%s'", cmd)
+	}
+
+	var out string
+
+	var err error
+
+	for attempt := 0; attempt < monitorRetries; attempt++ {
+		out, err = i.monitorCommand(cmd)
+		if err == nil {
+			break
+		}
+
+		time.Sleep(monitorInterval * time.Second)
+	}
+
+	return out, err
+}
+
+func (i *Qemu) monitorCommand(cmd string) (string, error) {
+	conn, err := net.DialTimeout(
+		"tcp",
+		i.consoleDialAddress(i.Hardware.MonitorPort),
+		monitorTimeout*time.Second,
+	)
+	if err != nil {
+		return "", fmt.Errorf("%w: failed connecting to qemu monitor: %s", util.ErrInstanceError, err)
+	}
+
+	defer conn.Close()
+
+	_ = conn.SetDeadline(time.Now().Add(monitorTimeout * time.Second))
+
+	// the monitor greets us with a banner and a prompt, we don't care about that
+	_, err = readMonitorPrompt(conn)
+	if err != nil {
+		return "", fmt.Errorf("%w: failed reading qemu monitor prompt: %s", util.ErrInstanceError, err)
+	}
+
+	_, err = conn.Write([]byte(cmd + "\n"))
+	if err != nil {
+		return "", err
+	}
+
+	out, err := readMonitorPrompt(conn)
+	if err != nil {
+		return "", fmt.Errorf(
+			"%w: failed reading qemu monitor command output: %s",
+			util.ErrInstanceError,
+			err,
+		)
+	}
+
+	// the monitor echoes the command back, so drop the first line of output
+	if idx := bytes.IndexByte(out, '\n'); idx >= 0 {
+		out = out[idx+1:]
+	}
+
+	return string(bytes.TrimSpace(out)), nil
+}
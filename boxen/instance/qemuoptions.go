@@ -1,10 +1,34 @@
 package instance
 
-import "github.com/carlmontanari/boxen/boxen/util"
+import (
+	"errors"
+
+	"github.com/carlmontanari/boxen/boxen/util"
+)
 
 type qemuOpts struct {
-	launchModifier func(c *QemuLaunchCmd)
-	sudo           bool
+	launchModifier     func(c *QemuLaunchCmd)
+	launchModifierName string
+	sudo               bool
+}
+
+// newQemuOpts applies the options, ignoring any options that are not qemu options.
+func newQemuOpts(opts ...Option) (*qemuOpts, error) {
+	qOpts := &qemuOpts{}
+
+	for _, option := range opts {
+		err := option(qOpts)
+
+		if err != nil {
+			if errors.Is(err, util.ErrIgnoredOption) {
+				continue
+			}
+
+			return nil, err
+		}
+	}
+
+	return qOpts, nil
 }
 
 // WithLaunchModifier sets an option to modify qemu launch command.
@@ -21,6 +45,21 @@ func WithLaunchModifier(f func(c *QemuLaunchCmd)) Option {
 	}
 }
 
+// WithLaunchModifierName sets the name the launch modifier is referred to by in dry run output, by
+// default this is the name of the modifier function.
+func WithLaunchModifierName(n string) Option {
+	return func(o interface{}) error {
+		q, ok := o.(*qemuOpts)
+
+		if ok {
+			q.launchModifierName = n
+			return nil
+		}
+
+		return util.ErrIgnoredOption
+	}
+}
+
 // WithSudo tells boxen to start/stop instances with sudo or not.
 func WithSudo(b bool) Option {
 	return func(o interface{}) error {
@@ -0,0 +1,404 @@
+package instance
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/carlmontanari/boxen/boxen/command"
+	"github.com/carlmontanari/boxen/boxen/config"
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+const (
+	sysCPUOnline = "/sys/devices/system/cpu/online"
+	cgroupRoot   = "/sys/fs/cgroup"
+
+	cpusetRemoveRetries  = 50
+	cpusetRemoveInterval = 100
+)
+
+// procMeminfo and sysNodeDir are the host memory files, variables so tests can use captured ones.
+var (
+	procMeminfo = "/proc/meminfo"            //nolint:gochecknoglobals
+	sysNodeDir  = "/sys/devices/system/node" //nolint:gochecknoglobals
+)
+
+var vCPUThreadIDPattern = regexp.MustCompile(`thread_id=(\d+)`) //nolint:gochecknoglobals
+
+// meminfoValue returns the value of the key from /proc/meminfo, values in kB are returned in kB.
+func meminfoValue(key string) (int, error) {
+	f, err := os.Open(procMeminfo)
+	if err != nil {
+		return 0, err
+	}
+
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+
+		if len(fields) < 2 || strings.TrimSuffix(fields[0], ":") != key { //nolint:gomnd
+			continue
+		}
+
+		return strconv.Atoi(fields[1])
+	}
+
+	return 0, fmt.Errorf("%w: '%s' not found in %s", util.ErrInspectionError, key, procMeminfo)
+}
+
+func readIntFile(f string) (int, error) {
+	b, err := os.ReadFile(f)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(strings.TrimSpace(string(b)))
+}
+
+// checkHugepages checks there are enough free (default size) hugepages for the instance memory, on
+// the numa node the memory is bound to if set.
+func (i *Qemu) checkHugepages(m *config.AdvancedMemory) error {
+	if !util.DirectoryExists(m.HugepagesDir()) {
+		return fmt.Errorf(
+			"%w: hugepages requested but hugetlbfs mount '%s' does not exist",
+			util.ErrValidationError,
+			m.HugepagesDir(),
+		)
+	}
+
+	sizeKB, err := meminfoValue("Hugepagesize")
+	if err != nil || sizeKB == 0 {
+		return fmt.Errorf(
+			"%w: hugepages requested but the host does not support hugepages",
+			util.ErrValidationError,
+		)
+	}
+
+	where := "host"
+
+	var free int
+
+	if m.NumaNode != nil {
+		where = fmt.Sprintf("numa node %d", *m.NumaNode)
+
+		free, err = readIntFile(filepath.Join(
+			sysNodeDir,
+			fmt.Sprintf("node%d", *m.NumaNode),
+			"hugepages",
+			fmt.Sprintf("hugepages-%dkB", sizeKB),
+			"free_hugepages",
+		))
+	} else {
+		free, err = meminfoValue("HugePages_Free")
+	}
+
+	if err != nil {
+		return fmt.Errorf(
+			"%w: failed reading free hugepages of %s: %s",
+			util.ErrInspectionError,
+			where,
+			err,
+		)
+	}
+
+	required := (i.Hardware.Memory*1024 + sizeKB - 1) / sizeKB //nolint:gomnd
+
+	if free < required {
+		return fmt.Errorf(
+			"%w: hugepages requested but %s has %d free %dkB hugepages, instance needs %d",
+			util.ErrValidationError,
+			where,
+			free,
+			sizeKB,
+			required,
+		)
+	}
+
+	return nil
+}
+
+// CheckHostCapabilities validates the cpu pinning, hugepages and numa settings of the instance and
+// checks the host can actually provide them.
+func (i *Qemu) CheckHostCapabilities() error {
+	if i.Advanced == nil {
+		return nil
+	}
+
+	m := i.Advanced.Memory
+
+	err := m.Validate()
+	if err != nil {
+		return err
+	}
+
+	cpus, err := i.Advanced.CPU.PinnedCPUs()
+	if err != nil {
+		return err
+	}
+
+	if len(cpus) > 0 {
+		b, err := os.ReadFile(sysCPUOnline)
+		if err != nil {
+			return err
+		}
+
+		online, err := config.ParseCPUList(string(b))
+		if err != nil {
+			return err
+		}
+
+		for _, cpu := range cpus {
+			if !util.IntSliceContains(online, cpu) {
+				return fmt.Errorf(
+					"%w: pinned cpu %d is not an online host cpu",
+					util.ErrValidationError,
+					cpu,
+				)
+			}
+		}
+	}
+
+	if m == nil {
+		return nil
+	}
+
+	if m.NumaNode != nil &&
+		!util.DirectoryExists(filepath.Join(sysNodeDir, fmt.Sprintf("node%d", *m.NumaNode))) {
+		return fmt.Errorf(
+			"%w: numa node %d does not exist on the host",
+			util.ErrValidationError,
+			*m.NumaNode,
+		)
+	}
+
+	if m.Hugepages {
+		return i.checkHugepages(m)
+	}
+
+	return nil
+}
+
+// vCPUThreadIDs returns the host thread ids of the vCPUs of the running instance from the monitor.
+func (i *Qemu) vCPUThreadIDs() ([]int, error) {
+	out, err := i.monitorCommandRetry("info cpus", false)
+	if err != nil {
+		return nil, err
+	}
+
+	var threadIDs []int
+
+	for _, match := range vCPUThreadIDPattern.FindAllStringSubmatch(out, -1) {
+		threadID, _ := strconv.Atoi(match[1])
+		threadIDs = append(threadIDs, threadID)
+	}
+
+	if len(threadIDs) == 0 {
+		return nil, fmt.Errorf(
+			"%w: no vCPU thread ids in monitor 'info cpus' output",
+			util.ErrInspectionError,
+		)
+	}
+
+	return threadIDs, nil
+}
+
+// cpusetDirs returns the boxen parent cgroup and the cgroup of the instance, and whether the host
+// uses cgroup v2.
+func (i *Qemu) cpusetDirs() (parent, dir string, v2 bool) {
+	v2 = util.FileExists(filepath.Join(cgroupRoot, "cgroup.controllers"))
+
+	parent = filepath.Join(cgroupRoot, "cpuset", "boxen")
+	if v2 {
+		parent = filepath.Join(cgroupRoot, "boxen")
+	}
+
+	return parent, filepath.Join(parent, i.Name), v2
+}
+
+// cpusetPin moves the qemu process pid into a boxen cgroup cpuset for the instance, restricted to
+// the cpus and mems.
+func (i *Qemu) cpusetPin(pid int, cpus, mems string) error {
+	parent, dir, v2 := i.cpusetDirs()
+
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	var writes [][2]string
+
+	if v2 {
+		writes = append(
+			writes,
+			[2]string{filepath.Join(cgroupRoot, "cgroup.subtree_control"), "+cpuset"},
+			[2]string{filepath.Join(parent, "cgroup.subtree_control"), "+cpuset"},
+		)
+	} else {
+		// v1 cpusets can't hold tasks (or children) without cpus and mems, the boxen parent simply
+		// gets everything the root has
+		for _, f := range []string{"cpuset.cpus", "cpuset.mems"} {
+			b, err := os.ReadFile(filepath.Join(cgroupRoot, "cpuset", f))
+			if err != nil {
+				return err
+			}
+
+			writes = append(writes, [2]string{filepath.Join(parent, f), string(b)})
+
+			if f == "cpuset.mems" && mems == "" {
+				mems = strings.TrimSpace(string(b))
+			}
+		}
+	}
+
+	writes = append(writes, [2]string{filepath.Join(dir, "cpuset.cpus"), cpus})
+
+	if mems != "" {
+		writes = append(writes, [2]string{filepath.Join(dir, "cpuset.mems"), mems})
+	}
+
+	writes = append(writes, [2]string{filepath.Join(dir, "cgroup.procs"), strconv.Itoa(pid)})
+
+	for _, w := range writes {
+		err = os.WriteFile(w[0], []byte(w[1]), util.FilePerms)
+		if err != nil {
+			return fmt.Errorf(
+				"%w: failed writing cgroup file '%s' (cpuset pinning requires running boxen as "+
+					"root): %s",
+				util.ErrInstanceError,
+				w[0],
+				err,
+			)
+		}
+	}
+
+	return nil
+}
+
+// removeCpuset removes the cgroup cpuset of the instance, if any. A cgroup can only be removed once
+// it holds no processes, so this is retried for a bit while the stopped qemu process exits.
+func (i *Qemu) removeCpuset() {
+	_, dir, _ := i.cpusetDirs()
+
+	if !util.DirectoryExists(dir) {
+		return
+	}
+
+	var err error
+
+	for attempt := 0; attempt < cpusetRemoveRetries; attempt++ {
+		err = os.Remove(dir)
+		if err == nil || os.IsNotExist(err) {
+			return
+		}
+
+		time.Sleep(cpusetRemoveInterval * time.Millisecond)
+	}
+
+	i.Loggers.Base.Criticalf("failed removing cpuset cgroup '%s': %s", dir, err)
+}
+
+// pinCPUs pins the vCPUs of the running instance to the configured host cpus.
+func (i *Qemu) pinCPUs() error {
+	if i.Advanced == nil {
+		return nil
+	}
+
+	cpus, err := i.Advanced.CPU.PinnedCPUs()
+	if err != nil || len(cpus) == 0 {
+		return err
+	}
+
+	threadIDs, err := i.vCPUThreadIDs()
+	if err != nil {
+		return err
+	}
+
+	if len(threadIDs) > len(cpus) {
+		i.Loggers.Base.Infof(
+			"instance has %d vCPUs but only %d pinned cpus, vCPUs will share cpus",
+			len(threadIDs),
+			len(cpus),
+		)
+	}
+
+	if i.Advanced.CPU.PinningMode == config.PinningModeCpuset {
+		// the launched pid may be sudo, the vCPU threads belong to the actual qemu process
+		pid, err := threadGroupID(threadIDs[0])
+		if err != nil {
+			return err
+		}
+
+		mems := ""
+
+		if i.Advanced.Memory != nil && i.Advanced.Memory.NumaNode != nil {
+			mems = strconv.Itoa(*i.Advanced.Memory.NumaNode)
+		}
+
+		i.Loggers.Base.Debugf("pinning qemu process %d to cpuset '%s'", pid, i.Advanced.CPU.Pinning)
+
+		return i.cpusetPin(pid, i.Advanced.CPU.Pinning, mems)
+	}
+
+	for idx, threadID := range threadIDs {
+		cpu := cpus[idx%len(cpus)]
+
+		i.Loggers.Base.Debugf("pinning vCPU %d (thread %d) to cpu %d", idx, threadID, cpu)
+
+		r, err := command.Execute(
+			"taskset",
+			command.WithArgs(
+				[]string{"-p", "-c", strconv.Itoa(cpu), strconv.Itoa(threadID)},
+			),
+			command.WithSudo(true),
+			command.WithWait(true),
+		)
+		if err != nil {
+			// the result is nil if the command never ran, i.e. the sudo password could not be read
+			if r == nil {
+				return err
+			}
+
+			stderr, _ := r.ReadStderr()
+
+			return fmt.Errorf(
+				"%w: failed pinning vCPU %d to cpu %d: %s %s",
+				util.ErrInstanceError,
+				idx,
+				cpu,
+				err,
+				stderr,
+			)
+		}
+	}
+
+	return nil
+}
+
+// threadGroupID returns the process id of the thread.
+func threadGroupID(threadID int) (int, error) {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/status", threadID))
+	if err != nil {
+		return 0, err
+	}
+
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), "Tgid:") {
+			return strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "Tgid:")))
+		}
+	}
+
+	return 0, fmt.Errorf("%w: no process id for thread %d", util.ErrInspectionError, threadID)
+}
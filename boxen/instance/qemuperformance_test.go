@@ -0,0 +1,173 @@
+package instance
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/carlmontanari/boxen/boxen/config"
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+// testMeminfo is a trimmed /proc/meminfo of a host with 2MB hugepages.
+const testMeminfo = `MemTotal:       65782460 kB
+MemFree:        40160312 kB
+MemAvailable:   55102336 kB
+HugePages_Total:    2048
+HugePages_Free:     %d
+HugePages_Rsvd:        0
+HugePages_Surp:        0
+Hugepagesize:       2048 kB
+Hugetlb:         4194304 kB
+`
+
+// testHostMemory points the host memory files at a temporary meminfo with hostFree free hugepages
+// and a numa node 0 with nodeFree free hugepages, they are restored when the test completes.
+func testHostMemory(t *testing.T, meminfo string, hostFree, nodeFree int) {
+	t.Helper()
+
+	d := t.TempDir()
+
+	origMeminfo, origNodeDir := procMeminfo, sysNodeDir
+
+	t.Cleanup(func() {
+		procMeminfo, sysNodeDir = origMeminfo, origNodeDir
+	})
+
+	procMeminfo = filepath.Join(d, "meminfo")
+	sysNodeDir = filepath.Join(d, "node")
+
+	err := os.WriteFile(procMeminfo, []byte(fmt.Sprintf(meminfo, hostFree)), util.FilePerms)
+	if err != nil {
+		t.Fatalf("failed writing meminfo: %s", err)
+	}
+
+	nodeHugepages := filepath.Join(sysNodeDir, "node0", "hugepages", "hugepages-2048kB")
+
+	err = os.MkdirAll(nodeHugepages, os.ModePerm)
+	if err != nil {
+		t.Fatalf("failed creating numa node: %s", err)
+	}
+
+	err = os.WriteFile(
+		filepath.Join(nodeHugepages, "free_hugepages"),
+		[]byte(fmt.Sprintf("%d\n", nodeFree)),
+		util.FilePerms,
+	)
+	if err != nil {
+		t.Fatalf("failed writing numa node free hugepages: %s", err)
+	}
+}
+
+func TestCheckHugepages(t *testing.T) {
+	node := func(n int) *int { return &n }
+
+	tests := []struct {
+		desc     string
+		meminfo  string
+		hostFree int
+		nodeFree int
+		m        func(hugetlbfs string) *config.AdvancedMemory
+		wantErr  error
+	}{
+		{
+			desc:     "enough free hugepages on the host",
+			meminfo:  testMeminfo,
+			hostFree: 1024,
+			m: func(hugetlbfs string) *config.AdvancedMemory {
+				return &config.AdvancedMemory{Hugepages: true, HugepagesPath: hugetlbfs}
+			},
+		},
+		{
+			desc:     "not enough free hugepages on the host",
+			meminfo:  testMeminfo,
+			hostFree: 1023,
+			nodeFree: 2048,
+			m: func(hugetlbfs string) *config.AdvancedMemory {
+				return &config.AdvancedMemory{Hugepages: true, HugepagesPath: hugetlbfs}
+			},
+			wantErr: util.ErrValidationError,
+		},
+		{
+			desc:     "enough free hugepages on the numa node",
+			meminfo:  testMeminfo,
+			nodeFree: 1024,
+			m: func(hugetlbfs string) *config.AdvancedMemory {
+				return &config.AdvancedMemory{
+					Hugepages:     true,
+					HugepagesPath: hugetlbfs,
+					NumaNode:      node(0),
+				}
+			},
+		},
+		{
+			desc:     "not enough free hugepages on the numa node",
+			meminfo:  testMeminfo,
+			hostFree: 2048,
+			nodeFree: 512,
+			m: func(hugetlbfs string) *config.AdvancedMemory {
+				return &config.AdvancedMemory{
+					Hugepages:     true,
+					HugepagesPath: hugetlbfs,
+					NumaNode:      node(0),
+				}
+			},
+			wantErr: util.ErrValidationError,
+		},
+		{
+			desc:     "numa node without hugepages",
+			meminfo:  testMeminfo,
+			hostFree: 2048,
+			m: func(hugetlbfs string) *config.AdvancedMemory {
+				return &config.AdvancedMemory{
+					Hugepages:     true,
+					HugepagesPath: hugetlbfs,
+					NumaNode:      node(1),
+				}
+			},
+			wantErr: util.ErrInspectionError,
+		},
+		{
+			desc:     "no hugetlbfs mount",
+			meminfo:  testMeminfo,
+			hostFree: 2048,
+			m: func(hugetlbfs string) *config.AdvancedMemory {
+				return &config.AdvancedMemory{
+					Hugepages:     true,
+					HugepagesPath: filepath.Join(hugetlbfs, "missing"),
+				}
+			},
+			wantErr: util.ErrValidationError,
+		},
+		{
+			desc:     "host without hugepages",
+			meminfo:  "MemTotal:       65782460 kB\nHugePages_Free: %d\n",
+			hostFree: 2048,
+			m: func(hugetlbfs string) *config.AdvancedMemory {
+				return &config.AdvancedMemory{Hugepages: true, HugepagesPath: hugetlbfs}
+			},
+			wantErr: util.ErrValidationError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			testHostMemory(t, tt.meminfo, tt.hostFree, tt.nodeFree)
+
+			// 2048MB of memory is 1024 2MB hugepages
+			q := &Qemu{Hardware: &config.Hardware{Memory: 2048}}
+
+			err := q.checkHugepages(tt.m(t.TempDir()))
+
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("%s: expected error '%s', got: %v", tt.desc, tt.wantErr, err)
+			}
+
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("%s: expected no error, got: %s", tt.desc, err)
+			}
+		})
+	}
+}
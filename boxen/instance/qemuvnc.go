@@ -0,0 +1,34 @@
+package instance
+
+import (
+	"fmt"
+
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+// setVNCPassword sets the vnc password of the running instance over the monitor, the password is
+// never part of the launch command.
+func (i *Qemu) setVNCPassword() error {
+	if !i.Advanced.VNCEnabled() || !i.Advanced.VNC.HasPassword() {
+		return nil
+	}
+
+	p, err := i.Advanced.VNC.ResolvePassword()
+	if err != nil {
+		return err
+	}
+
+	i.Loggers.Base.Debug("setting vnc password")
+
+	out, err := i.monitorCommandRetry(fmt.Sprintf("set_password vnc %s", p), true)
+	if err != nil {
+		return err
+	}
+
+	// set_password is silent unless it fails
+	if out != "" {
+		return fmt.Errorf("%w: failed setting vnc password: %s", util.ErrInstanceError, out)
+	}
+
+	return nil
+}
@@ -3,7 +3,9 @@ package logging
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"sync"
+	"time"
 )
 
 // Message is a simple struct that contains some fields relevant for log messages within boxen.
@@ -38,7 +40,12 @@ func (df *DefaultFormatter) Decode(s string) *Message {
 	parts := df.decodeRe.FindStringSubmatch(s)
 
 	if len(parts) != 4 { //nolint:gomnd
-		panic("cannot decode message")
+		// not an encoded message (i.e. output of some tool sharing the stream), pass it through
+		return &Message{
+			Message:   s,
+			Level:     debug,
+			Timestamp: strconv.FormatInt(time.Now().Unix(), timestampPlaces),
+		}
 	}
 
 	return &Message{
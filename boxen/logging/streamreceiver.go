@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+)
+
+// StreamReceiver is an io.Writer that receives messages emitted (as encoded by the Formatter) to a
+// stream -- i.e. the stdout of a container -- and emits them to a logging Instance.
+type StreamReceiver struct {
+	li   *Instance
+	buf  []byte
+	lock *sync.Mutex
+}
+
+// NewStreamReceiver returns a StreamReceiver that emits received messages to li.
+func NewStreamReceiver(li *Instance) *StreamReceiver {
+	return &StreamReceiver{
+		li:   li,
+		lock: &sync.Mutex{},
+	}
+}
+
+// Write accepts (part of) the stream, every complete line is decoded and queued into the logging
+// Instance.
+func (sr *StreamReceiver) Write(b []byte) (n int, err error) {
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
+
+	sr.buf = append(sr.buf, b...)
+
+	for {
+		idx := bytes.IndexByte(sr.buf, '\n')
+		if idx < 0 {
+			break
+		}
+
+		sr.queue(string(sr.buf[:idx]))
+
+		sr.buf = sr.buf[idx+1:]
+	}
+
+	return len(b), nil
+}
+
+// Flush queues any remaining partial line, call this once the stream is closed.
+func (sr *StreamReceiver) Flush() {
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
+
+	if len(sr.buf) > 0 {
+		sr.queue(string(sr.buf))
+	}
+
+	sr.buf = nil
+}
+
+func (sr *StreamReceiver) queue(s string) {
+	s = strings.TrimRight(s, "\r")
+
+	if strings.TrimSpace(s) == "" {
+		return
+	}
+
+	sr.li.queueMsg(sr.li.Formatter.Decode(s))
+}
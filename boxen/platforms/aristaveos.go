@@ -2,7 +2,6 @@ package platforms
 
 import (
 	"fmt"
-	"math"
 	"path/filepath"
 	"regexp"
 	"time"
@@ -46,38 +45,36 @@ func (p *AristaVeos) Package(
 
 func (p *AristaVeos) patchCmdMgmtNic(c *instance.QemuLaunchCmd) {
 	// vEOS wants the mgmt port to be the first port on the bus, so make that happen...
-	c.MgmtNic[1] += fmt.Sprintf(
-		",bus=pci.1,addr=0x%x",
-		2, //nolint:gomnd
-	)
+	c.MgmtNic.Device.SetPciAddr(1, 2) //nolint:gomnd
 }
 
 func (p *AristaVeos) patchCmdDataNic(c *instance.QemuLaunchCmd) {
-	var nicCmd []string
-
 	for nicID := 1; nicID < p.Hardware.NicCount+1; nicID++ {
-		// need to offset pci bus addr by one to account for mgmt nic
-		busID := int(math.Floor(float64(nicID+1)/float64(p.Hardware.NicPerBus))) + 1
-		busAddr := (nicID + 1%p.Hardware.NicPerBus) + 1
-		paddedNicID := fmt.Sprintf("%03d", nicID)
+		nic := c.Nic(instance.DataNicID(nicID))
+		if nic == nil {
+			continue
+		}
 
-		nicCmd = append(
-			nicCmd,
-			p.BuildDataNic(nicID, busID, busAddr, paddedNicID)...)
+		// need to offset pci bus addr by one to account for mgmt nic
+		nic.Device.SetPciAddr(p.PciAddr(nicID + 1))
 	}
+}
 
-	c.DataNic = nicCmd
+func (p *AristaVeos) patchCmdPci(c *instance.QemuLaunchCmd) {
+	// data nics are offset by one for the mgmt nic, so the last one may need an extra bridge
+	c.Pci = p.BuildPci(p.Hardware.NicCount + 1)
 }
 
 func (p *AristaVeos) patchCmdCdrom(c *instance.QemuLaunchCmd) {
 	diskDir := filepath.Dir(p.Disk)
 	c.Extra = append(
 		c.Extra,
-		[]string{"-cdrom", fmt.Sprintf("%s/%s", diskDir, AristaVeosAbootFileName)}...)
+		instance.NewOpt("cdrom", fmt.Sprintf("%s/%s", diskDir, AristaVeosAbootFileName)))
 }
 
 func (p *AristaVeos) modifyStartCmd(c *instance.QemuLaunchCmd) {
 	p.patchCmdMgmtNic(c)
+	p.patchCmdPci(c)
 	p.patchCmdDataNic(c)
 }
 
@@ -292,10 +289,31 @@ func (p *AristaVeos) SaveConfig() error {
 func (p *AristaVeos) SetUserPass(usr, pwd string) error {
 	p.Loggers.Base.Infof("set user/password for user '%s' requested", usr)
 
+	hashedPwd, err := util.HashPassword(util.PasswordHashSha512Crypt, pwd)
+	if err != nil {
+		return err
+	}
+
 	return p.Config([]string{fmt.Sprintf(
-		"username %s secret 0 %s role network-admin",
+		"username %s secret sha512 %s role network-admin",
 		usr,
-		pwd)})
+		hashedPwd)})
+}
+
+func (p *AristaVeos) SetSSHKeys(usr string, keys []*util.SSHPublicKey) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	p.Loggers.Base.Infof("set ssh keys for user '%s' requested", usr)
+
+	lines := make([]string, len(keys))
+
+	for i, k := range keys {
+		lines[i] = fmt.Sprintf("username %s sshkey %s", usr, k)
+	}
+
+	return p.Config(lines)
 }
 
 func (p *AristaVeos) SetHostname(h string) error {
@@ -0,0 +1,98 @@
+package platforms_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/carlmontanari/boxen/boxen/instance"
+	"github.com/carlmontanari/boxen/boxen/logging"
+	"github.com/carlmontanari/boxen/boxen/platforms"
+)
+
+func TestAristaVeosPciOffset(t *testing.T) {
+	tests := []struct {
+		desc        string
+		nicCount    int
+		wantLastNic string
+		wantBridges int
+	}{
+		{
+			desc:        "24 nics fill the first bridge after the mgmt nic",
+			nicCount:    24,
+			wantLastNic: "bus=pci.1,addr=0x1a",
+			wantBridges: 1,
+		},
+		{
+			desc:        "25 nics need a second bridge",
+			nicCount:    25,
+			wantLastNic: "bus=pci.2,addr=0x1",
+			wantBridges: 2,
+		},
+		{
+			desc:        "26 nics",
+			nicCount:    26,
+			wantLastNic: "bus=pci.2,addr=0x2",
+			wantBridges: 2,
+		},
+	}
+
+	t.Setenv("BOXEN_SCRAPLI_PLATFORM_DEFINITION", "cisco_iosxe")
+
+	l, err := logging.NewInstance(func(...interface{}) {})
+	if err != nil {
+		t.Fatalf("failed creating logger: %s", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c := goldenConfig(t, platforms.PlatformTypeAristaVeos)
+			c.Instances[platforms.PlatformTypeAristaVeos].Hardware.NicCount = tt.nicCount
+
+			p, err := platforms.NewPlatformFromConfig(
+				platforms.PlatformTypeAristaVeos,
+				c,
+				&instance.Loggers{Base: l},
+			)
+			if err != nil {
+				t.Fatalf("%s: failed creating platform: %s", tt.desc, err)
+			}
+
+			e, err := platforms.DryRun(p, false)
+			if err != nil {
+				t.Fatalf("%s: dry run failed: %s", tt.desc, err)
+			}
+
+			var bridges int
+
+			devices := map[string]string{}
+
+			for _, a := range e.Args {
+				if strings.HasPrefix(a.Arg, "pci-bridge,") {
+					bridges++
+				}
+
+				for _, id := range []string{"mgmt", "p001", instance.DataNicID(tt.nicCount)} {
+					if strings.Contains(a.Arg, fmt.Sprintf(",id=nic-%s,", id)) {
+						devices[id] = a.Arg
+					}
+				}
+			}
+
+			if bridges != tt.wantBridges {
+				t.Fatalf("%s: got %d pci bridges, want %d", tt.desc, bridges, tt.wantBridges)
+			}
+
+			// the mgmt nic takes the first slot, so data plane nic n is in slot n+1
+			for id, want := range map[string]string{
+				"mgmt":                          "bus=pci.1,addr=0x2",
+				"p001":                          "bus=pci.1,addr=0x3",
+				instance.DataNicID(tt.nicCount): tt.wantLastNic,
+			} {
+				if !strings.Contains(devices[id], want) {
+					t.Fatalf("%s: nic '%s' is '%s', want '%s'", tt.desc, id, devices[id], want)
+				}
+			}
+		})
+	}
+}
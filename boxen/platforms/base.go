@@ -1,6 +1,9 @@
 package platforms
 
-import "github.com/carlmontanari/boxen/boxen/instance"
+import (
+	"github.com/carlmontanari/boxen/boxen/instance"
+	"github.com/carlmontanari/boxen/boxen/util"
+)
 
 type Platform interface {
 	// Base embeds Install, Start, Stop and RunUntilSigInt methods that should be common for any
@@ -41,6 +44,9 @@ type Platform interface {
 
 	// SetUserPass sets the username/password -- used for "package start" mode.
 	SetUserPass(usr, pwd string) error
+	// SetSSHKeys adds the ssh public keys to the authorized keys of the user -- used for "package
+	// start" mode. Platforms that do not support ssh keys log and ignore them.
+	SetSSHKeys(usr string, keys []*util.SSHPublicKey) error
 	// SetHostname sets the hostname -- used for "package start" mode.
 	SetHostname(h string) error
 
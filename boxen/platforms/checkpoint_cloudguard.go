@@ -7,6 +7,7 @@ import (
 	sopoptions "github.com/scrapli/scrapligo/driver/opoptions"
 
 	"github.com/carlmontanari/boxen/boxen/instance"
+	"github.com/carlmontanari/boxen/boxen/util"
 )
 
 const (
@@ -213,6 +214,17 @@ func (p *CheckpointCloudguard) SetUserPass(usr, pwd string) error {
 	})
 }
 
+// SetSSHKeys is not supported on CheckpointCloudguard -- providing any keys is an error.
+func (p *CheckpointCloudguard) SetSSHKeys(usr string, keys []*util.SSHPublicKey) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	p.Loggers.Base.Infof("set ssh keys for user '%s' requested", usr)
+
+	return ValidateSSHKeys(PlatformTypeCheckpointCloudguard, len(keys))
+}
+
 func (p *CheckpointCloudguard) SetHostname(h string) error {
 	p.Loggers.Base.Infof("set hostname '%s' requested", h)
 
@@ -18,6 +18,10 @@ const (
 	CiscoCsr1000vScrapliPlatform  = "cisco_iosxe"
 	CiscoCsr1000vDefaultUser      = "admin"
 	CiscoCsr1000vDefaultPass      = "admin"
+
+	// csr1000vKeyStringChunkSize is the max line length used when sending ssh keys via
+	// "key-string", ios-xe doesn't like overly long lines there.
+	csr1000vKeyStringChunkSize = 64
 )
 
 type CiscoCsr1000v struct {
@@ -102,7 +106,7 @@ func (p *CiscoCsr1000v) patchCmdCdrom(c *instance.QemuLaunchCmd) {
 	diskDir := filepath.Dir(p.Disk)
 	c.Extra = append(
 		c.Extra,
-		[]string{"-cdrom", fmt.Sprintf("%s/%s", diskDir, CiscoCsr1000vInstallCdromName)}...)
+		instance.NewOpt("cdrom", fmt.Sprintf("%s/%s", diskDir, CiscoCsr1000vInstallCdromName)))
 }
 
 func (p *CiscoCsr1000v) modifyStartCmd(c *instance.QemuLaunchCmd) {
@@ -281,10 +285,35 @@ func (p *CiscoCsr1000v) SaveConfig() error {
 func (p *CiscoCsr1000v) SetUserPass(usr, pwd string) error {
 	p.Loggers.Base.Infof("set user/password for user '%s' requested", usr)
 
+	hashedPwd, err := util.HashPassword(util.PasswordHashCiscoType9, pwd)
+	if err != nil {
+		return err
+	}
+
 	return p.Config([]string{fmt.Sprintf(
-		"username %s privilege 15 password %s",
+		"username %s privilege 15 secret 9 %s",
 		usr,
-		pwd)})
+		hashedPwd)})
+}
+
+func (p *CiscoCsr1000v) SetSSHKeys(usr string, keys []*util.SSHPublicKey) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	p.Loggers.Base.Infof("set ssh keys for user '%s' requested", usr)
+
+	lines := []string{"ip ssh pubkey-chain", fmt.Sprintf("username %s", usr)}
+
+	for _, k := range keys {
+		lines = append(lines, "key-string")
+		lines = append(lines, k.KeyChunks(csr1000vKeyStringChunkSize)...)
+		lines = append(lines, "exit")
+	}
+
+	lines = append(lines, "exit", "exit")
+
+	return p.Config(lines)
 }
 
 func (p *CiscoCsr1000v) SetHostname(h string) error {
@@ -49,36 +49,58 @@ func (p *CiscoN9kv) Package(
 }
 
 func (p *CiscoN9kv) patchCmdDisk(c *instance.QemuLaunchCmd) {
-	c.Disk = []string{
-		"-drive",
-		fmt.Sprintf("if=none,file=%s,format=qcow2,id=drive-sata-disk0", p.Disk),
-		"-device",
-		"ahci,id=ahci0,bus=pci.0",
-		"-device",
-		"ide-hd,drive=drive-sata-disk0,bus=ahci0.0,id=drive-sata-disk0,bootindex=1",
+	// n9kv wants a sata disk, unless disks are explicitly configured
+	if len(p.Hardware.Disks) > 0 {
+		return
+	}
+
+	c.DiskController = []*instance.Device{
+		{
+			Driver: "ahci",
+			ID:     "ahci0",
+			Props:  instance.Props{{Key: "bus", Value: "pci.0"}},
+		},
+	}
+
+	c.Disk = []*instance.Disk{
+		{
+			Drive: &instance.Drive{
+				ID: "drive-sata-disk0",
+				Props: instance.Props{
+					{Key: "if", Value: "none"},
+					{Key: "file", Value: p.Disk},
+					{Key: "format", Value: "qcow2"},
+				},
+			},
+			Device: &instance.Device{
+				Driver: "ide-hd",
+				ID:     "drive-sata-disk0",
+				Props: instance.Props{
+					{Key: "drive", Value: "drive-sata-disk0"},
+					{Key: "bus", Value: "ahci0.0"},
+					{Key: "bootindex", Value: "1"},
+				},
+			},
+		},
 	}
 }
 
 func (p *CiscoN9kv) patchCmdExtraBios(c *instance.QemuLaunchCmd) {
+	// uefi instances boot the configured OVMF firmware rather than the bundled bios
+	if p.Advanced != nil && p.Advanced.Firmware.IsUEFI() {
+		return
+	}
+
 	diskDir := filepath.Dir(p.Disk)
 
 	c.Extra = append(
 		c.Extra,
-		[]string{
-			"-bios",
-			fmt.Sprintf("%s/%s", diskDir, CiscoN9kvBiosName),
-		}...,
+		instance.NewOpt("bios", fmt.Sprintf("%s/%s", diskDir, CiscoN9kvBiosName)),
 	)
 }
 
 func (p *CiscoN9kv) patchCmdExtraBoot(c *instance.QemuLaunchCmd) {
-	c.Extra = append(
-		c.Extra,
-		[]string{
-			"-boot",
-			"c",
-		}...,
-	)
+	c.Extra = append(c.Extra, instance.NewOpt("boot", "c"))
 }
 
 func (p *CiscoN9kv) modifyStartCmd(c *instance.QemuLaunchCmd) {
@@ -394,10 +416,31 @@ func (p *CiscoN9kv) SaveConfig() error {
 func (p *CiscoN9kv) SetUserPass(usr, pwd string) error {
 	p.Loggers.Base.Infof("set user/password for user '%s' requested", usr)
 
+	hashedPwd, err := util.HashPassword(util.PasswordHashSha256Crypt, pwd)
+	if err != nil {
+		return err
+	}
+
 	return p.Config([]string{fmt.Sprintf(
-		"username %s password 0 %s role network-admin",
+		"username %s password 5 %s role network-admin",
 		usr,
-		pwd)})
+		hashedPwd)})
+}
+
+func (p *CiscoN9kv) SetSSHKeys(usr string, keys []*util.SSHPublicKey) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	p.Loggers.Base.Infof("set ssh keys for user '%s' requested", usr)
+
+	lines := make([]string, len(keys))
+
+	for i, k := range keys {
+		lines[i] = fmt.Sprintf("username %s sshkey %s", usr, k)
+	}
+
+	return p.Config(lines)
 }
 
 func (p *CiscoN9kv) SetHostname(h string) error {
@@ -9,6 +9,7 @@ import (
 	sopoptions "github.com/scrapli/scrapligo/driver/opoptions"
 
 	"github.com/carlmontanari/boxen/boxen/instance"
+	"github.com/carlmontanari/boxen/boxen/util"
 )
 
 const (
@@ -32,21 +33,22 @@ func (p *CiscoXrv9k) Package(
 }
 
 func (p *CiscoXrv9k) patchCmdMgmtNic(c *instance.QemuLaunchCmd) {
-	ctrlNic := []string{
-		"-device",
-		fmt.Sprintf("%s,netdev=ctrl", NicVirtio),
-		"-netdev",
-		"tap,id=ctrl,script=no,downscript=no",
-	}
-	devNic := []string{
-		"-device",
-		fmt.Sprintf("%s,netdev=dev", NicVirtio),
-		"-netdev",
-		"tap,id=dev,script=no,downscript=no",
+	for _, id := range []string{"ctrl", "dev"} {
+		c.AuxNic = append(
+			c.AuxNic,
+			instance.NewNic(
+				NicVirtio,
+				&instance.Netdev{
+					Type: "tap",
+					ID:   id,
+					Props: instance.Props{
+						{Key: "script", Value: "no"},
+						{Key: "downscript", Value: "no"},
+					},
+				},
+			),
+		)
 	}
-
-	c.MgmtNic = append(c.MgmtNic, ctrlNic...)
-	c.MgmtNic = append(c.MgmtNic, devNic...)
 }
 
 func (p *CiscoXrv9k) modifyStartCmd(c *instance.QemuLaunchCmd) {
@@ -346,6 +348,17 @@ func (p *CiscoXrv9k) SetUserPass(usr, pwd string) error {
 	)
 }
 
+// SetSSHKeys is not supported on CiscoXrv9k -- providing any keys is an error.
+func (p *CiscoXrv9k) SetSSHKeys(usr string, keys []*util.SSHPublicKey) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	p.Loggers.Base.Infof("set ssh keys for user '%s' requested", usr)
+
+	return ValidateSSHKeys(PlatformTypeCiscoXrv9k, len(keys))
+}
+
 func (p *CiscoXrv9k) SetHostname(h string) error {
 	p.Loggers.Base.Infof("set hostname '%s' requested", h)
 
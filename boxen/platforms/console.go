@@ -10,6 +10,9 @@ import (
 
 	"github.com/scrapli/scrapligo/platform"
 
+	"github.com/carlmontanari/boxen/boxen"
+	"github.com/carlmontanari/boxen/boxen/config"
+	"github.com/carlmontanari/boxen/boxen/console"
 	"github.com/carlmontanari/boxen/boxen/instance"
 	"github.com/carlmontanari/boxen/boxen/logging"
 	"github.com/carlmontanari/boxen/boxen/util"
@@ -35,20 +38,20 @@ type ScrapliConsole struct {
 	c         *network.Driver
 	defOnOpen func(d *network.Driver) error
 	logger    *logging.Instance
+	creds     func() (*config.Credentials, error)
 }
 
+// NewScrapliConsole returns a console for the scrapligo platform on the serial port port. The
+// credentials are only fetched from creds when the console is opened, see Qemu.ResolveCredentials.
 func NewScrapliConsole(
 	scrapliPlatform string,
 	port int,
-	usr, pwd string,
+	creds func() (*config.Credentials, error),
 	l *instance.Loggers,
 	options ...sutil.Option,
 ) (*ScrapliConsole, error) {
 	opts := []sutil.Option{
 		soptions.WithPort(port),
-		soptions.WithAuthUsername(usr),
-		soptions.WithAuthPassword(pwd),
-		soptions.WithAuthSecondary(pwd),
 		soptions.WithAuthBypass(),
 		soptions.WithReturnChar(defaultCommsReturnChar),
 		soptions.WithTransportType("telnet"),
@@ -67,7 +70,7 @@ func NewScrapliConsole(
 	var p *platform.Platform
 
 	p, err = platform.NewPlatform(
-		scrapliPlatform,
+		scrapliPlatformDefinition(scrapliPlatform),
 		"localhost",
 		opts...,
 	)
@@ -86,6 +89,7 @@ func NewScrapliConsole(
 		c:         c,
 		defOnOpen: c.OnOpen,
 		logger:    l.Base,
+		creds:     creds,
 	}
 
 	c.OnOpen = func(d *network.Driver) error { return nil }
@@ -93,6 +97,19 @@ func NewScrapliConsole(
 	return con, nil
 }
 
+// scrapliPlatformDefinition returns the bytes of a scrapligo platform definition embedded in the
+// boxen assets if one exists for the provided platform name, this allows for supporting platforms
+// that are not (yet?) a part of scrapligo itself. If there is no embedded definition the name is
+// returned as is and scrapligo sorts out if it is one of its own platforms, a file, or a url.
+func scrapliPlatformDefinition(scrapliPlatform string) interface{} {
+	b, err := boxen.Assets.ReadFile(fmt.Sprintf("assets/scrapli/%s.yaml", scrapliPlatform))
+	if err != nil {
+		return scrapliPlatform
+	}
+
+	return b
+}
+
 func (c *ScrapliConsole) Config(lines []string) error {
 	_, err := c.c.SendConfigs(lines)
 	return err
@@ -104,14 +121,45 @@ func (c *ScrapliConsole) Detach() error {
 	return nil
 }
 
+// setAuth resolves the credentials and sets them on the scrapligo driver.
+func (c *ScrapliConsole) setAuth() error {
+	if c.creds == nil {
+		return nil
+	}
+
+	creds, err := c.creds()
+	if err != nil {
+		c.logger.Criticalf("error resolving credentials: %s\n", err)
+
+		return err
+	}
+
+	if creds == nil {
+		return nil
+	}
+
+	c.c.Transport.Args.User = creds.Username
+	c.c.Transport.Args.Password = creds.Password
+	c.c.AuthSecondary = creds.Password
+
+	return nil
+}
+
 func (c *ScrapliConsole) openRetry() error {
+	err := c.setAuth()
+	if err != nil {
+		return err
+	}
+
 	ch := make(chan error)
 
 	go func() {
 		for {
 			err := c.c.Open()
 			if err == nil {
-				ch <- nil
+				// identify as boxen automation to the console multiplexer, so console input of
+				// other attached clients can't interfere
+				ch <- c.c.Transport.Write(console.AutomationHello())
 
 				return
 			}
@@ -355,3 +403,55 @@ func (c *ScrapliConsole) InstallConfig(f string, replace bool) error {
 
 	return nil
 }
+
+// mergeConfigFromFile sends all lines from the config file `f` to the device as config lines. This
+// is for platforms that scrapligocfg does not support, so there is no "replace" here -- the lines
+// are simply merged into whatever config is already on the device.
+func (c *ScrapliConsole) mergeConfigFromFile(f string) error {
+	c.logger.Info("merge config from file requested")
+
+	resolvedF, err := util.ResolveFile(f)
+	if err != nil {
+		c.logger.Criticalf(
+			"failed resolving provided config file '%s', error: %s",
+			f,
+			err,
+		)
+
+		return err
+	}
+
+	b, err := os.ReadFile(resolvedF)
+	if err != nil {
+		c.logger.Criticalf(
+			"failed failed loading config from file '%s', error: %s",
+			resolvedF,
+			err,
+		)
+
+		return err
+	}
+
+	var lines []string
+
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimRight(line, "\r")
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	err = c.Config(lines)
+	if err != nil {
+		c.logger.Criticalf("failed sending config lines: %s", err)
+
+		return err
+	}
+
+	c.logger.Info("merge config from file complete")
+
+	return nil
+}
@@ -7,6 +7,9 @@ const (
 	VendorPaloAlto   = "paloalto"
 	VendorIPInfusion = "ipinfusion"
 	VendorCheckpoint = "checkpoint"
+	VendorFortinet   = "fortinet"
+	VendorMikrotik   = "mikrotik"
+	VendorLinux      = "linux"
 
 	PlatformAristaVeos           = "veos"
 	PlatformCiscoCsr1000v        = "csr1000v"
@@ -16,6 +19,9 @@ const (
 	PlatformPaloAltoPanos        = "panos"
 	PlatformIPInfusionOcNOS      = "ocnos"
 	PlatformCheckpointCloudguard = "cloudguard"
+	PlatformFortinetFortigate    = "fortigate"
+	PlatformMikrotikChr          = "chr"
+	PlatformLinuxCloud           = "cloud"
 
 	PlatformTypeAristaVeos           = "arista_veos"
 	PlatformTypeCiscoCsr1000v        = "cisco_csr1000v"
@@ -25,6 +31,9 @@ const (
 	PlatformTypePaloAltoPanos        = "paloalto_panos"
 	PlatformTypeIPInfusionOcNOS      = "ipinfusion_ocnos"
 	PlatformTypeCheckpointCloudguard = "checkpoint_cloudguard"
+	PlatformTypeFortinetFortigate    = "fortinet_fortigate"
+	PlatformTypeMikrotikChr          = "mikrotik_chr"
+	PlatformTypeLinux                = "linux"
 
 	NicE1000  = "e1000"
 	NicVirtio = "virtio-net-pci"
@@ -0,0 +1,41 @@
+package platforms
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/carlmontanari/boxen/boxen/instance"
+)
+
+// launchCmdModifier is implemented by platforms that patch the qemu launch command.
+type launchCmdModifier interface {
+	modifyStartCmd(c *instance.QemuLaunchCmd)
+	modifyInstallCmd(c *instance.QemuLaunchCmd)
+}
+
+// DryRun returns the launch command the platform instance p would be started with (or installed
+// with if install is true) including the platform launch command patches, without launching it.
+func DryRun(p Platform, install bool) (*instance.LaunchCmdExplain, error) {
+	var opts []instance.Option
+
+	if m, ok := p.(launchCmdModifier); ok {
+		// the method values are bound via the interface, so name the hook after the platform
+		platform := strings.TrimPrefix(fmt.Sprintf("%T", p), "*platforms.")
+
+		if install {
+			opts = append(
+				opts,
+				instance.WithLaunchModifier(m.modifyInstallCmd),
+				instance.WithLaunchModifierName(fmt.Sprintf("(*%s).modifyInstallCmd", platform)),
+			)
+		} else {
+			opts = append(
+				opts,
+				instance.WithLaunchModifier(m.modifyStartCmd),
+				instance.WithLaunchModifierName(fmt.Sprintf("(*%s).modifyStartCmd", platform)),
+			)
+		}
+	}
+
+	return p.DryRun(opts...)
+}
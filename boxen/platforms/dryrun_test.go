@@ -0,0 +1,159 @@
+package platforms_test
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/carlmontanari/boxen/boxen/boxen"
+	"github.com/carlmontanari/boxen/boxen/config"
+	"github.com/carlmontanari/boxen/boxen/instance"
+	"github.com/carlmontanari/boxen/boxen/logging"
+	"github.com/carlmontanari/boxen/boxen/platforms"
+	"github.com/google/go-cmp/cmp"
+)
+
+var update = flag.Bool("update", false, "update the golden files") //nolint:gochecknoglobals
+
+var (
+	// uuidPattern matches the (random) instance uuid of the launch command.
+	uuidPattern = regexp.MustCompile( //nolint:gochecknoglobals
+		`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`,
+	)
+	// macPattern matches the generated mac addresses, only the last octet is not random.
+	macPattern = regexp.MustCompile(`52:54:00:[0-9a-f]{2}:[0-9a-f]{2}:`) //nolint:gochecknoglobals
+)
+
+// normalizeLaunchCmd replaces the random parts of the launch command, fixed mac addresses (i.e. the
+// linux management nic) are kept as is.
+func normalizeLaunchCmd(s string) string {
+	s = uuidPattern.ReplaceAllString(s, "UUID")
+
+	return macPattern.ReplaceAllStringFunc(s, func(m string) string {
+		if strings.HasPrefix(platforms.LinuxMgmtMac, m) {
+			return m
+		}
+
+		return "52:54:00:xx:xx:"
+	})
+}
+
+// goldenConfig returns a config with a single instance of the platform type pT, set up with the
+// default profile of the platform type like a freshly provisioned instance.
+func goldenConfig(t *testing.T, pT string) *config.Config {
+	t.Helper()
+
+	profile, err := boxen.GetDefaultProfile(pT)
+	if err != nil {
+		t.Fatalf("failed loading default profile of '%s': %s", pT, err)
+	}
+
+	c := config.NewConfig()
+
+	// the binary doesn't exist, so the launch command isn't validated against whatever qemu the
+	// host running the tests has
+	c.Options.Qemu = &config.Qemu{Binary: "qemu-golden", Acceleration: []string{"kvm"}}
+
+	c.Platforms[pT] = config.NewPlatform()
+	c.Platforms[pT].Profiles["default"] = profile
+
+	tcpNats, udpNats := boxen.ZipPlatformProfileNats(profile.TPCNatPorts, profile.UDPNatPorts)
+
+	hardware := profile.Hardware.ToHardware()
+	hardware.MonitorPort = 4001
+	hardware.SerialPorts = []int{5001}
+
+	if profile.Hardware.SerialPortCount > 1 {
+		hardware.SerialPorts = append(hardware.SerialPorts, 5002)
+	}
+
+	c.Instances[pT] = &config.Instance{
+		Name:         pT,
+		PlatformType: pT,
+		Disk:         fmt.Sprintf("/boxen/instances/%s/disk.qcow2", pT),
+		ID:           1,
+		Profile:      "default",
+		Credentials:  config.NewDefaultCredentials(),
+		Hardware:     hardware,
+		MgmtIntf: &config.MgmtIntf{
+			Nat: &config.Nat{TCP: tcpNats, UDP: udpNats},
+		},
+		Advanced: profile.Advanced,
+	}
+
+	return c
+}
+
+func TestDryRunGolden(t *testing.T) {
+	platformTypes := []string{
+		platforms.PlatformTypeAristaVeos,
+		platforms.PlatformTypeCiscoCsr1000v,
+		platforms.PlatformTypeCiscoXrv9k,
+		platforms.PlatformTypeCiscoN9kv,
+		platforms.PlatformTypeJuniperVsrx,
+		platforms.PlatformTypePaloAltoPanos,
+		platforms.PlatformTypeIPInfusionOcNOS,
+		platforms.PlatformTypeCheckpointCloudguard,
+		platforms.PlatformTypeFortinetFortigate,
+		platforms.PlatformTypeMikrotikChr,
+		platforms.PlatformTypeLinux,
+	}
+
+	// some platforms fetch their scrapli platform definition, a builtin one keeps this offline -- the
+	// console isn't used for the launch command anyway
+	t.Setenv("BOXEN_SCRAPLI_PLATFORM_DEFINITION", "cisco_iosxe")
+
+	l, err := logging.NewInstance(func(...interface{}) {})
+	if err != nil {
+		t.Fatalf("failed creating logger: %s", err)
+	}
+
+	for _, pT := range platformTypes {
+		for _, install := range []bool{false, true} {
+			desc := fmt.Sprintf("%s_start", pT)
+			if install {
+				desc = fmt.Sprintf("%s_install", pT)
+			}
+
+			t.Run(desc, func(t *testing.T) {
+				p, err := platforms.NewPlatformFromConfig(
+					pT,
+					goldenConfig(t, pT),
+					&instance.Loggers{Base: l},
+				)
+				if err != nil {
+					t.Fatalf("%s: failed creating platform: %s", desc, err)
+				}
+
+				e, err := platforms.DryRun(p, install)
+				if err != nil {
+					t.Fatalf("%s: dry run failed: %s", desc, err)
+				}
+
+				got := normalizeLaunchCmd(e.Explain()) + "\n"
+
+				golden := filepath.Join("testdata", desc+".golden")
+
+				if *update {
+					err = os.WriteFile(golden, []byte(got), 0o644) //nolint:gosec,gomnd
+					if err != nil {
+						t.Fatalf("%s: failed updating golden file: %s", desc, err)
+					}
+				}
+
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatalf("%s: failed reading golden file: %s", desc, err)
+				}
+
+				if diff := cmp.Diff(string(want), got); diff != "" {
+					t.Fatalf("%s: launch command does not match golden file (-want +got):\n%s", desc, diff)
+				}
+			})
+		}
+	}
+}
@@ -3,6 +3,7 @@ package platforms
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	soptions "github.com/scrapli/scrapligo/driver/options"
 
@@ -42,11 +43,38 @@ func GetPlatformType(v, p string) string {
 		if p == PlatformCheckpointCloudguard {
 			return PlatformTypeCheckpointCloudguard
 		}
+	case VendorFortinet:
+		if p == PlatformFortinetFortigate {
+			return PlatformTypeFortinetFortigate
+		}
+	case VendorMikrotik:
+		if p == PlatformMikrotikChr {
+			return PlatformTypeMikrotikChr
+		}
+	case VendorLinux:
+		if p == PlatformLinuxCloud {
+			return PlatformTypeLinux
+		}
 	}
 
 	return ""
 }
 
+// GetVendorPlatform returns the vendor and platform of the platform type pT, both are empty if the
+// platform type is not supported.
+func GetVendorPlatform(pT string) (vendor, platform string) {
+	if pT == PlatformTypeLinux {
+		return VendorLinux, PlatformLinuxCloud
+	}
+
+	parts := strings.SplitN(pT, "_", 2) //nolint:gomnd
+	if len(parts) != 2 || GetPlatformType(parts[0], parts[1]) != pT {
+		return "", ""
+	}
+
+	return parts[0], parts[1]
+}
+
 func GetPlatformEmptyStruct(pT string) (Platform, error) {
 	switch pT {
 	case PlatformTypeAristaVeos:
@@ -65,6 +93,12 @@ func GetPlatformEmptyStruct(pT string) (Platform, error) {
 		return &IPInfusionOcNOS{}, nil
 	case PlatformTypeCheckpointCloudguard:
 		return &CheckpointCloudguard{}, nil
+	case PlatformTypeFortinetFortigate:
+		return &FortinetFortigate{}, nil
+	case PlatformTypeMikrotikChr:
+		return &MikrotikChr{}, nil
+	case PlatformTypeLinux:
+		return &Linux{}, nil
 	}
 
 	return nil, fmt.Errorf(
@@ -73,6 +107,47 @@ func GetPlatformEmptyStruct(pT string) (Platform, error) {
 	)
 }
 
+// GetPlatformMaxSSHKeys returns the number of ssh keys the platform type can authorize for a user,
+// 0 if the platform does not support ssh key injection at all and -1 if there is no limit.
+func GetPlatformMaxSSHKeys(pT string) int {
+	switch pT {
+	case PlatformTypeCiscoXrv9k, PlatformTypeIPInfusionOcNOS, PlatformTypeCheckpointCloudguard:
+		return 0
+	case PlatformTypePaloAltoPanos:
+		return paloAltoPanosMaxSSHKeys
+	case PlatformTypeFortinetFortigate:
+		return fortinetFortigateMaxSSHKeys
+	}
+
+	return -1
+}
+
+// ValidateSSHKeys returns a validation error if the platform type can't authorize count ssh keys
+// for a user -- keys are never silently dropped, so this is checked before any install or
+// packaging work is done.
+func ValidateSSHKeys(pT string, count int) error {
+	maxKeys := GetPlatformMaxSSHKeys(pT)
+
+	switch {
+	case count == 0 || maxKeys < 0 || count <= maxKeys:
+		return nil
+	case maxKeys == 0:
+		return fmt.Errorf(
+			"%w: ssh keys requested, platform '%s' does not support ssh key injection",
+			util.ErrValidationError,
+			pT,
+		)
+	}
+
+	return fmt.Errorf(
+		"%w: %d ssh keys requested, platform '%s' supports at most %d ssh key(s) per user",
+		util.ErrValidationError,
+		count,
+		pT,
+		maxKeys,
+	)
+}
+
 // GetPlatformScrapliDefinition sets the scrapli platform definition to a value
 // of the BOXEN_SCRAPLI_PLATFORM_DEFINITION env var or to a default string value.
 func GetPlatformScrapliDefinition(p string) string {
@@ -100,6 +175,12 @@ func GetPlatformScrapliDefinition(p string) string {
 		return IPInfusionOcNOSScrapliPlatform
 	case PlatformTypeCheckpointCloudguard:
 		return CheckpointCloudguardScrapliPlatform
+	case PlatformTypeFortinetFortigate:
+		return FortinetFortigateScrapliPlatform
+	case PlatformTypeMikrotikChr:
+		return MikrotikChrScrapliPlatform
+	case PlatformTypeLinux:
+		return LinuxScrapliPlatform
 	}
 
 	return ""
@@ -129,8 +210,7 @@ func NewPlatformFromConfig( //nolint:funlen
 		con, err = NewScrapliConsole(
 			scrapliPlatform,
 			q.Hardware.SerialPorts[0],
-			q.Credentials.Username,
-			q.Credentials.Password,
+			q.ResolveCredentials,
 			l,
 		)
 
@@ -142,8 +222,7 @@ func NewPlatformFromConfig( //nolint:funlen
 		con, err = NewScrapliConsole(
 			scrapliPlatform,
 			q.Hardware.SerialPorts[0],
-			q.Credentials.Username,
-			q.Credentials.Password,
+			q.ResolveCredentials,
 			l,
 		)
 
@@ -155,8 +234,7 @@ func NewPlatformFromConfig( //nolint:funlen
 		con, err = NewScrapliConsole(
 			scrapliPlatform,
 			q.Hardware.SerialPorts[0],
-			q.Credentials.Username,
-			q.Credentials.Password,
+			q.ResolveCredentials,
 			l,
 			soptions.WithReturnChar("\r"),
 		)
@@ -169,8 +247,7 @@ func NewPlatformFromConfig( //nolint:funlen
 		con, err = NewScrapliConsole(
 			scrapliPlatform,
 			q.Hardware.SerialPorts[0],
-			q.Credentials.Username,
-			q.Credentials.Password,
+			q.ResolveCredentials,
 			l,
 			soptions.WithReturnChar("\r"),
 		)
@@ -183,8 +260,7 @@ func NewPlatformFromConfig( //nolint:funlen
 		con, err = NewScrapliConsole(
 			scrapliPlatform,
 			q.Hardware.SerialPorts[0],
-			q.Credentials.Username,
-			q.Credentials.Password,
+			q.ResolveCredentials,
 			l,
 		)
 
@@ -196,8 +272,7 @@ func NewPlatformFromConfig( //nolint:funlen
 		con, err = NewScrapliConsole(
 			scrapliPlatform,
 			q.Hardware.SerialPorts[0],
-			q.Credentials.Username,
-			q.Credentials.Password,
+			q.ResolveCredentials,
 			l,
 			soptions.WithReturnChar("\r"),
 		)
@@ -210,8 +285,7 @@ func NewPlatformFromConfig( //nolint:funlen
 		con, err = NewScrapliConsole(
 			scrapliPlatform,
 			q.Hardware.SerialPorts[0],
-			q.Credentials.Username,
-			q.Credentials.Password,
+			q.ResolveCredentials,
 			l,
 			soptions.WithReturnChar("\r"),
 		)
@@ -224,8 +298,7 @@ func NewPlatformFromConfig( //nolint:funlen
 		con, err = NewScrapliConsole(
 			scrapliPlatform,
 			q.Hardware.SerialPorts[0],
-			q.Credentials.Username,
-			q.Credentials.Password,
+			q.ResolveCredentials,
 			l,
 			soptions.WithReturnChar("\r"),
 		)
@@ -234,6 +307,42 @@ func NewPlatformFromConfig( //nolint:funlen
 			Qemu:           q,
 			ScrapliConsole: con,
 		}
+	case PlatformTypeFortinetFortigate:
+		con, err = NewScrapliConsole(
+			scrapliPlatform,
+			q.Hardware.SerialPorts[0],
+			q.ResolveCredentials,
+			l,
+		)
+
+		p = &FortinetFortigate{
+			Qemu:           q,
+			ScrapliConsole: con,
+		}
+	case PlatformTypeMikrotikChr:
+		con, err = NewScrapliConsole(
+			scrapliPlatform,
+			q.Hardware.SerialPorts[0],
+			q.ResolveCredentials,
+			l,
+		)
+
+		p = &MikrotikChr{
+			Qemu:           q,
+			ScrapliConsole: con,
+		}
+	case PlatformTypeLinux:
+		con, err = NewScrapliConsole(
+			scrapliPlatform,
+			q.Hardware.SerialPorts[0],
+			q.ResolveCredentials,
+			l,
+		)
+
+		p = &Linux{
+			Qemu:           q,
+			ScrapliConsole: con,
+		}
 	default:
 		return nil, fmt.Errorf("%w: scrapligo driver is not found for %q platform",
 			util.ErrAllocationError, pT)
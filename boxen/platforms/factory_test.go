@@ -0,0 +1,83 @@
+package platforms_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/carlmontanari/boxen/boxen/platforms"
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+func TestValidateSSHKeys(t *testing.T) {
+	tests := []struct {
+		desc    string
+		pT      string
+		count   int
+		wantErr bool
+	}{
+		{
+			desc:  "no keys on a platform without key support",
+			pT:    platforms.PlatformTypeCiscoXrv9k,
+			count: 0,
+		},
+		{
+			desc:    "keys on a platform without key support",
+			pT:      platforms.PlatformTypeCiscoXrv9k,
+			count:   1,
+			wantErr: true,
+		},
+		{
+			desc:    "keys on ocnos",
+			pT:      platforms.PlatformTypeIPInfusionOcNOS,
+			count:   1,
+			wantErr: true,
+		},
+		{
+			desc:    "keys on checkpoint",
+			pT:      platforms.PlatformTypeCheckpointCloudguard,
+			count:   2,
+			wantErr: true,
+		},
+		{
+			desc:  "single key on panos",
+			pT:    platforms.PlatformTypePaloAltoPanos,
+			count: 1,
+		},
+		{
+			desc:    "more than one key on panos",
+			pT:      platforms.PlatformTypePaloAltoPanos,
+			count:   2,
+			wantErr: true,
+		},
+		{
+			desc:  "three keys on fortigate",
+			pT:    platforms.PlatformTypeFortinetFortigate,
+			count: 3,
+		},
+		{
+			desc:    "more than three keys on fortigate",
+			pT:      platforms.PlatformTypeFortinetFortigate,
+			count:   4,
+			wantErr: true,
+		},
+		{
+			desc:  "no limit",
+			pT:    platforms.PlatformTypeAristaVeos,
+			count: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := platforms.ValidateSSHKeys(tt.pT, tt.count)
+
+			if tt.wantErr && !errors.Is(err, util.ErrValidationError) {
+				t.Fatalf("%s: expected validation error, got: %v", tt.desc, err)
+			}
+
+			if !tt.wantErr && err != nil {
+				t.Fatalf("%s: expected no error, got: %s", tt.desc, err)
+			}
+		})
+	}
+}
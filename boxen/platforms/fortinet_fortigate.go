@@ -0,0 +1,314 @@
+package platforms
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/scrapli/scrapligo/driver/generic"
+	sopoptions "github.com/scrapli/scrapligo/driver/opoptions"
+
+	"github.com/carlmontanari/boxen/boxen/instance"
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+const (
+	FortinetFortigateScrapliPlatform = "fortinet_fortios"
+	FortinetFortigateDefaultUser     = "admin"
+	FortinetFortigateDefaultPass     = ""
+
+	fortinetFortigateDefaultPromptWait = 60
+	// fortinetFortigateMaxSSHKeys is the number of ssh-public-keyN slots a fortigate admin has.
+	fortinetFortigateMaxSSHKeys = 3
+)
+
+type FortinetFortigate struct {
+	*instance.Qemu
+	*ScrapliConsole
+}
+
+func (p *FortinetFortigate) Package(
+	_, _ string,
+) (packageFiles, runFiles []string, err error) {
+	return []string{}, []string{}, err
+}
+
+func (p *FortinetFortigate) startReady() error {
+	err := p.openRetry()
+	if err != nil {
+		return err
+	}
+
+	err = p.readUntil(
+		[]byte("login:"),
+		getPlatformBootTimeout(PlatformTypeFortinetFortigate),
+	)
+	if err != nil {
+		return err
+	}
+
+	return p.c.Channel.WriteReturn()
+}
+
+// initialConfigPrompt handles the first login of a fresh fortigate -- the admin user has no
+// password, and fortios forces a password change on first login, so we set the admin password to
+// the password of the instance here.
+func (p *FortinetFortigate) initialConfigPrompt() error {
+	loginPrompt, _ := generic.NewCallback(
+		func(d *generic.Driver, output string) error {
+			return d.Channel.WriteAndReturn([]byte(FortinetFortigateDefaultUser), false)
+		},
+		sopoptions.WithCallbackContains("login:"),
+		sopoptions.WithCallbackNextTimeout(fortinetFortigateDefaultPromptWait*time.Second),
+	)
+
+	passwordPrompt, _ := generic.NewCallback(
+		func(d *generic.Driver, output string) error {
+			return d.Channel.WriteAndReturn([]byte(FortinetFortigateDefaultPass), true)
+		},
+		// anchored so this doesn't match the "new password" prompt!
+		sopoptions.WithCallbackContainsRe(regexp.MustCompile(`(?im)^password:\s?$`)),
+		sopoptions.WithCallbackNextTimeout(fortinetFortigateDefaultPromptWait*time.Second),
+	)
+
+	newPasswordPrompt, _ := generic.NewCallback(
+		func(d *generic.Driver, output string) error {
+			return d.Channel.WriteAndReturn([]byte(p.Credentials.Password), true)
+		},
+		sopoptions.WithCallbackContains("new password:"),
+		sopoptions.WithCallbackNextTimeout(fortinetFortigateDefaultPromptWait*time.Second),
+	)
+
+	confirmPasswordPrompt, _ := generic.NewCallback(
+		func(d *generic.Driver, output string) error {
+			return d.Channel.WriteAndReturn([]byte(p.Credentials.Password), true)
+		},
+		sopoptions.WithCallbackContains("confirm password:"),
+		sopoptions.WithCallbackNextTimeout(fortinetFortigateDefaultPromptWait*time.Second),
+	)
+
+	devicePrompt, _ := generic.NewCallback(
+		nil,
+		sopoptions.WithCallbackContainsRe(p.joinScrapliPatterns()),
+		sopoptions.WithCallbackComplete(),
+	)
+
+	callbacks := []*generic.Callback{
+		loginPrompt,
+		passwordPrompt,
+		newPasswordPrompt,
+		confirmPasswordPrompt,
+		devicePrompt,
+	}
+
+	_, err := p.c.SendWithCallbacks(
+		"",
+		callbacks,
+		fortinetFortigateDefaultPromptWait*time.Second,
+	)
+
+	return err
+}
+
+func (p *FortinetFortigate) Install(opts ...instance.Option) error { //nolint:funlen
+	p.Loggers.Base.Info("install requested")
+
+	a, opts, err := setInstallArgs(opts...)
+	if err != nil {
+		return err
+	}
+
+	c := make(chan error, 1)
+	stop := make(chan bool, 1)
+
+	go func() {
+		err = p.Qemu.Start(opts...)
+		if err != nil {
+			c <- err
+
+			return
+		}
+
+		p.Loggers.Base.Debug("instance started, waiting for start ready state")
+
+		err = p.startReady()
+		if err != nil {
+			p.Loggers.Base.Criticalf("error waiting for start ready state: %s\n", err)
+
+			c <- err
+
+			return
+		}
+
+		p.Loggers.Base.Debug("start ready state acquired, handling initial password change")
+
+		err = p.initialConfigPrompt()
+		if err != nil {
+			p.Loggers.Base.Criticalf("error running through initial password change: %s\n", err)
+
+			c <- err
+
+			return
+		}
+
+		p.Loggers.Base.Debug("initial password change addressed, log in complete")
+
+		if a.configLines != nil {
+			p.Loggers.Base.Debug("install config lines provided, executing scrapligo on open")
+
+			err = p.defOnOpen(p.c)
+			if err != nil {
+				p.Loggers.Base.Criticalf("error running scrapligo on open: %s\n", err)
+
+				c <- err
+
+				return
+			}
+
+			err = p.Config(a.configLines)
+			if err != nil {
+				p.Loggers.Base.Criticalf("error sending install config lines: %s\n", err)
+
+				c <- err
+
+				return
+			}
+		}
+
+		p.Loggers.Base.Debug("initial installation complete")
+
+		err = p.SaveConfig()
+		if err != nil {
+			p.Loggers.Base.Criticalf("error saving config: %s\n", err)
+
+			c <- err
+
+			return
+		}
+
+		// small delay ensuring config is saved nicely, without this extra sleep things just seem to
+		// not actually "save" despite the "save complete" or whatever output.
+		time.Sleep(5 * time.Second) // nolint:gomnd
+
+		c <- nil
+		stop <- true
+	}()
+
+	go p.WatchMainProc(c, stop)
+
+	err = <-c
+	if err != nil {
+		return err
+	}
+
+	p.Loggers.Base.Info("install complete, stopping instance")
+
+	return p.Stop(opts...)
+}
+
+func (p *FortinetFortigate) Start(opts ...instance.Option) error { //nolint:dupl
+	p.Loggers.Base.Info("start platform instance requested")
+
+	a, opts, err := setStartArgs(opts...)
+	if err != nil {
+		return err
+	}
+
+	err = p.Qemu.Start(opts...)
+	if err != nil {
+		return err
+	}
+
+	err = p.startReady()
+	if err != nil {
+		p.Loggers.Base.Criticalf("error waiting for start ready state: %s\n", err)
+
+		return err
+	}
+
+	if !a.prepareConsole {
+		p.Loggers.Base.Info("prepare console not requested, starting instance complete")
+
+		return nil
+	}
+
+	err = p.login(
+		&loginArgs{
+			username: p.Credentials.Username,
+			password: p.Credentials.Password,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	err = p.defOnOpen(p.c)
+	if err != nil {
+		return err
+	}
+
+	p.Loggers.Base.Info("starting platform instance complete")
+
+	return nil
+}
+
+func (p *FortinetFortigate) InstallConfig(f string, replace bool) error {
+	if replace {
+		p.Loggers.Base.Info("config replace not supported for platform, merging config instead")
+	}
+
+	return p.mergeConfigFromFile(f)
+}
+
+func (p *FortinetFortigate) SaveConfig() error {
+	// fortios saves configs as soon as a config block is closed with "end", nothing to do here!
+	p.Loggers.Base.Info("save config requested, fortios saves configuration automatically")
+
+	return nil
+}
+
+func (p *FortinetFortigate) SetUserPass(usr, pwd string) error {
+	p.Loggers.Base.Infof("set user/password for user '%s' requested", usr)
+
+	return p.Config([]string{
+		"config system admin",
+		fmt.Sprintf("edit %s", usr),
+		"set accprofile super_admin",
+		fmt.Sprintf("set password %s", pwd),
+		"next",
+		"end",
+	})
+}
+
+func (p *FortinetFortigate) SetSSHKeys(usr string, keys []*util.SSHPublicKey) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	p.Loggers.Base.Infof("set ssh keys for user '%s' requested", usr)
+
+	err := ValidateSSHKeys(PlatformTypeFortinetFortigate, len(keys))
+	if err != nil {
+		return err
+	}
+
+	lines := []string{"config system admin", fmt.Sprintf("edit %s", usr)}
+
+	for i, k := range keys {
+		lines = append(lines, fmt.Sprintf("set ssh-public-key%d \"%s\"", i+1, k))
+	}
+
+	lines = append(lines, "next", "end")
+
+	return p.Config(lines)
+}
+
+func (p *FortinetFortigate) SetHostname(h string) error {
+	p.Loggers.Base.Infof("set hostname '%s' requested", h)
+
+	return p.Config([]string{
+		"config system global",
+		fmt.Sprintf("set hostname %s", h),
+		"end",
+	})
+}
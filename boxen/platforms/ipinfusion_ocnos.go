@@ -7,6 +7,7 @@ import (
 	sopoptions "github.com/scrapli/scrapligo/driver/opoptions"
 
 	"github.com/carlmontanari/boxen/boxen/instance"
+	"github.com/carlmontanari/boxen/boxen/util"
 )
 
 const (
@@ -209,6 +210,17 @@ func (p *IPInfusionOcNOS) SetUserPass(usr, pwd string) error {
 	return err
 }
 
+// SetSSHKeys is not supported on IPInfusionOcNOS -- providing any keys is an error.
+func (p *IPInfusionOcNOS) SetSSHKeys(usr string, keys []*util.SSHPublicKey) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	p.Loggers.Base.Infof("set ssh keys for user '%s' requested", usr)
+
+	return ValidateSSHKeys(PlatformTypeIPInfusionOcNOS, len(keys))
+}
+
 func (p *IPInfusionOcNOS) SetHostname(h string) error {
 	p.Loggers.Base.Infof("set hostname '%s' requested", h)
 
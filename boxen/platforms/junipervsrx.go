@@ -258,6 +258,27 @@ func (p *JuniperVsrx) SetUserPass(usr, pwd string) error {
 	return err
 }
 
+func (p *JuniperVsrx) SetSSHKeys(usr string, keys []*util.SSHPublicKey) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	p.Loggers.Base.Infof("set ssh keys for user '%s' requested", usr)
+
+	lines := make([]string, len(keys))
+
+	for i, k := range keys {
+		lines[i] = fmt.Sprintf(
+			"set system login user %s authentication ssh-%s \"%s\"",
+			usr,
+			k.Algorithm(),
+			k,
+		)
+	}
+
+	return p.Config(lines)
+}
+
 func (p *JuniperVsrx) SetHostname(h string) error {
 	p.Loggers.Base.Infof("set hostname '%s' requested", h)
 
@@ -0,0 +1,352 @@
+package platforms
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/carlmontanari/boxen/boxen/config"
+	"github.com/carlmontanari/boxen/boxen/instance"
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+const (
+	LinuxScrapliPlatform = "linux"
+	// LinuxSeedFileName is the name of the cloud-init nocloud seed iso that is generated next to the
+	// instance disk.
+	LinuxSeedFileName = "cidata.iso"
+	// LinuxPasswordHashFileName is the name of the file the hash of the users password is stored in
+	// next to the instance disk, so the (randomly salted) hash -- and therefore the seed -- is
+	// stable across boxen runs.
+	LinuxPasswordHashFileName = "password.hash"
+	// LinuxMgmtMac is the mac address assigned to the management nic of linux instances, this is
+	// used to match the management nic in the cloud-init network config, so we don't have to care
+	// about how each distribution names its interfaces.
+	LinuxMgmtMac = "52:54:00:ff:ff:00"
+
+	linuxDefaultBootTime = 300
+)
+
+// Linux is a "generic" linux platform -- this is meant for booting "normal" cloud images (ubuntu,
+// debian, alpine, etc.) to act as hosts/traffic generators and the like in a lab. Rather than
+// scraping the console to configure things, linux instances are configured by cloud-init via a
+// nocloud seed iso that boxen generates before the instance is started.
+type Linux struct {
+	*instance.Qemu
+	*ScrapliConsole
+
+	hostname        string
+	runCmds         []string
+	userData        []byte
+	replaceUserData bool
+	seed            []byte
+}
+
+func (p *Linux) Package(
+	_, _ string,
+) (packageFiles, runFiles []string, err error) {
+	return []string{}, []string{}, err
+}
+
+func (p *Linux) seedPath() string {
+	return filepath.Join(filepath.Dir(p.Disk), LinuxSeedFileName)
+}
+
+func (p *Linux) passwordHashPath() string {
+	return filepath.Join(filepath.Dir(p.Disk), LinuxPasswordHashFileName)
+}
+
+func (p *Linux) patchCmdMgmtMac(c *instance.QemuLaunchCmd) {
+	if c.MgmtNic != nil {
+		c.MgmtNic.Device.Props.Set("mac", LinuxMgmtMac)
+	}
+}
+
+func (p *Linux) patchCmdSeed(c *instance.QemuLaunchCmd) {
+	c.Extra = append(
+		c.Extra,
+		&instance.Drive{
+			Props: instance.Props{
+				{Key: "if", Value: "ide"},
+				{Key: "media", Value: "cdrom"},
+				{Key: "format", Value: "raw"},
+				{Key: "file", Value: p.seedPath()},
+			},
+		})
+}
+
+func (p *Linux) modifyStartCmd(c *instance.QemuLaunchCmd) {
+	p.patchCmdMgmtMac(c)
+	p.patchCmdSeed(c)
+}
+
+func (p *Linux) modifyInstallCmd(c *instance.QemuLaunchCmd) {
+	p.modifyStartCmd(c)
+}
+
+// writeSeed renders the cloud-init seed for the instance and writes it to disk if it has changed
+// since it was last written, returning true if the seed was (re)written.
+func (p *Linux) writeSeed() (bool, error) {
+	if p.hostname == "" {
+		p.hostname = p.Name
+	}
+
+	_, err := p.ResolveCredentials()
+	if err != nil {
+		return false, err
+	}
+
+	files, err := p.renderSeed()
+	if err != nil {
+		return false, err
+	}
+
+	seed := []byte(fmt.Sprintf("%s\n%s", files[linuxSeedMetaData], files[linuxSeedUserData]))
+
+	if p.seed != nil && string(p.seed) == string(seed) {
+		return false, nil
+	}
+
+	p.Loggers.Base.Debugf("writing cloud-init seed to '%s'", p.seedPath())
+
+	err = util.WriteISO9660(p.seedPath(), linuxSeedLabel, files)
+	if err != nil {
+		p.Loggers.Base.Criticalf("error writing cloud-init seed: %s\n", err)
+
+		return false, err
+	}
+
+	p.seed = seed
+
+	return true, nil
+}
+
+func (p *Linux) startReady() error {
+	err := p.openRetry()
+	if err != nil {
+		return err
+	}
+
+	return p.readUntil(
+		[]byte(linuxCloudInitFinished),
+		getPlatformBootTimeout(PlatformTypeLinux),
+	)
+}
+
+func (p *Linux) Install(opts ...instance.Option) error {
+	p.Loggers.Base.Info("install requested")
+
+	a, opts, err := setInstallArgs(opts...)
+	if err != nil {
+		return err
+	}
+
+	// install config lines are just run once by cloud-init on the first boot
+	err = p.Config(a.configLines)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.writeSeed()
+	if err != nil {
+		return err
+	}
+
+	opts = append(opts, instance.WithLaunchModifier(p.modifyInstallCmd))
+
+	c := make(chan error, 1)
+	stop := make(chan bool, 1)
+
+	go func() {
+		err = p.Qemu.Start(opts...)
+		if err != nil {
+			c <- err
+
+			return
+		}
+
+		p.Loggers.Base.Debug("instance started, waiting for cloud-init to finish")
+
+		err = p.startReady()
+		if err != nil {
+			p.Loggers.Base.Criticalf("error waiting for cloud-init to finish: %s\n", err)
+
+			c <- err
+
+			return
+		}
+
+		p.Loggers.Base.Debug("initial installation complete")
+
+		// small delay to let the guest flush anything cloud-init wrote before we kill it
+		time.Sleep(5 * time.Second) // nolint:gomnd
+
+		c <- nil
+		stop <- true
+	}()
+
+	go p.WatchMainProc(c, stop)
+
+	err = <-c
+	if err != nil {
+		return err
+	}
+
+	p.Loggers.Base.Info("install complete, stopping instance")
+
+	return p.Stop(opts...)
+}
+
+func (p *Linux) Start(opts ...instance.Option) error {
+	p.Loggers.Base.Info("start platform instance requested")
+
+	_, opts, err := setStartArgs(opts...)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.writeSeed()
+	if err != nil {
+		return err
+	}
+
+	opts = append(opts, instance.WithLaunchModifier(p.modifyStartCmd))
+
+	err = p.Qemu.Start(opts...)
+	if err != nil {
+		return err
+	}
+
+	err = p.startReady()
+	if err != nil {
+		p.Loggers.Base.Criticalf("error waiting for cloud-init to finish: %s\n", err)
+
+		return err
+	}
+
+	p.Loggers.Base.Info("starting platform instance complete")
+
+	return nil
+}
+
+// Config for linux instances does not send anything to the instance, the lines are staged as
+// cloud-init "runcmd" entries that are executed after the next SaveConfig.
+func (p *Linux) Config(lines []string) error {
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		p.runCmds = append(p.runCmds, line)
+	}
+
+	return nil
+}
+
+// InstallConfig for linux instances loads the file f as cloud-init user-data. If replace is true
+// the file is used as the user-data as is, otherwise it is merged with the boxen generated
+// user-data (user/password/hostname). As with Config, this is applied by SaveConfig.
+func (p *Linux) InstallConfig(f string, replace bool) error {
+	p.Loggers.Base.Info("install config requested")
+
+	resolvedF, err := util.ResolveFile(f)
+	if err != nil {
+		return err
+	}
+
+	b, err := os.ReadFile(resolvedF)
+	if err != nil {
+		return err
+	}
+
+	if !strings.HasPrefix(string(b), "#cloud-config") && !strings.HasPrefix(string(b), "#!") {
+		return fmt.Errorf(
+			"%w: linux config must be cloud-config or a script, config file '%s' is neither",
+			util.ErrValidationError,
+			f,
+		)
+	}
+
+	p.userData = b
+	p.replaceUserData = replace
+
+	return nil
+}
+
+// SaveConfig applies any staged changes -- if the rendered cloud-init seed has changed the seed is
+// rewritten and the instance is reset (via the qemu monitor) so cloud-init picks up the changes.
+func (p *Linux) SaveConfig() error {
+	p.Loggers.Base.Info("save config requested")
+
+	changed, err := p.writeSeed()
+	if err != nil {
+		return err
+	}
+
+	if !changed {
+		p.Loggers.Base.Debug("cloud-init seed unchanged, nothing to do")
+
+		return nil
+	}
+
+	p.Loggers.Base.Debug("cloud-init seed changed, resetting instance")
+
+	_, err = p.MonitorCommand("system_reset")
+	if err != nil {
+		return err
+	}
+
+	return p.readUntil(
+		[]byte(linuxCloudInitFinished),
+		getPlatformBootTimeout(PlatformTypeLinux),
+	)
+}
+
+func (p *Linux) SetUserPass(usr, pwd string) error {
+	p.Loggers.Base.Infof("set user/password for user '%s' requested", usr)
+
+	// credentials may be shared w/ the global options, so don't modify them in place
+	p.Credentials = &config.Credentials{
+		Username: usr,
+		Password: pwd,
+		SSHKeys:  p.Credentials.SSHKeys,
+	}
+
+	return nil
+}
+
+// SetSSHKeys for linux instances stages the keys as the users cloud-init "ssh_authorized_keys",
+// they are applied by SaveConfig. The keys replace any keys the instance was configured with.
+func (p *Linux) SetSSHKeys(usr string, keys []*util.SSHPublicKey) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	p.Loggers.Base.Infof("set ssh keys for user '%s' requested", usr)
+
+	sshKeys := make([]string, len(keys))
+
+	for i, k := range keys {
+		sshKeys[i] = k.String()
+	}
+
+	// copy, credentials may be shared w/ the global options and the password may still be a source
+	creds := *p.Credentials
+	creds.Username = usr
+	creds.UsernameFrom = nil
+	creds.SSHKeys = sshKeys
+
+	p.Credentials = &creds
+
+	return nil
+}
+
+func (p *Linux) SetHostname(h string) error {
+	p.Loggers.Base.Infof("set hostname '%s' requested", h)
+
+	p.hostname = h
+
+	return nil
+}
@@ -0,0 +1,251 @@
+package platforms
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+const (
+	linuxSeedLabel         = "cidata"
+	linuxSeedMetaData      = "meta-data"
+	linuxSeedUserData      = "user-data"
+	linuxSeedNetworkConfig = "network-config"
+
+	// linuxCloudInitFinished is (the end of) what cloud-init emits on the console when the final
+	// stage is done, i.e. "Cloud-init v. 22.4.2 finished at ...".
+	linuxCloudInitFinished = "finished at"
+
+	linuxMultipartBoundary = "==BOXEN-CLOUD-INIT=="
+
+	// linuxSetPasswordCmd sets the password hash ($2) of the user ($1) if the user exists, cloud-init
+	// only sets "passwd" when it creates the user, so a changed password is applied on every boot.
+	linuxSetPasswordCmd = "id -u \"$1\" >/dev/null 2>&1 && usermod -p \"$2\" \"$1\" || true"
+)
+
+type linuxCloudConfigUser struct {
+	Name              string   `yaml:"name"`
+	Sudo              string   `yaml:"sudo"`
+	LockPasswd        bool     `yaml:"lock_passwd"`
+	Passwd            string   `yaml:"passwd,omitempty"`
+	SSHAuthorizedKeys []string `yaml:"ssh_authorized_keys,omitempty"`
+}
+
+type linuxCloudConfigChpasswd struct {
+	Expire bool `yaml:"expire"`
+}
+
+type linuxCloudConfig struct {
+	Hostname       string                    `yaml:"hostname"`
+	ManageEtcHosts bool                      `yaml:"manage_etc_hosts"`
+	SSHPwauth      bool                      `yaml:"ssh_pwauth"`
+	Chpasswd       *linuxCloudConfigChpasswd `yaml:"chpasswd"`
+	Users          []*linuxCloudConfigUser   `yaml:"users"`
+	BootCmd        [][]string                `yaml:"bootcmd,omitempty"`
+	RunCmd         []string                  `yaml:"runcmd,omitempty"`
+}
+
+type linuxMetaData struct {
+	InstanceID    string `yaml:"instance-id"`
+	LocalHostname string `yaml:"local-hostname"`
+}
+
+// hashedPassword returns the sha512-crypt hash of the users password. The hash is stored next to
+// the instance disk and reused as long as the password doesn't change, otherwise the random salt
+// would change the rendered seed every time boxen renders it.
+func (p *Linux) hashedPassword() (string, error) {
+	if p.Credentials.Password == "" {
+		return "", nil
+	}
+
+	stored, err := os.ReadFile(p.passwordHashPath())
+	if err == nil {
+		// hash is "$6$salt$digest", so rehash w/ the stored salt to see if the password changed
+		parts := strings.Split(strings.TrimSpace(string(stored)), "$")
+
+		if len(parts) == 4 {
+			h, err := util.HashPasswordWithSalt(
+				util.PasswordHashSha512Crypt,
+				p.Credentials.Password,
+				parts[2],
+			)
+			if err != nil {
+				return "", err
+			}
+
+			if h == strings.TrimSpace(string(stored)) {
+				return h, nil
+			}
+		}
+	}
+
+	h, err := util.HashPassword(util.PasswordHashSha512Crypt, p.Credentials.Password)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.WriteFile(p.passwordHashPath(), []byte(h+"\n"), util.PrivateFilePerms)
+	if err != nil {
+		return "", fmt.Errorf(
+			"%w: failed storing password hash for instance '%s': %s",
+			util.ErrInstanceError,
+			p.Name,
+			err,
+		)
+	}
+
+	return h, nil
+}
+
+// renderCloudConfig renders the boxen generated cloud-config for the instance, passwd is the hash
+// of the users password, if it is empty the user has no password.
+func (p *Linux) renderCloudConfig(passwd string) ([]byte, error) {
+	cc := &linuxCloudConfig{
+		Hostname:       p.hostname,
+		ManageEtcHosts: true,
+		SSHPwauth:      true,
+		Chpasswd:       &linuxCloudConfigChpasswd{Expire: false},
+		Users: []*linuxCloudConfigUser{
+			{
+				Name:              p.Credentials.Username,
+				Sudo:              "ALL=(ALL) NOPASSWD:ALL",
+				LockPasswd:        false,
+				Passwd:            passwd,
+				SSHAuthorizedKeys: p.Credentials.SSHKeys,
+			},
+		},
+		RunCmd: p.runCmds,
+	}
+
+	if passwd != "" {
+		cc.BootCmd = [][]string{
+			{"sh", "-c", linuxSetPasswordCmd, "boxen", p.Credentials.Username, passwd},
+		}
+	}
+
+	b, err := yaml.Marshal(cc)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte("#cloud-config\n"), b...), nil
+}
+
+func linuxUserDataContentType(b []byte) string {
+	if bytes.HasPrefix(b, []byte("#!")) {
+		return "text/x-shellscript"
+	}
+
+	return "text/cloud-config"
+}
+
+// renderUserData renders the user-data for the instance -- if the user provided some user-data
+// (via InstallConfig) this is either used as is, or bundled with the boxen generated cloud-config
+// as a multipart document that cloud-init merges.
+func (p *Linux) renderUserData(passwd string) ([]byte, error) {
+	if p.userData != nil && p.replaceUserData {
+		return p.userData, nil
+	}
+
+	cc, err := p.renderCloudConfig(passwd)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.userData == nil {
+		return cc, nil
+	}
+
+	var body bytes.Buffer
+
+	w := multipart.NewWriter(&body)
+
+	// fixed boundary so the rendered user-data (and therefore the instance id) is stable
+	_ = w.SetBoundary(linuxMultipartBoundary)
+
+	for _, part := range [][]byte{cc, p.userData} {
+		h := textproto.MIMEHeader{}
+		h.Set("Content-Type", linuxUserDataContentType(part))
+		h.Set("Merge-Type", "list(append)+dict(no_replace,recurse_list)+str()")
+
+		pw, err := w.CreatePart(h)
+		if err != nil {
+			return nil, err
+		}
+
+		_, _ = pw.Write(part)
+	}
+
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(fmt.Sprintf(
+		"Content-Type: multipart/mixed; boundary=\"%s\"\nMIME-Version: 1.0\n\n%s",
+		linuxMultipartBoundary,
+		body.String(),
+	)), nil
+}
+
+func linuxNetworkConfig() []byte {
+	return []byte(fmt.Sprintf(
+		"version: 2\n"+
+			"ethernets:\n"+
+			"  mgmt:\n"+
+			"    match:\n"+
+			"      macaddress: \"%s\"\n"+
+			"    dhcp4: true\n",
+		LinuxMgmtMac,
+	))
+}
+
+// renderSeed renders all the files for the nocloud seed. The instance id is derived from the
+// contents of the seed, so any change (user, hostname, etc.) causes cloud-init to treat the
+// instance as "new" and re-run the per-instance modules on the next boot. The password hash is
+// left out of the instance id, a changed password is applied by the bootcmd on every boot.
+func (p *Linux) renderSeed() (map[string][]byte, error) {
+	passwd, err := p.hashedPassword()
+	if err != nil {
+		return nil, err
+	}
+
+	userData, err := p.renderUserData(passwd)
+	if err != nil {
+		return nil, err
+	}
+
+	idUserData, err := p.renderUserData("")
+	if err != nil {
+		return nil, err
+	}
+
+	networkConfig := linuxNetworkConfig()
+
+	h := sha256.New()
+	_, _ = h.Write([]byte(p.hostname))
+	_, _ = h.Write(idUserData)
+	_, _ = h.Write(networkConfig)
+
+	metaData, err := yaml.Marshal(&linuxMetaData{
+		InstanceID:    fmt.Sprintf("boxen-%x", h.Sum(nil)[:8]),
+		LocalHostname: p.hostname,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string][]byte{
+		linuxSeedMetaData:      metaData,
+		linuxSeedUserData:      userData,
+		linuxSeedNetworkConfig: networkConfig,
+	}, nil
+}
@@ -0,0 +1,184 @@
+package platforms
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/carlmontanari/boxen/boxen/config"
+	"github.com/carlmontanari/boxen/boxen/instance"
+	"github.com/carlmontanari/boxen/boxen/logging"
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v2"
+)
+
+// readSeed returns the volume label and the root directory files of the seed iso.
+func readSeed(t *testing.T, f string) (string, map[string][]byte) {
+	t.Helper()
+
+	img, err := os.ReadFile(f)
+	if err != nil {
+		t.Fatalf("failed reading seed: %s", err)
+	}
+
+	pvd := img[16*2048 : 17*2048]
+	if pvd[0] != 1 || string(pvd[1:6]) != "CD001" {
+		t.Fatalf("seed has no primary volume descriptor")
+	}
+
+	rootExtent := binary.LittleEndian.Uint32(pvd[158:162])
+	rootSize := binary.LittleEndian.Uint32(pvd[166:170])
+	dir := img[rootExtent*2048 : rootExtent*2048+rootSize]
+
+	files := map[string][]byte{}
+
+	for len(dir) > 0 && dir[0] != 0 {
+		name := string(dir[33 : 33+dir[32]])
+		extent := binary.LittleEndian.Uint32(dir[2:6])
+		size := binary.LittleEndian.Uint32(dir[10:14])
+
+		if dir[25]&2 == 0 {
+			files[strings.TrimSuffix(name, ";1")] = img[extent*2048 : extent*2048+size]
+		}
+
+		dir = dir[dir[0]:]
+	}
+
+	return strings.TrimRight(string(pvd[40:72]), " "), files
+}
+
+func testLinux(t *testing.T) *Linux {
+	t.Helper()
+
+	l, err := logging.NewInstance(func(...interface{}) {})
+	if err != nil {
+		t.Fatalf("failed creating logger: %s", err)
+	}
+
+	return &Linux{
+		Qemu: &instance.Qemu{
+			Name: "vm1",
+			Disk: filepath.Join(t.TempDir(), "disk.qcow2"),
+			Credentials: &config.Credentials{
+				Username: "boxen",
+				Password: "b0x3n",
+				SSHKeys:  []string{"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIBoxen boxen@lab"},
+			},
+			Loggers: &instance.Loggers{Base: l},
+		},
+	}
+}
+
+func TestWriteSeed(t *testing.T) {
+	p := testLinux(t)
+
+	changed, err := p.writeSeed()
+	if err != nil {
+		t.Fatalf("failed writing seed: %s", err)
+	}
+
+	if !changed {
+		t.Fatal("expected initial seed to be written")
+	}
+
+	label, files := readSeed(t, p.seedPath())
+
+	if label != linuxSeedLabel {
+		t.Fatalf("got label %q, want %q", label, linuxSeedLabel)
+	}
+
+	want, err := p.renderSeed()
+	if err != nil {
+		t.Fatalf("failed rendering seed: %s", err)
+	}
+
+	if diff := cmp.Diff(want, files); diff != "" {
+		t.Fatalf("seed files do not match (-want +got):\n%s", diff)
+	}
+
+	md := &linuxMetaData{}
+
+	err = yaml.Unmarshal(files[linuxSeedMetaData], md)
+	if err != nil {
+		t.Fatalf("failed parsing meta-data: %s", err)
+	}
+
+	if !strings.HasPrefix(md.InstanceID, "boxen-") || md.LocalHostname != "vm1" {
+		t.Fatalf("unexpected meta-data: %+v", md)
+	}
+
+	if !strings.HasPrefix(string(files[linuxSeedUserData]), "#cloud-config\n") {
+		t.Fatalf("user-data is not a cloud-config: %q", files[linuxSeedUserData])
+	}
+
+	cc := &linuxCloudConfig{}
+
+	err = yaml.Unmarshal(files[linuxSeedUserData], cc)
+	if err != nil {
+		t.Fatalf("failed parsing user-data: %s", err)
+	}
+
+	if len(cc.Users) != 1 || cc.Users[0].Name != "boxen" ||
+		!strings.HasPrefix(cc.Users[0].Passwd, "$6$") ||
+		!cmp.Equal(cc.Users[0].SSHAuthorizedKeys, p.Credentials.SSHKeys) {
+		t.Fatalf("unexpected users in user-data: %+v", cc.Users)
+	}
+
+	if !strings.Contains(string(files[linuxSeedNetworkConfig]), LinuxMgmtMac) {
+		t.Fatalf("network-config does not match the mgmt mac: %q", files[linuxSeedNetworkConfig])
+	}
+}
+
+func TestWriteSeedStable(t *testing.T) {
+	p := testLinux(t)
+
+	_, err := p.writeSeed()
+	if err != nil {
+		t.Fatalf("failed writing seed: %s", err)
+	}
+
+	_, first := readSeed(t, p.seedPath())
+
+	// a "new" boxen run for the same instance reuses the stored password hash
+	p.seed = nil
+
+	_, err = p.writeSeed()
+	if err != nil {
+		t.Fatalf("failed writing seed: %s", err)
+	}
+
+	_, second := readSeed(t, p.seedPath())
+
+	if diff := cmp.Diff(first, second); diff != "" {
+		t.Fatalf("seed changed across runs (-first +second):\n%s", diff)
+	}
+
+	changed, err := p.writeSeed()
+	if err != nil {
+		t.Fatalf("failed writing seed: %s", err)
+	}
+
+	if changed {
+		t.Fatal("expected unchanged seed not to be rewritten")
+	}
+
+	// a changed password changes the user-data, but not the instance id
+	p.Credentials.Password = "n3wb0x3n"
+
+	changed, err = p.writeSeed()
+	if err != nil {
+		t.Fatalf("failed writing seed: %s", err)
+	}
+
+	_, third := readSeed(t, p.seedPath())
+
+	if !changed || string(third[linuxSeedUserData]) == string(first[linuxSeedUserData]) {
+		t.Fatal("expected changed password to rewrite the user-data")
+	}
+
+	if diff := cmp.Diff(first[linuxSeedMetaData], third[linuxSeedMetaData]); diff != "" {
+		t.Fatalf("changed password changed the meta-data (-first +third):\n%s", diff)
+	}
+}
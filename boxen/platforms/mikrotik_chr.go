@@ -0,0 +1,315 @@
+package platforms
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/scrapli/scrapligo/driver/generic"
+	sopoptions "github.com/scrapli/scrapligo/driver/opoptions"
+
+	"github.com/carlmontanari/boxen/boxen/instance"
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+const (
+	MikrotikChrScrapliPlatform = "mikrotik_routeros"
+	MikrotikChrDefaultUser     = "admin"
+	MikrotikChrDefaultPass     = ""
+
+	// mikrotikChrLoginSuffix is appended to the username at login time, "c" disables colors and
+	// "t" disables terminal auto detection, without these the console output is full of escape
+	// codes that make scrapligo sad.
+	mikrotikChrLoginSuffix = "+ct"
+
+	mikrotikChrDefaultPromptWait = 60
+)
+
+type MikrotikChr struct {
+	*instance.Qemu
+	*ScrapliConsole
+}
+
+func (p *MikrotikChr) Package(
+	_, _ string,
+) (packageFiles, runFiles []string, err error) {
+	return []string{}, []string{}, err
+}
+
+func (p *MikrotikChr) startReady() error {
+	err := p.openRetry()
+	if err != nil {
+		return err
+	}
+
+	err = p.readUntil(
+		[]byte("login:"),
+		getPlatformBootTimeout(PlatformTypeMikrotikChr),
+	)
+	if err != nil {
+		return err
+	}
+
+	return p.c.Channel.WriteReturn()
+}
+
+// initialConfigPrompt handles the first login of a fresh chr -- the admin user has a blank
+// password, and newer routeros versions ask about the license and force a password change on first
+// login. The admin password is set to the password of the instance here.
+func (p *MikrotikChr) initialConfigPrompt() error {
+	loginPrompt, _ := generic.NewCallback(
+		func(d *generic.Driver, output string) error {
+			return d.Channel.WriteAndReturn(
+				[]byte(MikrotikChrDefaultUser+mikrotikChrLoginSuffix),
+				false,
+			)
+		},
+		sopoptions.WithCallbackContains("login:"),
+		sopoptions.WithCallbackNextTimeout(mikrotikChrDefaultPromptWait*time.Second),
+	)
+
+	passwordPrompt, _ := generic.NewCallback(
+		func(d *generic.Driver, output string) error {
+			return d.Channel.WriteAndReturn([]byte(MikrotikChrDefaultPass), true)
+		},
+		// anchored so this doesn't match the "new password" prompts!
+		sopoptions.WithCallbackContainsRe(regexp.MustCompile(`(?im)^password:\s?$`)),
+		sopoptions.WithCallbackNextTimeout(mikrotikChrDefaultPromptWait*time.Second),
+	)
+
+	licensePrompt, _ := generic.NewCallback(
+		func(d *generic.Driver, output string) error {
+			return d.Channel.WriteAndReturn([]byte("n"), false)
+		},
+		sopoptions.WithCallbackContains("software license? [y/n]"),
+		sopoptions.WithCallbackNextTimeout(mikrotikChrDefaultPromptWait*time.Second),
+	)
+
+	newPasswordPrompt, _ := generic.NewCallback(
+		func(d *generic.Driver, output string) error {
+			return d.Channel.WriteAndReturn([]byte(p.Credentials.Password), true)
+		},
+		sopoptions.WithCallbackContainsRe(regexp.MustCompile(`(?im)^new password>\s?$`)),
+		sopoptions.WithCallbackNextTimeout(mikrotikChrDefaultPromptWait*time.Second),
+	)
+
+	repeatPasswordPrompt, _ := generic.NewCallback(
+		func(d *generic.Driver, output string) error {
+			return d.Channel.WriteAndReturn([]byte(p.Credentials.Password), true)
+		},
+		sopoptions.WithCallbackContains("repeat new password>"),
+		sopoptions.WithCallbackNextTimeout(mikrotikChrDefaultPromptWait*time.Second),
+	)
+
+	devicePrompt, _ := generic.NewCallback(
+		nil,
+		sopoptions.WithCallbackContainsRe(p.joinScrapliPatterns()),
+		sopoptions.WithCallbackComplete(),
+	)
+
+	callbacks := []*generic.Callback{
+		loginPrompt,
+		passwordPrompt,
+		licensePrompt,
+		newPasswordPrompt,
+		repeatPasswordPrompt,
+		devicePrompt,
+	}
+
+	_, err := p.c.SendWithCallbacks(
+		"",
+		callbacks,
+		mikrotikChrDefaultPromptWait*time.Second,
+	)
+
+	return err
+}
+
+func (p *MikrotikChr) Install(opts ...instance.Option) error { //nolint:funlen,dupl
+	p.Loggers.Base.Info("install requested")
+
+	a, opts, err := setInstallArgs(opts...)
+	if err != nil {
+		return err
+	}
+
+	c := make(chan error, 1)
+	stop := make(chan bool, 1)
+
+	go func() {
+		err = p.Qemu.Start(opts...)
+		if err != nil {
+			c <- err
+
+			return
+		}
+
+		p.Loggers.Base.Debug("instance started, waiting for start ready state")
+
+		err = p.startReady()
+		if err != nil {
+			p.Loggers.Base.Criticalf("error waiting for start ready state: %s\n", err)
+
+			c <- err
+
+			return
+		}
+
+		p.Loggers.Base.Debug("start ready state acquired, handling initial login dialog")
+
+		err = p.initialConfigPrompt()
+		if err != nil {
+			p.Loggers.Base.Criticalf("error running through initial login dialog: %s\n", err)
+
+			c <- err
+
+			return
+		}
+
+		p.Loggers.Base.Debug("initial login dialog addressed, log in complete")
+
+		if a.configLines != nil {
+			p.Loggers.Base.Debug("install config lines provided, executing scrapligo on open")
+
+			err = p.defOnOpen(p.c)
+			if err != nil {
+				p.Loggers.Base.Criticalf("error running scrapligo on open: %s\n", err)
+
+				c <- err
+
+				return
+			}
+
+			err = p.Config(a.configLines)
+			if err != nil {
+				p.Loggers.Base.Criticalf("error sending install config lines: %s\n", err)
+
+				c <- err
+
+				return
+			}
+		}
+
+		p.Loggers.Base.Debug("initial installation complete")
+
+		err = p.SaveConfig()
+		if err != nil {
+			p.Loggers.Base.Criticalf("error saving config: %s\n", err)
+
+			c <- err
+
+			return
+		}
+
+		// small delay ensuring config is flushed to disk before we shut the instance down.
+		time.Sleep(5 * time.Second) // nolint:gomnd
+
+		c <- nil
+		stop <- true
+	}()
+
+	go p.WatchMainProc(c, stop)
+
+	err = <-c
+	if err != nil {
+		return err
+	}
+
+	p.Loggers.Base.Info("install complete, stopping instance")
+
+	return p.Stop(opts...)
+}
+
+func (p *MikrotikChr) Start(opts ...instance.Option) error { //nolint:dupl
+	p.Loggers.Base.Info("start platform instance requested")
+
+	a, opts, err := setStartArgs(opts...)
+	if err != nil {
+		return err
+	}
+
+	err = p.Qemu.Start(opts...)
+	if err != nil {
+		return err
+	}
+
+	err = p.startReady()
+	if err != nil {
+		p.Loggers.Base.Criticalf("error waiting for start ready state: %s\n", err)
+
+		return err
+	}
+
+	if !a.prepareConsole {
+		p.Loggers.Base.Info("prepare console not requested, starting instance complete")
+
+		return nil
+	}
+
+	err = p.login(
+		&loginArgs{
+			username: p.Credentials.Username + mikrotikChrLoginSuffix,
+			password: p.Credentials.Password,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	err = p.defOnOpen(p.c)
+	if err != nil {
+		return err
+	}
+
+	p.Loggers.Base.Info("starting platform instance complete")
+
+	return nil
+}
+
+func (p *MikrotikChr) InstallConfig(f string, replace bool) error {
+	if replace {
+		p.Loggers.Base.Info("config replace not supported for platform, merging config instead")
+	}
+
+	return p.mergeConfigFromFile(f)
+}
+
+func (p *MikrotikChr) SaveConfig() error {
+	// routeros persists every change immediately, nothing to do here!
+	p.Loggers.Base.Info("save config requested, routeros saves configuration automatically")
+
+	return nil
+}
+
+func (p *MikrotikChr) SetUserPass(usr, pwd string) error {
+	p.Loggers.Base.Infof("set user/password for user '%s' requested", usr)
+
+	return p.Config([]string{fmt.Sprintf(
+		":if ([:len [/user find name=%[1]s]] > 0) do={/user set %[1]s password=\"%[2]s\"} "+
+			"else={/user add name=%[1]s password=\"%[2]s\" group=full}",
+		usr,
+		pwd,
+	)})
+}
+
+func (p *MikrotikChr) SetSSHKeys(usr string, keys []*util.SSHPublicKey) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	p.Loggers.Base.Infof("set ssh keys for user '%s' requested", usr)
+
+	lines := make([]string, len(keys))
+
+	for i, k := range keys {
+		lines[i] = fmt.Sprintf("/user ssh-keys add user=%s key=\"%s\"", usr, k)
+	}
+
+	return p.Config(lines)
+}
+
+func (p *MikrotikChr) SetHostname(h string) error {
+	p.Loggers.Base.Infof("set hostname '%s' requested", h)
+
+	return p.Config([]string{fmt.Sprintf("/system identity set name=%s", h)})
+}
@@ -20,6 +20,8 @@ const (
 	paloAltoPanosDefaultBootTime   = 720
 	paloAltoPanosDefaultPromptWait = 30
 	paloAltoPanosDefaultLoginWait  = 300
+	// paloAltoPanosMaxSSHKeys is the number of public keys a panos user has.
+	paloAltoPanosMaxSSHKeys = 1
 )
 
 type PaloAltoPanos struct {
@@ -35,10 +37,14 @@ func (p *PaloAltoPanos) Package(
 }
 
 func (p *PaloAltoPanos) patchCPUEmulation(c *instance.QemuLaunchCmd) {
-	if len(c.Accel) > 1 && c.Accel[1] != AccelKVM {
+	if c.Accel != "" && c.Accel != AccelKVM {
 		// if the accel is *not* KVM, we'll use emulated CPU, otherwise we'll leave it
 		// to the default of "max"
-		c.CPU[1] = "qemu64,+ssse3,+sse4.1,+sse4.2"
+		if c.CPU == nil {
+			c.CPU = &instance.CPU{}
+		}
+
+		c.CPU.Model = "qemu64,+ssse3,+sse4.1,+sse4.2"
 	}
 }
 
@@ -385,6 +391,23 @@ func (p *PaloAltoPanos) SetUserPass(usr, pwd string) error {
 	return p.Config(lines)
 }
 
+func (p *PaloAltoPanos) SetSSHKeys(usr string, keys []*util.SSHPublicKey) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	p.Loggers.Base.Infof("set ssh keys for user '%s' requested", usr)
+
+	err := ValidateSSHKeys(PlatformTypePaloAltoPanos, len(keys))
+	if err != nil {
+		return err
+	}
+
+	return p.Config([]string{
+		fmt.Sprintf("set mgt-config users %s public-key %s", usr, keys[0].Base64()),
+	})
+}
+
 func (p *PaloAltoPanos) SetHostname(h string) error {
 	p.Loggers.Base.Infof("set hostname '%s' requested", h)
 
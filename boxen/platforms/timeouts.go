@@ -25,6 +25,8 @@ func getPlatformBootTimeout(pT string) int {
 		t = paloAltoPanosDefaultBootTime
 	case PlatformTypeCheckpointCloudguard:
 		t = checkpointCloudGuardDefaultBootTime
+	case PlatformTypeLinux:
+		t = linuxDefaultBootTime
 	default:
 		t = DefaultBootTime
 	}
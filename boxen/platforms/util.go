@@ -33,6 +33,18 @@ func diskToVendorPlatformMap() map[*regexp.Regexp][]string {
 			"checkpoint",
 			"cloudguard",
 		},
+		regexp.MustCompile(`(?i)fgt_vm64_kvm.*.qcow2`): {
+			"fortinet",
+			"fortigate",
+		},
+		regexp.MustCompile(`(?i)chr-.*.(qcow2|vmdk|img)`): {
+			"mikrotik",
+			"chr",
+		},
+		regexp.MustCompile(`(?i)(cloudimg|generic-?cloud|cloud-base|cloudinit).*.(qcow2|img)`): {
+			"linux",
+			"cloud",
+		},
 	}
 }
 
@@ -69,6 +81,13 @@ func pTDiskToVersionMap() map[string]*regexp.Regexp {
 			`(?i)(?:pa-vm-kvm-)(\d+\.\d+\.\d+(?:-h\d+)?).qcow2`),
 		PlatformTypeCheckpointCloudguard: regexp.MustCompile(
 			`(?i)check_point_(r\d+\.\d+)_cloudguard_.*.qcow2`),
+		PlatformTypeFortinetFortigate: regexp.MustCompile(
+			`(?i)fgt_vm64_kvm-v(\d+\.\d+\.\d+)(?:\.f)?-build\d+.*.qcow2`),
+		PlatformTypeMikrotikChr: regexp.MustCompile(
+			`(?i)chr-(\d+\.\d+(?:\.\d+)?(?:(?:rc|beta)\d+)?).(?:qcow2|vmdk|img)`),
+		// there is no common versioning scheme across distributions, so the version of a linux
+		// cloud image is simply the disk name without the extension.
+		PlatformTypeLinux: regexp.MustCompile(`(?i)^(.+?)\.(?:qcow2|img)$`),
 	}
 }
 
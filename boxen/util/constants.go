@@ -3,6 +3,7 @@ package util
 const (
 	MaxBuffer          = 65535
 	FilePerms          = 0666
+	PrivateFilePerms   = 0600
 	QemuImgCmd         = "qemu-img"
 	DockerCmd          = "docker"
 	QemuImgContainer   = "ghcr.io/hellt/qemu-img:latest"
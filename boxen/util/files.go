@@ -2,6 +2,8 @@ package util
 
 import (
 	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
@@ -81,6 +83,30 @@ func CopyFile(s, d string) error {
 	return err
 }
 
+// FileSha256 returns the hex encoded sha256 checksum of the file f.
+func FileSha256(f string) (string, error) {
+	fPath, err := ResolveFile(f)
+	if err != nil {
+		return "", err
+	}
+
+	src, err := os.Open(fPath)
+	if err != nil {
+		return "", err
+	}
+
+	defer src.Close()
+
+	h := sha256.New()
+
+	_, err = io.Copy(h, src)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 // CopyAsset copies an asset file source `s` to destination `d`.
 func CopyAsset(s, d string) error {
 	sFile, err := boxen.Assets.Open(fmt.Sprintf("assets/%s", s))
@@ -0,0 +1,168 @@
+package util
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+const (
+	isoSectorSize      = 2048
+	isoSystemAreaCount = 16
+	isoDirRecordLen    = 33
+	isoPathTableLen    = 10
+)
+
+// isoBothEndian32 writes the "both-endian" (little endian followed by big endian) representation
+// of v to b as used all over the place in iso9660 structures.
+func isoBothEndian32(b []byte, v uint32) {
+	binary.LittleEndian.PutUint32(b[0:4], v)
+	binary.BigEndian.PutUint32(b[4:8], v)
+}
+
+func isoBothEndian16(b []byte, v uint16) {
+	binary.LittleEndian.PutUint16(b[0:2], v)
+	binary.BigEndian.PutUint16(b[2:4], v)
+}
+
+func isoPadded(b []byte, s string) {
+	for i := range b {
+		b[i] = ' '
+	}
+
+	copy(b, s)
+}
+
+func isoDirRecord(name []byte, extent, size uint32, dir bool, t time.Time) []byte {
+	l := isoDirRecordLen + len(name)
+	if l%2 != 0 {
+		l++
+	}
+
+	r := make([]byte, l)
+
+	r[0] = byte(l)
+	isoBothEndian32(r[2:10], extent)
+	isoBothEndian32(r[10:18], size)
+
+	r[18] = byte(t.Year() - 1900) //nolint:gomnd
+	r[19] = byte(t.Month())
+	r[20] = byte(t.Day())
+	r[21] = byte(t.Hour())
+	r[22] = byte(t.Minute())
+	r[23] = byte(t.Second())
+
+	if dir {
+		r[25] = 2
+	}
+
+	isoBothEndian16(r[28:32], 1)
+
+	r[32] = byte(len(name))
+	copy(r[33:], name)
+
+	return r
+}
+
+func isoSectors(size int) uint32 {
+	return uint32((size + isoSectorSize - 1) / isoSectorSize)
+}
+
+// WriteISO9660 writes a very minimal iso9660 image to the file f with the volume identifier label
+// that contains the given files in its root directory. There are no rock ridge or joliet extensions
+// so file names are stored as given (plus the ";1" version suffix) -- this is fine for the (linux)
+// consumers we care about (i.e. cloud-init seeds) but this is *not* a general purpose iso writer!
+func WriteISO9660(f, label string, files map[string][]byte) error {
+	names := make([]string, 0, len(files))
+
+	for name := range files {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	// system area, primary volume descriptor, terminator, l and m path tables, root directory
+	pvdSector := uint32(isoSystemAreaCount)
+	lPathSector := pvdSector + 2 //nolint:gomnd
+	mPathSector := lPathSector + 1
+	rootSector := mPathSector + 1
+	nextSector := rootSector + 1
+
+	t := time.Now().UTC()
+
+	rootDir := make([]byte, 0, isoSectorSize)
+	rootDir = append(rootDir, isoDirRecord([]byte{0}, rootSector, isoSectorSize, true, t)...)
+	rootDir = append(rootDir, isoDirRecord([]byte{1}, rootSector, isoSectorSize, true, t)...)
+
+	extents := make(map[string]uint32, len(names))
+
+	for _, name := range names {
+		extents[name] = nextSector
+		nextSector += isoSectors(len(files[name]))
+
+		rootDir = append(
+			rootDir,
+			isoDirRecord(
+				[]byte(name+";1"),
+				extents[name],
+				uint32(len(files[name])),
+				false,
+				t,
+			)...)
+	}
+
+	if len(rootDir) > isoSectorSize {
+		return fmt.Errorf("%w: too many files for iso root directory", ErrAllocationError)
+	}
+
+	img := make([]byte, int(nextSector)*isoSectorSize)
+
+	pvd := img[pvdSector*isoSectorSize : (pvdSector+1)*isoSectorSize]
+	pvd[0] = 1
+	copy(pvd[1:6], "CD001")
+	pvd[6] = 1
+	isoPadded(pvd[8:40], "")
+	isoPadded(pvd[40:72], label)
+	isoBothEndian32(pvd[80:88], nextSector)
+	isoBothEndian16(pvd[120:124], 1)
+	isoBothEndian16(pvd[124:128], 1)
+	isoBothEndian16(pvd[128:132], isoSectorSize)
+	isoBothEndian32(pvd[132:140], isoPathTableLen)
+	binary.LittleEndian.PutUint32(pvd[140:144], lPathSector)
+	binary.BigEndian.PutUint32(pvd[148:152], mPathSector)
+	copy(pvd[156:190], rootDir[:34])
+	isoPadded(pvd[190:813], "")
+	isoPadded(pvd[574:702], "BOXEN")
+
+	for _, offset := range []int{813, 830, 847, 864} {
+		copy(pvd[offset:offset+16], "0000000000000000")
+	}
+
+	copy(pvd[813:829], t.Format("20060102150405")+"00")
+	pvd[881] = 1
+
+	term := img[(pvdSector+1)*isoSectorSize:]
+	term[0] = 255
+	copy(term[1:6], "CD001")
+	term[6] = 1
+
+	lPath := img[lPathSector*isoSectorSize:]
+	lPath[0] = 1
+	binary.LittleEndian.PutUint32(lPath[2:6], rootSector)
+	binary.LittleEndian.PutUint16(lPath[6:8], 1)
+
+	mPath := img[mPathSector*isoSectorSize:]
+	mPath[0] = 1
+	binary.BigEndian.PutUint32(mPath[2:6], rootSector)
+	binary.BigEndian.PutUint16(mPath[6:8], 1)
+
+	copy(img[rootSector*isoSectorSize:], rootDir)
+
+	for _, name := range names {
+		copy(img[extents[name]*isoSectorSize:], files[name])
+	}
+
+	return os.WriteFile(f, img, FilePerms)
+}
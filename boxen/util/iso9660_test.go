@@ -0,0 +1,224 @@
+package util_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/carlmontanari/boxen/boxen/util"
+	"github.com/google/go-cmp/cmp"
+)
+
+const isoSectorSize = 2048
+
+type isoDirRecord struct {
+	name   string
+	extent uint32
+	size   uint32
+	dir    bool
+}
+
+// isoBothEndian32 reads a "both-endian" value and fails if the two halves disagree.
+func isoBothEndian32(t *testing.T, b []byte, field string) uint32 {
+	t.Helper()
+
+	le, be := binary.LittleEndian.Uint32(b[0:4]), binary.BigEndian.Uint32(b[4:8])
+	if le != be {
+		t.Fatalf("%s: little endian value %d does not match big endian value %d", field, le, be)
+	}
+
+	return le
+}
+
+func isoBothEndian16(t *testing.T, b []byte, field string) uint16 {
+	t.Helper()
+
+	le, be := binary.LittleEndian.Uint16(b[0:2]), binary.BigEndian.Uint16(b[2:4])
+	if le != be {
+		t.Fatalf("%s: little endian value %d does not match big endian value %d", field, le, be)
+	}
+
+	return le
+}
+
+func parseISODirRecords(t *testing.T, b []byte) []isoDirRecord {
+	t.Helper()
+
+	var records []isoDirRecord
+
+	for len(b) > 0 && b[0] != 0 {
+		l := int(b[0])
+		if l < 34 || l > len(b) || l%2 != 0 {
+			t.Fatalf("invalid directory record length %d", l)
+		}
+
+		nameLen := int(b[32])
+
+		records = append(records, isoDirRecord{
+			name:   string(b[33 : 33+nameLen]),
+			extent: isoBothEndian32(t, b[2:10], "directory record extent"),
+			size:   isoBothEndian32(t, b[10:18], "directory record size"),
+			dir:    b[25]&2 != 0,
+		})
+
+		b = b[l:]
+	}
+
+	return records
+}
+
+// readISO9660 parses the image written by WriteISO9660, checking the volume descriptors and path
+// tables, and returns the volume label and the root directory records.
+func readISO9660(t *testing.T, f string) (string, []isoDirRecord, []byte) {
+	t.Helper()
+
+	img, err := os.ReadFile(f)
+	if err != nil {
+		t.Fatalf("failed reading iso: %s", err)
+	}
+
+	if len(img)%isoSectorSize != 0 || len(img) < 20*isoSectorSize {
+		t.Fatalf("iso size %d is not a valid number of sectors", len(img))
+	}
+
+	for _, b := range img[:16*isoSectorSize] {
+		if b != 0 {
+			t.Fatal("system area is not empty")
+		}
+	}
+
+	pvd := img[16*isoSectorSize : 17*isoSectorSize]
+	if pvd[0] != 1 || string(pvd[1:6]) != "CD001" || pvd[6] != 1 {
+		t.Fatalf("invalid primary volume descriptor header %q", pvd[0:7])
+	}
+
+	if got := isoBothEndian32(t, pvd[80:88], "volume space size"); int(got) != len(img)/isoSectorSize {
+		t.Fatalf("volume space size is %d, image has %d sectors", got, len(img)/isoSectorSize)
+	}
+
+	if got := isoBothEndian16(t, pvd[128:132], "logical block size"); got != isoSectorSize {
+		t.Fatalf("logical block size is %d", got)
+	}
+
+	if pvd[881] != 1 {
+		t.Fatalf("file structure version is %d", pvd[881])
+	}
+
+	term := img[17*isoSectorSize : 18*isoSectorSize]
+	if term[0] != 255 || string(term[1:6]) != "CD001" {
+		t.Fatalf("invalid volume descriptor set terminator %q", term[0:7])
+	}
+
+	root := parseISODirRecords(t, pvd[156:190])
+	if len(root) != 1 || !root[0].dir || root[0].name != "\x00" {
+		t.Fatalf("invalid root directory record in primary volume descriptor: %+v", root)
+	}
+
+	pathTableSize := isoBothEndian32(t, pvd[132:140], "path table size")
+	lPath := img[binary.LittleEndian.Uint32(pvd[140:144])*isoSectorSize:]
+	mPath := img[binary.BigEndian.Uint32(pvd[148:152])*isoSectorSize:]
+
+	if pathTableSize != 10 || lPath[0] != 1 || mPath[0] != 1 ||
+		binary.LittleEndian.Uint32(lPath[2:6]) != root[0].extent ||
+		binary.BigEndian.Uint32(mPath[2:6]) != root[0].extent {
+		t.Fatal("path tables do not point to the root directory")
+	}
+
+	dir := img[root[0].extent*isoSectorSize : root[0].extent*isoSectorSize+root[0].size]
+
+	return strings.TrimRight(string(pvd[40:72]), " "), parseISODirRecords(t, dir), img
+}
+
+func TestWriteISO9660(t *testing.T) {
+	tests := []struct {
+		desc  string
+		label string
+		files map[string][]byte
+	}{
+		{
+			desc:  "no files",
+			label: "empty",
+			files: map[string][]byte{},
+		},
+		{
+			desc:  "cloud-init seed",
+			label: "cidata",
+			files: map[string][]byte{
+				"user-data":      []byte("#cloud-config\nhostname: vm1\n"),
+				"meta-data":      []byte("instance-id: boxen-1\nlocal-hostname: vm1\n"),
+				"network-config": []byte("version: 2\n"),
+			},
+		},
+		{
+			desc:  "multi sector and empty files",
+			label: "data",
+			files: map[string][]byte{
+				"big":   []byte(strings.Repeat("boxen", 1000)),
+				"empty": {},
+				"exact": []byte(strings.Repeat("x", isoSectorSize)),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			f := filepath.Join(t.TempDir(), "test.iso")
+
+			err := util.WriteISO9660(f, tt.label, tt.files)
+			if err != nil {
+				t.Fatalf("%s: failed writing iso: %s", tt.desc, err)
+			}
+
+			label, records, img := readISO9660(t, f)
+
+			if label != tt.label {
+				t.Fatalf("%s: got label %q, want %q", tt.desc, label, tt.label)
+			}
+
+			if len(records) < 2 || records[0].name != "\x00" || records[1].name != "\x01" ||
+				!records[0].dir || !records[1].dir {
+				t.Fatalf("%s: root directory is missing its '.' and '..' records", tt.desc)
+			}
+
+			got := map[string][]byte{}
+
+			for _, r := range records[2:] {
+				if r.dir {
+					t.Fatalf("%s: unexpected directory %q", tt.desc, r.name)
+				}
+
+				if !strings.HasSuffix(r.name, ";1") {
+					t.Fatalf("%s: file %q is missing the version suffix", tt.desc, r.name)
+				}
+
+				if int(r.extent*isoSectorSize+r.size) > len(img) {
+					t.Fatalf("%s: file %q extends past the end of the image", tt.desc, r.name)
+				}
+
+				got[strings.TrimSuffix(r.name, ";1")] =
+					img[r.extent*isoSectorSize : r.extent*isoSectorSize+r.size]
+			}
+
+			if diff := cmp.Diff(tt.files, got); diff != "" {
+				t.Fatalf("%s: files do not match (-want +got):\n%s", tt.desc, diff)
+			}
+		})
+	}
+}
+
+func TestWriteISO9660TooManyFiles(t *testing.T) {
+	files := map[string][]byte{}
+
+	for i := 0; i < 100; i++ {
+		files[fmt.Sprintf("file-with-a-rather-long-name-%03d", i)] = []byte("boxen")
+	}
+
+	err := util.WriteISO9660(filepath.Join(t.TempDir(), "test.iso"), "full", files)
+	if !errors.Is(err, util.ErrAllocationError) {
+		t.Fatalf("expected allocation error, got: %v", err)
+	}
+}
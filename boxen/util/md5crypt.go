@@ -111,12 +111,12 @@ func Md5Crypt(password []byte) []byte {
 
 // ConfigLinesMd5Password accepts a slice of config lines and replaces the plain-text password with
 // a md5 encrypted password. It does this by using the provided passwordPattern to find lines that
-// contain passwords.
+// contain passwords. Passwords that are already crypt hashed (start with "$") are left as is.
 func ConfigLinesMd5Password(lines []string, passwordPattern *regexp.Regexp) []string {
 	for i, line := range lines {
 		matches := passwordPattern.FindStringSubmatch(line)
 
-		if len(matches) > 1 {
+		if len(matches) > 1 && !strings.HasPrefix(matches[1], "$") {
 			newLine := strings.Replace(
 				line,
 				matches[1],
@@ -0,0 +1,244 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"math/big"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// PasswordHashMd5Crypt is the "$1$" md5-crypt scheme, see Md5Crypt.
+	PasswordHashMd5Crypt = "md5-crypt"
+	// PasswordHashSha256Crypt is the "$5$" sha256-crypt scheme (i.e. nx-os type 5).
+	PasswordHashSha256Crypt = "sha256-crypt"
+	// PasswordHashSha512Crypt is the "$6$" sha512-crypt scheme (i.e. junos, eos sha512, linux).
+	PasswordHashSha512Crypt = "sha512-crypt"
+	// PasswordHashCiscoType8 is the "$8$" pbkdf2-sha256 scheme used by cisco type 8 secrets.
+	PasswordHashCiscoType8 = "cisco-type8"
+	// PasswordHashCiscoType9 is the "$9$" scrypt scheme used by cisco type 9 secrets.
+	PasswordHashCiscoType9 = "cisco-type9"
+
+	shaCryptRounds   = 5000
+	shaCryptSaltSize = 16
+
+	ciscoSaltSize        = 14
+	ciscoKeySize         = 32
+	ciscoType8Iterations = 20000
+	ciscoType9N          = 16384
+	ciscoType9R          = 1
+	ciscoType9P          = 1
+)
+
+// sha256CryptOrder and sha512CryptOrder are the byte orders used when encoding the final sha-crypt
+// digests, each group of three bytes is encoded to four characters.
+var (
+	sha256CryptOrder = [][3]int{ //nolint:gochecknoglobals
+		{0, 10, 20}, {21, 1, 11}, {12, 22, 2}, {3, 13, 23}, {24, 4, 14},
+		{15, 25, 5}, {6, 16, 26}, {27, 7, 17}, {18, 28, 8}, {9, 19, 29},
+	}
+	sha512CryptOrder = [][3]int{ //nolint:gochecknoglobals
+		{0, 21, 42}, {22, 43, 1}, {44, 2, 23}, {3, 24, 45}, {25, 46, 4}, {47, 5, 26}, {6, 27, 48},
+		{28, 49, 7}, {50, 8, 29}, {9, 30, 51}, {31, 52, 10}, {53, 11, 32}, {12, 33, 54},
+		{34, 55, 13}, {56, 14, 35}, {15, 36, 57}, {37, 58, 16}, {59, 17, 38}, {18, 39, 60},
+		{40, 61, 19}, {62, 20, 41},
+	}
+	// ciscoBase64 is base64 w/ the crypt alphabet (itoa64) -- this is what cisco type 8/9 use.
+	ciscoBase64 = base64.NewEncoding(itoa64).WithPadding(base64.NoPadding) //nolint:gochecknoglobals
+)
+
+// RandomSalt returns a random salt of n characters from the crypt alphabet.
+func RandomSalt(n int) (string, error) {
+	salt := make([]byte, n)
+
+	for i := range salt {
+		idx, err := rand.Int(rand.Reader, big.NewInt(int64(len(itoa64))))
+		if err != nil {
+			return "", err
+		}
+
+		salt[i] = itoa64[idx.Int64()]
+	}
+
+	return string(salt), nil
+}
+
+func cryptB64From24Bit(out []byte, b2, b1, b0 byte, n int) []byte {
+	w := uint(b2)<<16 | uint(b1)<<8 | uint(b0)
+
+	for ; n > 0; n-- {
+		out = append(out, itoa64[w&0x3f])
+		w >>= 6
+	}
+
+	return out
+}
+
+// repeatTo returns b repeated (and truncated) to a length of n bytes.
+func repeatTo(b []byte, n int) []byte {
+	out := make([]byte, 0, n)
+
+	for len(out) < n {
+		out = append(out, b...)
+	}
+
+	return out[:n]
+}
+
+// shaCrypt implements the sha-crypt algorithm as specified by Ulrich Drepper, see:
+// https://www.akkadia.org/drepper/SHA-crypt.txt. Only the default number of rounds is supported.
+func shaCrypt(newHash func() hash.Hash, magic string, password, salt []byte) string {
+	if len(salt) > shaCryptSaltSize {
+		salt = salt[:shaCryptSaltSize]
+	}
+
+	b := newHash()
+	b.Write(password)
+	b.Write(salt)
+	b.Write(password)
+	bSum := b.Sum(nil)
+
+	a := newHash()
+	a.Write(password)
+	a.Write(salt)
+	a.Write(repeatTo(bSum, len(password)))
+
+	for i := len(password); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			a.Write(bSum)
+		} else {
+			a.Write(password)
+		}
+	}
+
+	aSum := a.Sum(nil)
+
+	dp := newHash()
+	for i := 0; i < len(password); i++ {
+		dp.Write(password)
+	}
+
+	p := repeatTo(dp.Sum(nil), len(password))
+
+	ds := newHash()
+	for i := 0; i < 16+int(aSum[0]); i++ {
+		ds.Write(salt)
+	}
+
+	s := repeatTo(ds.Sum(nil), len(salt))
+
+	c := aSum
+
+	for i := 0; i < shaCryptRounds; i++ {
+		h := newHash()
+
+		if i&1 != 0 {
+			h.Write(p)
+		} else {
+			h.Write(c)
+		}
+
+		if i%3 != 0 {
+			h.Write(s)
+		}
+
+		if i%7 != 0 {
+			h.Write(p)
+		}
+
+		if i&1 != 0 {
+			h.Write(c)
+		} else {
+			h.Write(p)
+		}
+
+		c = h.Sum(nil)
+	}
+
+	out := []byte(fmt.Sprintf("%s%s$", magic, salt))
+
+	if len(c) == sha256.Size {
+		for _, o := range sha256CryptOrder {
+			out = cryptB64From24Bit(out, c[o[0]], c[o[1]], c[o[2]], 4) //nolint:gomnd
+		}
+
+		return string(cryptB64From24Bit(out, 0, c[31], c[30], 3)) //nolint:gomnd
+	}
+
+	for _, o := range sha512CryptOrder {
+		out = cryptB64From24Bit(out, c[o[0]], c[o[1]], c[o[2]], 4) //nolint:gomnd
+	}
+
+	return string(cryptB64From24Bit(out, 0, 0, c[63], 2)) //nolint:gomnd
+}
+
+// Sha256Crypt returns the "$5$" sha256-crypt hash of password using the provided salt.
+func Sha256Crypt(password, salt []byte) string {
+	return shaCrypt(sha256.New, "$5$", password, salt)
+}
+
+// Sha512Crypt returns the "$6$" sha512-crypt hash of password using the provided salt.
+func Sha512Crypt(password, salt []byte) string {
+	return shaCrypt(sha512.New, "$6$", password, salt)
+}
+
+// CiscoType8 returns the cisco type 8 ("$8$", pbkdf2-sha256) hash of password using the provided
+// salt.
+func CiscoType8(password, salt []byte) string {
+	k := pbkdf2.Key(password, salt, ciscoType8Iterations, ciscoKeySize, sha256.New)
+
+	return fmt.Sprintf("$8$%s$%s", salt, ciscoBase64.EncodeToString(k))
+}
+
+// CiscoType9 returns the cisco type 9 ("$9$", scrypt) hash of password using the provided salt.
+func CiscoType9(password, salt []byte) (string, error) {
+	k, err := scrypt.Key(password, salt, ciscoType9N, ciscoType9R, ciscoType9P, ciscoKeySize)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("$9$%s$%s", salt, ciscoBase64.EncodeToString(k)), nil
+}
+
+// HashPasswordWithSalt hashes the password with the given scheme (one of the PasswordHash*
+// constants) and salt.
+func HashPasswordWithSalt(scheme, password, salt string) (string, error) {
+	switch scheme {
+	case PasswordHashMd5Crypt:
+		// md5crypt always uses the same salt, see Md5Crypt
+		return string(Md5Crypt([]byte(password))), nil
+	case PasswordHashSha256Crypt:
+		return Sha256Crypt([]byte(password), []byte(salt)), nil
+	case PasswordHashSha512Crypt:
+		return Sha512Crypt([]byte(password), []byte(salt)), nil
+	case PasswordHashCiscoType8:
+		return CiscoType8([]byte(password), []byte(salt)), nil
+	case PasswordHashCiscoType9:
+		return CiscoType9([]byte(password), []byte(salt))
+	}
+
+	return "", fmt.Errorf("%w: unknown password hash scheme '%s'", ErrValidationError, scheme)
+}
+
+// HashPassword hashes the password with the given scheme (one of the PasswordHash* constants) and
+// a random salt.
+func HashPassword(scheme, password string) (string, error) {
+	saltSize := shaCryptSaltSize
+
+	if scheme == PasswordHashCiscoType8 || scheme == PasswordHashCiscoType9 {
+		saltSize = ciscoSaltSize
+	}
+
+	salt, err := RandomSalt(saltSize)
+	if err != nil {
+		return "", err
+	}
+
+	return HashPasswordWithSalt(scheme, password, salt)
+}
@@ -0,0 +1,85 @@
+package util_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/carlmontanari/boxen/boxen/util"
+)
+
+func TestHashPasswordWithSalt(t *testing.T) {
+	tests := []struct {
+		desc     string
+		scheme   string
+		password string
+		salt     string
+		want     string
+	}{
+		{
+			// drepper sha-crypt specification test vectors
+			desc:     "sha256-crypt",
+			scheme:   util.PasswordHashSha256Crypt,
+			password: "Hello world!",
+			salt:     "saltstring",
+			want:     "$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5",
+		},
+		{
+			desc:     "sha256-crypt salt truncated to sixteen characters",
+			scheme:   util.PasswordHashSha256Crypt,
+			password: "This is just a test",
+			salt:     "toolongsaltstring",
+			want:     "$5$toolongsaltstrin$Un/5jzAHMgOGZ5.mWJpuVolil07guHPvOW8mGRcvxa5",
+		},
+		{
+			desc:     "sha512-crypt",
+			scheme:   util.PasswordHashSha512Crypt,
+			password: "Hello world!",
+			salt:     "saltstring",
+			want: "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68" +
+				"u4OTLiBFdcbYEdFCoEOfaS35inz1",
+		},
+		{
+			desc:     "sha512-crypt salt truncated to sixteen characters",
+			scheme:   util.PasswordHashSha512Crypt,
+			password: "This is just a test",
+			salt:     "toolongsaltstring",
+			want: "$6$toolongsaltstrin$lQ8jolhgVRVhY4b5pZKaysCLi0QBxGoNeKQzQ3glMhwllF7oGDZxUh" +
+				"x1yxdYcz/e1JSbq3y6JMxxl8audkUEm0",
+		},
+		{
+			// hashcat example hashes (modes 9200 and 9300)
+			desc:     "cisco type 8",
+			scheme:   util.PasswordHashCiscoType8,
+			password: "hashcat",
+			salt:     "TnGX/fE4KGHOVU",
+			want:     "$8$TnGX/fE4KGHOVU$pEhnEvxrvaynpi8j4f.EMHr6M.FzU8xnZnBr/tJdFWk",
+		},
+		{
+			desc:     "cisco type 9",
+			scheme:   util.PasswordHashCiscoType9,
+			password: "hashcat",
+			salt:     "2MJBozw/9R3UsU",
+			want:     "$9$2MJBozw/9R3UsU$2lFhcKvpghcyw8deP25GOfyZaagyUOGBymkryvOdfo6",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := util.HashPasswordWithSalt(tt.scheme, tt.password, tt.salt)
+			if err != nil {
+				t.Fatalf("%s: failed hashing password: %s", tt.desc, err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("%s: got %q, want %q", tt.desc, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHashPasswordWithSaltUnknownScheme(t *testing.T) {
+	_, err := util.HashPasswordWithSalt("rot13", "password", "salt")
+	if !errors.Is(err, util.ErrValidationError) {
+		t.Fatalf("expected validation error, got: %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package util
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const sshKeyTypeLenSize = 4
+
+// sshKeyAlgorithms maps the supported openssh public key types to the "short" algorithm name that
+// most network operating systems use in their ssh key configuration syntax.
+var sshKeyAlgorithms = map[string]string{ //nolint:gochecknoglobals
+	"ssh-rsa":             "rsa",
+	"ssh-dss":             "dsa",
+	"ssh-ed25519":         "ed25519",
+	"ecdsa-sha2-nistp256": "ecdsa",
+	"ecdsa-sha2-nistp384": "ecdsa",
+	"ecdsa-sha2-nistp521": "ecdsa",
+}
+
+// SSHPublicKey is a parsed openssh "authorized_keys" style public key.
+type SSHPublicKey struct {
+	// Type is the openssh key type, i.e. "ssh-rsa".
+	Type string
+	// Key is the base64 encoded key blob.
+	Key string
+	// Comment is the (optional) key comment, i.e. "user@host".
+	Comment string
+}
+
+// ParseSSHPublicKey parses an openssh public key in the "type key [comment]" format, checking that
+// the key type is supported and matches the type encoded in the key blob.
+func ParseSSHPublicKey(s string) (*SSHPublicKey, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 2 { //nolint:gomnd
+		return nil, fmt.Errorf(
+			"%w: ssh public key '%s' is not of the form 'type key [comment]'",
+			ErrValidationError,
+			s,
+		)
+	}
+
+	k := &SSHPublicKey{
+		Type:    fields[0],
+		Key:     fields[1],
+		Comment: strings.Join(fields[2:], " "),
+	}
+
+	if _, ok := sshKeyAlgorithms[k.Type]; !ok {
+		// authorized_keys lines may be prefixed with options (i.e. 'from="10.0.0.0/8"'), network
+		// operating systems have no equivalent so rather than dropping the restrictions, say so
+		for _, f := range fields[1:] {
+			if _, ok = sshKeyAlgorithms[f]; ok {
+				return nil, fmt.Errorf(
+					"%w: ssh public key '%s' has authorized_keys options, options are not supported",
+					ErrValidationError,
+					s,
+				)
+			}
+		}
+
+		return nil, fmt.Errorf("%w: unsupported ssh public key type '%s'", ErrValidationError, k.Type)
+	}
+
+	blob, err := base64.StdEncoding.DecodeString(k.Key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: ssh public key is not valid base64: %s", ErrValidationError, err)
+	}
+
+	// the blob starts with the (length prefixed) key type, which should match the declared type
+	if len(blob) < sshKeyTypeLenSize ||
+		len(blob) < sshKeyTypeLenSize+int(binary.BigEndian.Uint32(blob)) ||
+		string(blob[sshKeyTypeLenSize:sshKeyTypeLenSize+int(binary.BigEndian.Uint32(blob))]) !=
+			k.Type {
+		return nil, fmt.Errorf(
+			"%w: ssh public key blob does not match declared key type '%s'",
+			ErrValidationError,
+			k.Type,
+		)
+	}
+
+	return k, nil
+}
+
+// ParseSSHPublicKeys parses a slice of ssh public keys.
+func ParseSSHPublicKeys(keys []string) ([]*SSHPublicKey, error) {
+	parsed := make([]*SSHPublicKey, len(keys))
+
+	for i, key := range keys {
+		k, err := ParseSSHPublicKey(key)
+		if err != nil {
+			return nil, err
+		}
+
+		parsed[i] = k
+	}
+
+	return parsed, nil
+}
+
+// LoadSSHPublicKeys returns the ssh public key(s) from s -- if s is a path to a file (i.e. an
+// "id_rsa.pub" or "authorized_keys" file) each (non blank/comment) line of the file is returned,
+// otherwise s itself is assumed to be a public key.
+func LoadSSHPublicKeys(s string) ([]string, error) {
+	f, err := ResolveFile(s)
+	if err != nil {
+		return []string{s}, nil //nolint:nilerr
+	}
+
+	b, err := os.ReadFile(f)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		keys = append(keys, line)
+	}
+
+	return keys, nil
+}
+
+// Algorithm returns the short algorithm name of the key -- one of rsa, dsa, ecdsa, or ed25519.
+func (k *SSHPublicKey) Algorithm() string {
+	return sshKeyAlgorithms[k.Type]
+}
+
+// String returns the key in the openssh "type key" format, the comment is omitted as plenty of
+// platforms don't handle (or need) it.
+func (k *SSHPublicKey) String() string {
+	return fmt.Sprintf("%s %s", k.Type, k.Key)
+}
+
+// Base64 returns the base64 encoded String representation of the key, this is the format that some
+// platforms (i.e. panos) expect keys to be provided in.
+func (k *SSHPublicKey) Base64() string {
+	return base64.StdEncoding.EncodeToString([]byte(k.String()))
+}
+
+// KeyChunks returns the key blob split into chunks of at most n characters, for platforms that
+// don't accept overly long lines (i.e. ios-xe "key-string").
+func (k *SSHPublicKey) KeyChunks(n int) []string {
+	var chunks []string
+
+	for s := k.Key; s != ""; {
+		if len(s) <= n {
+			chunks = append(chunks, s)
+
+			break
+		}
+
+		chunks = append(chunks, s[:n])
+		s = s[n:]
+	}
+
+	return chunks
+}
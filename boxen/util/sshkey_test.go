@@ -0,0 +1,227 @@
+package util_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/carlmontanari/boxen/boxen/util"
+	"github.com/google/go-cmp/cmp"
+)
+
+const (
+	testEd25519Blob = "AAAAC3NzaC1lZDI1NTE5AAAAILXMN7mj0jOxyukcUD9aSdRSAiM6QmtRlXlRujoflUx6"
+	testRSABlob     = "AAAAB3NzaC1yc2EAAAADAQABAAAAgQC5Qae4mRz1s6hHVndoI//WKVeueIEP1Zig5" +
+		"NoZEf3r1/kO2qF/D0lpGcXU1rpTyjL6SROYH1gqKokYH/JnaJ10yNDcsVwBSEtK9sTHQk7EHe/Ig/mfNMF" +
+		"Xj4Ap0RgCKf1dPru+RdY5ihTG2gCZHX0AT96ZDZAMsYzP3EV+09CTWQ=="
+	testECDSABlob = "AAAAE2VjZHNhLXNoYTItbmlzdHAyNTYAAAAIbmlzdHAyNTYAAABBBCkonJGmUoddnqkTV2ih8" +
+		"q2ARYL6hwbN5ilBdEXa5UZwo6ZHH8hVGcTx5wNjnR9giNEd04UTbdNk8lksUAURM0g="
+)
+
+func TestParseSSHPublicKey(t *testing.T) {
+	tests := []struct {
+		desc          string
+		key           string
+		want          *util.SSHPublicKey
+		wantAlgorithm string
+		wantErr       bool
+	}{
+		{
+			desc: "ed25519 with comment",
+			key:  "ssh-ed25519 " + testEd25519Blob + " boxen@lab",
+			want: &util.SSHPublicKey{
+				Type:    "ssh-ed25519",
+				Key:     testEd25519Blob,
+				Comment: "boxen@lab",
+			},
+			wantAlgorithm: "ed25519",
+		},
+		{
+			desc:          "rsa with multi word comment and surrounding whitespace",
+			key:           "  ssh-rsa " + testRSABlob + " boxen  lab key \n",
+			want:          &util.SSHPublicKey{Type: "ssh-rsa", Key: testRSABlob, Comment: "boxen lab key"},
+			wantAlgorithm: "rsa",
+		},
+		{
+			desc:          "ecdsa without comment",
+			key:           "ecdsa-sha2-nistp256 " + testECDSABlob,
+			want:          &util.SSHPublicKey{Type: "ecdsa-sha2-nistp256", Key: testECDSABlob},
+			wantAlgorithm: "ecdsa",
+		},
+		{
+			desc:    "empty",
+			key:     "",
+			wantErr: true,
+		},
+		{
+			desc:    "no key blob",
+			key:     "ssh-ed25519",
+			wantErr: true,
+		},
+		{
+			desc:    "comment line",
+			key:     "# ssh-ed25519 " + testEd25519Blob + " boxen@lab",
+			wantErr: true,
+		},
+		{
+			desc:    "unsupported type",
+			key:     "ssh-xmss " + testEd25519Blob,
+			wantErr: true,
+		},
+		{
+			desc:    "invalid base64",
+			key:     "ssh-ed25519 not*base64",
+			wantErr: true,
+		},
+		{
+			desc:    "declared type does not match blob",
+			key:     "ssh-rsa " + testEd25519Blob,
+			wantErr: true,
+		},
+		{
+			desc:    "truncated blob",
+			key:     "ssh-ed25519 AAAAC3Nz",
+			wantErr: true,
+		},
+		{
+			desc:    "authorized_keys options",
+			key:     `from="10.0.0.0/8",no-pty ssh-ed25519 ` + testEd25519Blob + " boxen@lab",
+			wantErr: true,
+		},
+		{
+			desc:    "authorized_keys options with spaces",
+			key:     `command="echo hi there",restrict ssh-ed25519 ` + testEd25519Blob,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := util.ParseSSHPublicKey(tt.key)
+
+			if tt.wantErr {
+				if !errors.Is(err, util.ErrValidationError) {
+					t.Fatalf("%s: expected validation error, got: %v", tt.desc, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("%s: failed parsing key: %s", tt.desc, err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("%s: key does not match (-want +got):\n%s", tt.desc, diff)
+			}
+
+			if got.Algorithm() != tt.wantAlgorithm {
+				t.Fatalf("%s: got algorithm %q, want %q", tt.desc, got.Algorithm(), tt.wantAlgorithm)
+			}
+		})
+	}
+}
+
+func TestParseSSHPublicKeys(t *testing.T) {
+	keys, err := util.ParseSSHPublicKeys([]string{
+		"ssh-ed25519 " + testEd25519Blob + " boxen@lab",
+		"ssh-rsa " + testRSABlob,
+	})
+	if err != nil {
+		t.Fatalf("failed parsing keys: %s", err)
+	}
+
+	if len(keys) != 2 || keys[0].Type != "ssh-ed25519" || keys[1].Type != "ssh-rsa" {
+		t.Fatalf("unexpected keys: %+v", keys)
+	}
+
+	_, err = util.ParseSSHPublicKeys([]string{"ssh-ed25519 " + testEd25519Blob, "nope"})
+	if !errors.Is(err, util.ErrValidationError) {
+		t.Fatalf("expected validation error for any invalid key, got: %v", err)
+	}
+}
+
+func TestLoadSSHPublicKeys(t *testing.T) {
+	d := t.TempDir()
+
+	authorizedKeys := filepath.Join(d, "authorized_keys")
+
+	err := os.WriteFile(
+		authorizedKeys,
+		[]byte(strings.Join([]string{
+			"# lab keys",
+			"",
+			"ssh-ed25519 " + testEd25519Blob + " boxen@lab",
+			"   ",
+			"  # indented comment",
+			"ssh-rsa " + testRSABlob + " rsa@lab  ",
+			"",
+		}, "\n")),
+		util.FilePerms,
+	)
+	if err != nil {
+		t.Fatalf("failed writing authorized_keys: %s", err)
+	}
+
+	tests := []struct {
+		desc string
+		s    string
+		want []string
+	}{
+		{
+			desc: "authorized_keys file skips blank and comment lines",
+			s:    authorizedKeys,
+			want: []string{
+				"ssh-ed25519 " + testEd25519Blob + " boxen@lab",
+				"ssh-rsa " + testRSABlob + " rsa@lab",
+			},
+		},
+		{
+			desc: "literal key",
+			s:    "ssh-ed25519 " + testEd25519Blob,
+			want: []string{"ssh-ed25519 " + testEd25519Blob},
+		},
+		{
+			desc: "missing file is treated as a key",
+			s:    filepath.Join(d, "id_ed25519.pub"),
+			want: []string{filepath.Join(d, "id_ed25519.pub")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := util.LoadSSHPublicKeys(tt.s)
+			if err != nil {
+				t.Fatalf("%s: failed loading keys: %s", tt.desc, err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("%s: keys do not match (-want +got):\n%s", tt.desc, diff)
+			}
+		})
+	}
+}
+
+func TestSSHPublicKeyRendering(t *testing.T) {
+	k, err := util.ParseSSHPublicKey("ssh-ed25519 " + testEd25519Blob + " boxen@lab")
+	if err != nil {
+		t.Fatalf("failed parsing key: %s", err)
+	}
+
+	if k.String() != "ssh-ed25519 "+testEd25519Blob {
+		t.Fatalf("unexpected string representation %q", k.String())
+	}
+
+	if k.Base64() != "c3NoLWVkMjU1MTkgQUFBQUMzTnphQzFsWkRJMU5URTVBQUFBSUxYTU43bWowak94eXVrY1VEOWFT"+
+		"ZFJTQWlNNlFtdFJsWGxSdWpvZmxVeDY=" {
+		t.Fatalf("unexpected base64 representation %q", k.Base64())
+	}
+
+	want := []string{testEd25519Blob[:32], testEd25519Blob[32:64], testEd25519Blob[64:]}
+
+	if diff := cmp.Diff(want, k.KeyChunks(32)); diff != "" {
+		t.Fatalf("key chunks do not match (-want +got):\n%s", diff)
+	}
+}
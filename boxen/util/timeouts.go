@@ -1,5 +1,24 @@
 package util
 
+import "sync/atomic"
+
+// timeoutScale is an additional timeout multiplier boxen sets itself, see ScaleTimeouts.
+var timeoutScale int32 = 1 //nolint:gochecknoglobals
+
+// ScaleTimeouts multiplies all timeouts of the process by factor on top of the
+// BOXEN_TIMEOUT_MULTIPLIER, i.e. when instances run without hardware acceleration. Timeouts are
+// only ever scaled up, the largest factor wins.
+func ScaleTimeouts(factor int) {
+	for {
+		current := atomic.LoadInt32(&timeoutScale)
+
+		if int32(factor) <= current ||
+			atomic.CompareAndSwapInt32(&timeoutScale, current, int32(factor)) {
+			return
+		}
+	}
+}
+
 // GetTimeoutMultiplier returns either 1, or the integer value of the environment variable
 // BOXEN_TIMEOUT_MULTIPLIER.
 func GetTimeoutMultiplier() int {
@@ -10,7 +29,7 @@ func GetTimeoutMultiplier() int {
 }
 
 // ApplyTimeoutMultiplier returns the timeout, as an integer, after being multiplied by the
-// environment variable BOXEN_TIMEOUT_MULTIPLIER.
+// environment variable BOXEN_TIMEOUT_MULTIPLIER (and any scale set with ScaleTimeouts).
 func ApplyTimeoutMultiplier(timeout int) int {
-	return timeout * GetTimeoutMultiplier()
+	return timeout * GetTimeoutMultiplier() * int(atomic.LoadInt32(&timeoutScale))
 }